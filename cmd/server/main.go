@@ -1,51 +1,299 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	"fizzbuzz-service/internal/application"
 	"fizzbuzz-service/internal/domain/service"
+	"fizzbuzz-service/internal/infrastructure/auth"
 	"fizzbuzz-service/internal/infrastructure/config"
+	"fizzbuzz-service/internal/infrastructure/health"
 	infrahttp "fizzbuzz-service/internal/infrastructure/http"
 	"fizzbuzz-service/internal/infrastructure/http/handler"
+	custommw "fizzbuzz-service/internal/infrastructure/http/middleware"
+	"fizzbuzz-service/internal/infrastructure/metrics"
+	"fizzbuzz-service/internal/infrastructure/persistence/file"
 	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/persistence/sqlite"
 	"fizzbuzz-service/internal/infrastructure/server"
+	"fizzbuzz-service/internal/infrastructure/tracing"
+
+	"github.com/go-chi/chi/v5"
 )
 
 func main() {
 	// 1. Load configuration
-	cfg := config.Load()
+	args := os.Args[1:]
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	// 2. Setup structured logger
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(parseLogLevel(cfg.LogLevel))
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: parseLogLevel(cfg.LogLevel),
+		Level: logLevel,
 	}))
 
+	tracerProvider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		File:        cfg.TracingFile,
+		Endpoint:    cfg.TracingEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+		ServiceName: "fizzbuzz-service",
+	})
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// 3. Wire dependencies (manual DI - could use wire/fx for larger apps)
 	generator := service.NewFizzBuzzGenerator()
-	statsRepo := inmemory.NewStatisticsRepository()
+	store, closeStats, err := newStatisticsStore(cfg, logger)
+	if err != nil {
+		logger.Error("failed to initialize statistics", "error", err)
+		os.Exit(1)
+	}
+	// Probes check the store itself, so they don't produce spans
+	readiness := health.NewReadiness(cfg.ReadyCheckTimeout, logger)
+	readiness.Register("statistics", health.StatisticsChecker(store))
+	var statsRepo tracing.StatisticsStore = store
+	if tracerProvider.Enabled() {
+		statsRepo = tracing.TraceStatistics(statsRepo, tracerProvider)
+	}
+
+	// Hits go through the dispatcher, then the optional batcher, then the store
+	var statsUpdater application.StatisticsUpdater = statsRepo
+	var batcher *application.StatisticsBatcher
+	if cfg.StatsBatchInterval > 0 {
+		batcher = application.NewStatisticsBatcher(statsRepo, application.BatcherConfig{
+			Shards:        16,
+			FlushInterval: cfg.StatsBatchInterval,
+			MaxPending:    int64(cfg.StatsBatchSize),
+			Timeout:       cfg.StatsTimeout,
+		}, logger)
+		statsUpdater = batcher
+	}
 
-	generateUseCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, cfg.MaxLimit, logger)
+	dispatcher := application.NewStatisticsDispatcher(statsUpdater, application.DispatcherConfig{
+		QueueSize: cfg.StatsQueueSize,
+		Workers:   cfg.StatsWorkers,
+		Overflow:  cfg.StatsOverflow,
+		Timeout:   cfg.StatsTimeout,
+	}, logger, application.WithDispatcherTracerProvider(tracerProvider))
+	readiness.Register("statistics_queue", health.QueueChecker(dispatcher, cfg.ReadyQueueMaxFill))
+
+	registry := metrics.NewRegistry()
+	registry.RegisterRuntimeMetrics()
+	registry.RegisterStatisticsDispatcher(dispatcher)
+
+	generateOpts := []application.GenerateOption{
+		application.WithStreamMaxLimit(cfg.StreamMaxLimit),
+		application.WithTracerProvider(tracerProvider),
+	}
+	if cfg.ResultCacheMaxBytes > 0 {
+		resultCache := application.NewResultCache(int64(cfg.ResultCacheMaxBytes))
+		registry.RegisterResultCache(resultCache)
+		generateOpts = append(generateOpts, application.WithResultCache(resultCache))
+	}
+	generateUseCase := application.NewGenerateFizzBuzzUseCase(generator, dispatcher, cfg.MaxLimit, logger, generateOpts...)
 	getStatsUseCase := application.NewGetStatisticsUseCase(statsRepo)
+	if cfg.ResultCacheMaxBytes > 0 && cfg.ResultCachePrewarm > 0 {
+		prewarmResultCache(generateUseCase, getStatsUseCase, cfg.ResultCachePrewarm, cfg.StatsTimeout, logger)
+	}
+	httpMetrics := metrics.NewHTTPMetrics(registry)
 
-	fizzHandler := handler.NewFizzBuzzHandler(generateUseCase, logger)
+	fizzHandler := handler.NewFizzBuzzHandler(generateUseCase, logger,
+		handler.WithSequenceObserver(httpMetrics),
+		handler.WithTracerProvider(tracerProvider),
+		handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)),
+	)
 	statsHandler := handler.NewStatisticsHandler(getStatsUseCase, logger)
-	healthHandler := handler.NewHealthHandler()
+	healthHandler := handler.NewHealthHandler(handler.WithReadiness(readiness))
 
-	router := infrahttp.NewRouter(fizzHandler, statsHandler, healthHandler, logger)
+	// Reloadable settings are swapped in place while requests are served
+	cors := custommw.NewCORS(custommw.CORSConfig(cfg.CORS))
+	idleTTL := custommw.WithIdleTTL(cfg.RateLimits.IdleTTL)
+	rateLimiters := infrahttp.RateLimiters{
+		Generate:   custommw.NewRateLimiter(custommw.RateLimit(cfg.RateLimits.Generate), idleTTL),
+		Stream:     custommw.NewRateLimiter(custommw.RateLimit(cfg.RateLimits.Stream), idleTTL),
+		Statistics: custommw.NewRateLimiter(custommw.RateLimit(cfg.RateLimits.Statistics), idleTTL),
+	}
+	// Authentication is enabled by an API keys file, a JWKS or both, whose
+	// keys are reloadable
+	var authn auth.Authenticator
+	keyStore := auth.NewKeyStore(cfg.APIKeys)
+	jwtAuthn := auth.NewJWTAuthenticator(cfg.JWKS, jwtConfig(cfg))
+	if cfg.APIKeysFile != "" || cfg.JWKSFile != "" {
+		methods := auth.Dispatcher{}
+		if cfg.APIKeysFile != "" {
+			methods.APIKeys = keyStore
+		}
+		if cfg.JWKSFile != "" {
+			methods.JWT = jwtAuthn
+		}
+		authn = methods
+	}
+
+	reloader := config.NewReloader(args, cfg, logger)
+	reloader.OnReload(func(cfg *config.Config) {
+		logLevel.Set(parseLogLevel(cfg.LogLevel))
+		generateUseCase.SetMaxLimit(cfg.MaxLimit)
+		generateUseCase.SetStreamMaxLimit(cfg.StreamMaxLimit)
+		cors.Update(custommw.CORSConfig(cfg.CORS))
+		rateLimiters.Generate.Update(custommw.RateLimit(cfg.RateLimits.Generate))
+		rateLimiters.Stream.Update(custommw.RateLimit(cfg.RateLimits.Stream))
+		rateLimiters.Statistics.Update(custommw.RateLimit(cfg.RateLimits.Statistics))
+		keyStore.Update(cfg.APIKeys)
+		jwtAuthn.Update(cfg.JWKS, jwtConfig(cfg))
+	})
+	adminHandler := handler.NewAdminHandler(reloader, logger)
+
+	routerOpts := []infrahttp.RouterOption{
+		infrahttp.WithMetrics(httpMetrics),
+		infrahttp.WithTracing(tracerProvider),
+		infrahttp.WithRequestTimeout(cfg.RequestTimeout),
+		infrahttp.WithCORS(cors),
+		infrahttp.WithRateLimits(rateLimiters),
+	}
+	if authn != nil {
+		routerOpts = append(routerOpts, infrahttp.WithAuthentication(authn))
+	}
+	if cfg.AdminPort == "" {
+		routerOpts = append(routerOpts,
+			infrahttp.WithHandler(cfg.MetricsPath, registry.Handler()),
+			infrahttp.WithAdmin(adminHandler),
+		)
+		if authn == nil {
+			logger.Warn("admin routes disabled: set ADMIN_PORT or configure authentication to serve them")
+		}
+	}
+	router := infrahttp.NewRouter(fizzHandler, statsHandler, healthHandler, logger, routerOpts...)
 
 	// 4. Configure and run server
-	serverCfg := server.Default()
-	serverCfg.Port = cfg.Port
+	serverCfg := server.Config{
+		Port:              cfg.Port,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		StopTimeout:       cfg.StopTimeout,
+		DrainDelay:        cfg.DrainDelay,
+	}
 
 	srv := server.New(serverCfg, router, logger)
+	if cfg.AdminPort != "" {
+		admin := chi.NewRouter()
+		admin.Handle(cfg.MetricsPath, registry.Handler())
+		adminRoutes := chi.Router(admin)
+		if authn != nil {
+			adminRoutes = admin.With(custommw.AuthenticationMiddleware(authn), custommw.RequireScope(auth.ScopeAdmin))
+		}
+		adminHandler.RegisterRoutes(adminRoutes)
+		statsHandler.RegisterAdminRoutes(adminRoutes)
+		srv.ServeAdmin(cfg.AdminPort, admin)
+	}
+	srv.OnReload(func() { reloader.Reload() })
+	srv.OnDrain(readiness.Drain)
+	// Drain queued hits before closing the store they are written to
+	srv.OnShutdown(dispatcher.Close)
+	if batcher != nil {
+		srv.OnShutdown(batcher.Close)
+	}
+	srv.OnShutdown(closeStats)
+	// Last, so spans of the shutdown itself are exported
+	srv.OnShutdown(tracerProvider.Shutdown)
 	if err := srv.Run(); err != nil {
 		logger.Error("server error", "error", err)
 		os.Exit(1)
 	}
 }
 
+// statisticsStore is implemented by every statistics backend
+type statisticsStore interface {
+	application.StatisticsUpdater
+	application.StatisticsRepository
+	health.Pinger
+}
+
+// newStatisticsStore creates the configured statistics backend and its shutdown hook
+func newStatisticsStore(cfg *config.Config, logger *slog.Logger) (statisticsStore, func(context.Context) error, error) {
+	switch cfg.StatsBackend {
+	case config.StatsBackendMemory:
+		noop := func(context.Context) error { return nil }
+		maxClients := inmemory.WithMaxClients(cfg.StatsMaxClients)
+		if cfg.StatsShards > 1 {
+			return inmemory.NewShardedStatisticsRepository(cfg.StatsShards, maxClients), noop, nil
+		}
+		return inmemory.NewStatisticsRepository(maxClients), noop, nil
+	case config.StatsBackendFile:
+		fileCfg := file.DefaultConfig(cfg.StatsDir)
+		fileCfg.FlushInterval = cfg.StatsFlushInterval
+		fileCfg.SnapshotInterval = cfg.StatsSnapshotInterval
+		fileCfg.MaxClients = cfg.StatsMaxClients
+
+		repo, err := file.Open(fileCfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case config.StatsBackendSQLite:
+		if err := os.MkdirAll(cfg.StatsDir, 0o755); err != nil {
+			return nil, nil, fmt.Errorf("create statistics dir: %w", err)
+		}
+
+		repo, err := sqlite.Open(filepath.Join(cfg.StatsDir, "statistics.db"),
+			sqlite.WithMaxClients(cfg.StatsMaxClients))
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown statistics backend %q", cfg.StatsBackend)
+	}
+}
+
+// prewarmResultCache caches the sequences of the n most frequent requests
+// A failure only costs the first requests their cache miss, so it is logged.
+func prewarmResultCache(
+	generate *application.GenerateFizzBuzzUseCase,
+	stats *application.GetStatisticsUseCase,
+	n int,
+	timeout time.Duration,
+	logger *slog.Logger,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	queries, err := stats.TopQueries(ctx, n)
+	if err != nil {
+		logger.Warn("failed to prewarm result cache", "error", err)
+		return
+	}
+	logger.Info("result cache prewarmed", "sequences", generate.Prewarm(ctx, queries))
+}
+
+// jwtConfig returns the claims JWTs are checked against
+func jwtConfig(cfg *config.Config) auth.JWTConfig {
+	return auth.JWTConfig{
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+		Leeway:   cfg.JWTLeeway,
+	}
+}
+
 func parseLogLevel(level string) slog.Level {
 	switch level {
 	case "debug":
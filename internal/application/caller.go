@@ -0,0 +1,22 @@
+package application
+
+import (
+	"context"
+
+	"fizzbuzz-service/internal/domain/entity"
+)
+
+type callerKey struct{}
+
+// WithCaller returns a context whose recorded hits are attributed to caller
+// Adapters resolve the caller (e.g. from credentials or the client address)
+// before calling the use cases.
+func WithCaller(ctx context.Context, caller entity.Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller of the request, zero when unknown
+func CallerFromContext(ctx context.Context) entity.Caller {
+	caller, _ := ctx.Value(callerKey{}).(entity.Caller)
+	return caller
+}
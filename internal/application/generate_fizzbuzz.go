@@ -2,23 +2,65 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fizzbuzz-service/internal/domain"
 	"fizzbuzz-service/internal/domain/entity"
 	"fizzbuzz-service/internal/domain/service"
+	"iter"
 	"log/slog"
-	"time"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// tracerName is the instrumentation scope of the spans of this package
+const tracerName = "fizzbuzz-service/internal/application"
+
 type GenerateFizzBuzzUseCase struct {
-	generator    *service.FizzBuzzGenerator
-	statsUpdater StatisticsUpdater
-	maxLimit     int
-	logger       *slog.Logger
+	generator      *service.FizzBuzzGenerator
+	statsUpdater   StatisticsUpdater
+	cache          *ResultCache
+	maxLimit       atomic.Int64
+	streamMaxLimit atomic.Int64
+	logger         *slog.Logger
+	tracer         trace.Tracer
 }
 
 // StatisticsUpdater is a port for updating statistics
 type StatisticsUpdater interface {
-	UpdateStats(ctx context.Context, query entity.FizzBuzzQuery) error
+	// UpdateStats counts a hit of query, globally and for caller when known
+	UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error
+}
+
+// GenerateOption customizes the use case beyond its required dependencies
+type GenerateOption func(*GenerateFizzBuzzUseCase)
+
+// WithStreamMaxLimit sets the maximum limit accepted by Stream
+// Defaults to the maxLimit used by Generate
+func WithStreamMaxLimit(limit int) GenerateOption {
+	return func(uc *GenerateFizzBuzzUseCase) {
+		uc.streamMaxLimit.Store(int64(limit))
+	}
+}
+
+// WithResultCache serves repeated queries of Generate from cache
+// Entries are keyed by FizzBuzzQuery.Key(), which quotes words so distinct
+// queries never share one. Streams are never cached.
+func WithResultCache(cache *ResultCache) GenerateOption {
+	return func(uc *GenerateFizzBuzzUseCase) {
+		uc.cache = cache
+	}
+}
+
+// WithTracerProvider traces Generate and Stream, and the generation itself
+// Defaults to a no-op provider
+func WithTracerProvider(tp trace.TracerProvider) GenerateOption {
+	return func(uc *GenerateFizzBuzzUseCase) {
+		uc.tracer = tp.Tracer(tracerName)
+	}
 }
 
 // NewGenerateFizzBuzzUseCase creates the use case
@@ -27,13 +69,31 @@ func NewGenerateFizzBuzzUseCase(
 	statsUpdater StatisticsUpdater,
 	maxLimit int,
 	logger *slog.Logger,
+	opts ...GenerateOption,
 ) *GenerateFizzBuzzUseCase {
-	return &GenerateFizzBuzzUseCase{
+	uc := &GenerateFizzBuzzUseCase{
 		generator:    generator,
 		statsUpdater: statsUpdater,
-		maxLimit:     maxLimit,
 		logger:       logger,
+		tracer:       noop.NewTracerProvider().Tracer(tracerName),
+	}
+	uc.maxLimit.Store(int64(maxLimit))
+	uc.streamMaxLimit.Store(int64(maxLimit))
+	for _, opt := range opts {
+		opt(uc)
 	}
+	return uc
+}
+
+// SetMaxLimit changes the maximum limit accepted by Generate
+// Safe to call while requests are served (e.g. on configuration reload).
+func (uc *GenerateFizzBuzzUseCase) SetMaxLimit(limit int) {
+	uc.maxLimit.Store(int64(limit))
+}
+
+// SetStreamMaxLimit changes the maximum limit accepted by Stream
+func (uc *GenerateFizzBuzzUseCase) SetStreamMaxLimit(limit int) {
+	uc.streamMaxLimit.Store(int64(limit))
 }
 
 // Generate validates input and generates the sequence
@@ -41,27 +101,162 @@ func (uc *GenerateFizzBuzzUseCase) Generate(
 	ctx context.Context,
 	query entity.FizzBuzzQuery,
 ) ([]string, error) {
-	// Validate with detailed error messages
-	validation := query.Validate(uc.maxLimit)
-	if !validation.Valid {
-		return nil, domain.NewValidationError("invalid parameters", validation.Errors...)
-	}
-
-	// Update statistics asynchronously
-	// We use a separate goroutine to not block the main request
-	// Errors are logged but don't fail the main request (stats are non-critical)
-	go func() {
-		// Create a new context with timeout, not tied to the request
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := uc.statsUpdater.UpdateStats(ctx, query); err != nil {
-			uc.logger.Error("failed to update statistics",
-				"error", err,
-				"query_key", query.Key(),
-			)
+	ctx, span := uc.tracer.Start(ctx, "GenerateFizzBuzzUseCase.Generate", trace.WithAttributes(queryAttributes(query)...))
+	defer span.End()
+
+	if err := validate(query, int(uc.maxLimit.Load())); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	uc.recordHit(ctx, query)
+
+	if uc.cache == nil {
+		return uc.generate(ctx, query), nil
+	}
+	result, hit := uc.cache.Get(query.Key(), func() []string { return uc.generate(ctx, query) })
+	span.SetAttributes(attribute.Bool("fizzbuzz.cache_hit", hit))
+	return result, nil
+}
+
+// GeneratePage validates input and generates only the numbers of page
+// Statistics count a hit of the whole query, whatever the page. Pages are
+// computed directly rather than cached.
+func (uc *GenerateFizzBuzzUseCase) GeneratePage(
+	ctx context.Context,
+	query entity.FizzBuzzQuery,
+	page entity.Page,
+) ([]string, error) {
+	ctx, span := uc.tracer.Start(ctx, "GenerateFizzBuzzUseCase.GeneratePage", trace.WithAttributes(
+		append(queryAttributes(query),
+			attribute.Int("fizzbuzz.offset", page.Offset),
+			attribute.Int("fizzbuzz.count", page.Count),
+		)...,
+	))
+	defer span.End()
+
+	if err := uc.ValidatePage(query, page); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	uc.recordHit(ctx, query)
+
+	return uc.generatePage(ctx, query, page), nil
+}
+
+// generate runs the domain service
+func (uc *GenerateFizzBuzzUseCase) generate(ctx context.Context, query entity.FizzBuzzQuery) []string {
+	// The domain service stays free of tracing; its span is opened here
+	_, span := uc.tracer.Start(ctx, "FizzBuzzGenerator.Generate")
+	defer span.End()
+
+	result := uc.generator.Generate(query)
+	span.SetAttributes(attribute.Int("fizzbuzz.result_length", len(result)))
+	return result
+}
+
+// generatePage runs the domain service on the numbers of page
+func (uc *GenerateFizzBuzzUseCase) generatePage(ctx context.Context, query entity.FizzBuzzQuery, page entity.Page) []string {
+	_, span := uc.tracer.Start(ctx, "FizzBuzzGenerator.GenerateRange")
+	defer span.End()
+
+	result := uc.generator.GenerateRange(query, page.Start(), page.End(query.UpperLimit))
+	span.SetAttributes(attribute.Int("fizzbuzz.result_length", len(result)))
+	return result
+}
+
+// Prewarm caches the sequences of queries, without counting hits
+// Queries Generate would reject are skipped. It returns the number of
+// sequences cached, and does nothing without a result cache.
+func (uc *GenerateFizzBuzzUseCase) Prewarm(ctx context.Context, queries []entity.FizzBuzzQuery) int {
+	if uc.cache == nil {
+		return 0
+	}
+
+	warmed := 0
+	for _, query := range queries {
+		if ctx.Err() != nil {
+			break
+		}
+		if uc.Validate(query) != nil {
+			continue
 		}
-	}()
+		uc.cache.Warm(query.Key(), func() []string { return uc.generate(ctx, query) })
+		warmed++
+	}
+	return warmed
+}
+
+// Validate checks the query against the limit enforced by Generate
+// It lets callers short-circuit (e.g. HTTP revalidation) without generating.
+func (uc *GenerateFizzBuzzUseCase) Validate(query entity.FizzBuzzQuery) error {
+	return validate(query, int(uc.maxLimit.Load()))
+}
 
-	return uc.generator.Generate(query), nil
+// ValidatePage checks the query and the page enforced by GeneratePage
+func (uc *GenerateFizzBuzzUseCase) ValidatePage(query entity.FizzBuzzQuery, page entity.Page) error {
+	validation := query.Validate(int(uc.maxLimit.Load()))
+	violations := append(validation.Violations, page.Validate(query.UpperLimit)...)
+	if len(violations) > 0 {
+		return domain.NewValidationError("invalid parameters", violations...)
+	}
+	return nil
+}
+
+// Stream validates input and returns a lazy sequence of (number, output) pairs
+// The sequence is computed while it is consumed, so callers can serve limits
+// far beyond what Generate buffers in memory. Statistics are recorded once,
+// when the stream is accepted.
+func (uc *GenerateFizzBuzzUseCase) Stream(
+	ctx context.Context,
+	query entity.FizzBuzzQuery,
+) (iter.Seq2[int, string], error) {
+	ctx, span := uc.tracer.Start(ctx, "GenerateFizzBuzzUseCase.Stream", trace.WithAttributes(queryAttributes(query)...))
+	defer span.End()
+
+	if err := validate(query, int(uc.streamMaxLimit.Load())); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	uc.recordHit(ctx, query)
+
+	return uc.generator.Sequence(query), nil
+}
+
+// validate checks the query with detailed error messages
+func validate(query entity.FizzBuzzQuery, maxLimit int) error {
+	validation := query.Validate(maxLimit)
+	if !validation.Valid {
+		return domain.NewValidationError("invalid parameters", validation.Violations...)
+	}
+	return nil
+}
+
+// queryAttributes describes a query on spans
+func queryAttributes(query entity.FizzBuzzQuery) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("fizzbuzz.limit", query.UpperLimit),
+		attribute.Int("fizzbuzz.rules", len(query.RuleSet())),
+	}
+}
+
+// recordHit hands the query over to the statistics updater, attributed to
+// the caller of ctx (see WithCaller)
+// Errors are logged but don't fail the main request (stats are non-critical).
+// Wire a StatisticsDispatcher as updater to record hits in the background.
+func (uc *GenerateFizzBuzzUseCase) recordHit(ctx context.Context, query entity.FizzBuzzQuery) {
+	err := uc.statsUpdater.UpdateStats(ctx, query, CallerFromContext(ctx))
+	if errors.Is(err, ErrStatisticsDropped) {
+		// Counted by the dispatcher; logging each one would flood the logs under load
+		uc.logger.Debug("statistics hit dropped", "query_key", query.Key())
+		return
+	}
+	if err != nil {
+		uc.logger.Error("failed to update statistics",
+			"error", err,
+			"query_key", query.Key(),
+		)
+	}
 }
@@ -2,10 +2,22 @@ package application
 
 import (
 	"context"
+	"time"
 
+	"fizzbuzz-service/internal/domain"
 	"fizzbuzz-service/internal/domain/entity"
 )
 
+const (
+	// DefaultTopLimit is the page size when none is requested
+	DefaultTopLimit = 10
+	// MaxTopLimit bounds the page size of the ranking
+	MaxTopLimit = 100
+	// MinWindow and MaxWindow bound time-windowed statistics
+	MinWindow = time.Minute
+	MaxWindow = 24 * time.Hour
+)
+
 // GetStatisticsUseCase retrieves the most frequent request
 type GetStatisticsUseCase struct {
 	repo StatisticsRepository
@@ -14,6 +26,13 @@ type GetStatisticsUseCase struct {
 // StatisticsRepository is a port for reading statistics
 type StatisticsRepository interface {
 	GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error)
+	// GetTop returns up to query.Limit entries ordered by entity.RanksBefore,
+	// starting after query.After, with their 1-based rank
+	GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error)
+	// GetClients returns up to query.Limit clients ordered by
+	// entity.RanksBefore, starting after query.After, with their 1-based rank
+	// and most frequent request
+	GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error)
 }
 
 // NewGetStatisticsUseCase creates the use case
@@ -25,3 +44,198 @@ func NewGetStatisticsUseCase(repo StatisticsRepository) *GetStatisticsUseCase {
 func (uc *GetStatisticsUseCase) Get(ctx context.Context) (*entity.StatisticsSummary, error) {
 	return uc.repo.GetMostFrequent(ctx)
 }
+
+// TopRequest selects a page of the ranking
+type TopRequest struct {
+	// Limit is the page size
+	Limit int
+	// Cursor is empty for the first page, then the NextCursor of the previous page
+	Cursor string
+	// Window restricts counts to the last period; zero means all time
+	Window time.Duration
+	// Client restricts counts to the hits of one client; empty means all
+	Client string
+}
+
+// GetInWindow returns the most frequent request of the last window
+// A zero window is the same as Get.
+func (uc *GetStatisticsUseCase) GetInWindow(ctx context.Context, window time.Duration) (*entity.StatisticsSummary, error) {
+	if window == 0 {
+		return uc.Get(ctx)
+	}
+
+	if violations := validateWindow(window); len(violations) > 0 {
+		return nil, domain.NewValidationError("invalid parameters", violations...)
+	}
+
+	entries, err := uc.repo.GetTop(ctx, entity.TopQuery{Limit: 1, Window: window})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return &entity.StatisticsSummary{}, nil
+	}
+	return &entity.StatisticsSummary{
+		MostFrequentQuery: entries[0].Query,
+		HitCount:          entries[0].HitCount,
+	}, nil
+}
+
+// GetForClient returns the most frequent request of one client
+// Hits per client are only counted all time, so window must be zero.
+func (uc *GetStatisticsUseCase) GetForClient(ctx context.Context, client string, window time.Duration) (*entity.StatisticsSummary, error) {
+	var violations []domain.Violation
+	if client == "" {
+		violations = append(violations, domain.Invalid("client", domain.CodeRequired, "client is unknown"))
+	}
+	if window != 0 {
+		violations = append(violations, windowPerClient())
+	}
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError("invalid parameters", violations...)
+	}
+
+	entries, err := uc.repo.GetTop(ctx, entity.TopQuery{Limit: 1, Client: client})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return &entity.StatisticsSummary{}, nil
+	}
+	return &entity.StatisticsSummary{
+		MostFrequentQuery: entries[0].Query,
+		HitCount:          entries[0].HitCount,
+	}, nil
+}
+
+// Top returns one page of the ranking of requests
+func (uc *GetStatisticsUseCase) Top(ctx context.Context, req TopRequest) (*entity.TopStatistics, error) {
+	limit := req.Limit
+
+	var violations []domain.Violation
+	if limit <= 0 || limit > MaxTopLimit {
+		violations = append(violations, domain.OutOfRange("n", 1, MaxTopLimit))
+	}
+	if req.Window != 0 {
+		violations = append(violations, validateWindow(req.Window)...)
+		if req.Client != "" {
+			violations = append(violations, windowPerClient())
+		}
+	}
+
+	query := entity.TopQuery{
+		Limit:  limit + 1, // one extra entry tells whether a next page exists
+		Window: req.Window,
+		Client: req.Client,
+	}
+	if req.Cursor != "" {
+		after, err := entity.DecodeRankCursor(req.Cursor)
+		if err != nil {
+			violations = append(violations, domain.Invalid("cursor", domain.CodeInvalid, "cursor is invalid"))
+		}
+		query.After = &after
+	}
+
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError("invalid parameters", violations...)
+	}
+
+	entries, err := uc.repo.GetTop(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []entity.StatisticsEntry{}
+	}
+
+	page := &entity.TopStatistics{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = entity.CursorOf(page.Entries[limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// TopQueries returns the n most frequent queries, all time
+func (uc *GetStatisticsUseCase) TopQueries(ctx context.Context, n int) ([]entity.FizzBuzzQuery, error) {
+	var (
+		queries []entity.FizzBuzzQuery
+		cursor  string
+	)
+	for len(queries) < n {
+		page, err := uc.Top(ctx, TopRequest{Limit: min(n-len(queries), MaxTopLimit), Cursor: cursor})
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range page.Entries {
+			queries = append(queries, entry.Query.ToQuery())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return queries, nil
+}
+
+// ClientsRequest selects a page of the ranking of clients
+type ClientsRequest struct {
+	// Limit is the page size
+	Limit int
+	// Cursor is empty for the first page, then the NextCursor of the previous page
+	Cursor string
+}
+
+// Clients returns one page of the ranking of clients, by number of hits
+func (uc *GetStatisticsUseCase) Clients(ctx context.Context, req ClientsRequest) (*entity.ClientStatistics, error) {
+	limit := req.Limit
+
+	var violations []domain.Violation
+	if limit <= 0 || limit > MaxTopLimit {
+		violations = append(violations, domain.OutOfRange("n", 1, MaxTopLimit))
+	}
+
+	query := entity.ClientQuery{Limit: limit + 1}
+	if req.Cursor != "" {
+		after, err := entity.DecodeRankCursor(req.Cursor)
+		if err != nil {
+			violations = append(violations, domain.Invalid("cursor", domain.CodeInvalid, "cursor is invalid"))
+		}
+		query.After = &after
+	}
+
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError("invalid parameters", violations...)
+	}
+
+	entries, err := uc.repo.GetClients(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []entity.ClientEntry{}
+	}
+
+	page := &entity.ClientStatistics{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = page.Entries[limit-1].Cursor().Encode()
+	}
+	return page, nil
+}
+
+func validateWindow(window time.Duration) []domain.Violation {
+	if window < MinWindow || window > MaxWindow {
+		return []domain.Violation{domain.OutOfRange("window", MinWindow.String(), MaxWindow.String())}
+	}
+	return nil
+}
+
+// windowPerClient reports a window given with a client
+func windowPerClient() domain.Violation {
+	return domain.Invalid("window", domain.CodeUnsupported, "window is not supported per client")
+}
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Approximate memory cost of a cached sequence, beyond the bytes of its
+// strings: the slice header and one string header per value
+const (
+	sliceOverhead  = 24
+	stringOverhead = 16
+)
+
+// ResultCache keeps generated sequences by query key, evicting the least
+// recently used once their total size exceeds a budget in bytes
+// Concurrent misses of the same key share a single generation. Cached
+// sequences are shared by every caller and must not be modified.
+type ResultCache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+	bytes   int64
+
+	group singleflight.Group
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	shared    atomic.Int64
+	evictions atomic.Int64
+}
+
+// cacheEntry is one cached sequence
+type cacheEntry struct {
+	key    string
+	result []string
+	size   int64
+}
+
+// ResultCacheStats is a snapshot of the cache counters
+type ResultCacheStats struct {
+	Entries  int
+	Bytes    int64
+	MaxBytes int64
+	// Hits were served from the cache
+	Hits int64
+	// Misses were generated, Shared of them by another concurrent miss
+	Misses    int64
+	Shared    int64
+	Evictions int64
+}
+
+// NewResultCache creates a cache holding up to maxBytes of sequences
+func NewResultCache(maxBytes int64) *ResultCache {
+	return &ResultCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the sequence cached under key, or generates and caches it
+// hit reports whether it came from the cache.
+func (c *ResultCache) Get(key string, generate func() []string) (result []string, hit bool) {
+	if result, ok := c.lookup(key); ok {
+		c.hits.Add(1)
+		return result, true
+	}
+
+	c.misses.Add(1)
+	joined := true
+	v, _, _ := c.group.Do(key, func() (any, error) {
+		joined = false
+		// A concurrent call may have filled the cache since the lookup
+		if result, ok := c.lookup(key); ok {
+			return result, nil
+		}
+		result := generate()
+		c.add(key, result)
+		return result, nil
+	})
+	if joined {
+		c.shared.Add(1)
+	}
+	return v.([]string), false
+}
+
+// Warm caches the sequence of key unless it already is, without counting
+// a hit or a miss
+func (c *ResultCache) Warm(key string, generate func() []string) {
+	if _, ok := c.lookup(key); ok {
+		return
+	}
+	c.group.Do(key, func() (any, error) {
+		result := generate()
+		c.add(key, result)
+		return result, nil
+	})
+}
+
+// Stats returns the current size and counters
+func (c *ResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	entries, bytes := len(c.entries), c.bytes
+	c.mu.Unlock()
+
+	return ResultCacheStats{
+		Entries:   entries,
+		Bytes:     bytes,
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Shared:    c.shared.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// lookup returns the sequence of key and marks it as recently used
+func (c *ResultCache) lookup(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).result, true
+}
+
+// add caches result, evicting the least recently used sequences to make room
+// Sequences larger than the whole budget are not cached.
+func (c *ResultCache) add(key string, result []string) {
+	size := resultSize(key, result)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	for c.bytes+size > c.maxBytes {
+		c.evict(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, size: size})
+	c.bytes += size
+}
+
+// evict removes elem; c.mu must be held
+func (c *ResultCache) evict(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+	c.evictions.Add(1)
+}
+
+// resultSize estimates the memory held by a cached sequence
+func resultSize(key string, result []string) int64 {
+	size := int64(len(key) + sliceOverhead)
+	for _, s := range result {
+		size += int64(len(s) + stringOverhead)
+	}
+	return size
+}
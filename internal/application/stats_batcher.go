@@ -0,0 +1,246 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+)
+
+// ErrBatcherClosed is returned by UpdateStats after Close
+var ErrBatcherClosed = errors.New("statistics batcher closed")
+
+// HitDelta is a number of hits of one query by one caller
+type HitDelta struct {
+	Query  entity.FizzBuzzQuery
+	Caller entity.Caller
+	Hits   int64
+}
+
+// BatchStatisticsUpdater is implemented by updaters that record many hits at
+// once, typically under a single lock or transaction
+// Hits are timestamped when the batch is recorded.
+type BatchStatisticsUpdater interface {
+	StatisticsUpdater
+	UpdateStatsBatch(ctx context.Context, deltas []HitDelta) error
+}
+
+// BatcherConfig tunes the aggregation of hits before they are recorded
+type BatcherConfig struct {
+	// Shards is the number of independently locked buffers (at least 1)
+	Shards int
+	// FlushInterval is how often every buffer is flushed
+	FlushInterval time.Duration
+	// MaxPending flushes a buffer early once it holds that many hits
+	MaxPending int64
+	// Timeout bounds each flush into the wrapped updater
+	Timeout time.Duration
+}
+
+// DefaultBatcherConfig returns sensible defaults
+func DefaultBatcherConfig() BatcherConfig {
+	return BatcherConfig{
+		Shards:        16,
+		FlushInterval: 100 * time.Millisecond,
+		MaxPending:    1024,
+		Timeout:       5 * time.Second,
+	}
+}
+
+// StatisticsBatcher aggregates hits per query key and caller before recording them
+// It is a StatisticsUpdater: UpdateStats only adds to a local buffer, picked
+// by key so concurrent requests rarely share a lock, and the buffers are
+// flushed periodically or when full into the wrapped updater, in one call if
+// it implements BatchStatisticsUpdater. Hits are visible in statistics once
+// flushed, so at most FlushInterval late.
+type StatisticsBatcher struct {
+	updater StatisticsUpdater
+	config  BatcherConfig
+	logger  *slog.Logger
+
+	seed   maphash.Seed
+	shards []batchShard
+
+	// full receives the index of a shard that reached MaxPending
+	full chan int
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	// flushMu serializes flushes so Close flushes after the background ones
+	flushMu sync.Mutex
+
+	// mu guards closing against concurrent additions, so the final flush
+	// sees every hit accepted
+	mu     sync.RWMutex
+	closed bool
+}
+
+type batchShard struct {
+	mu      sync.Mutex
+	deltas  map[deltaKey]*HitDelta
+	pending int64
+}
+
+// deltaKey identifies the hits of one query by one caller
+type deltaKey struct {
+	query  string
+	caller string
+}
+
+// NewStatisticsBatcher starts the background flushes
+// Call Close to flush the remaining hits on shutdown.
+func NewStatisticsBatcher(updater StatisticsUpdater, config BatcherConfig, logger *slog.Logger) *StatisticsBatcher {
+	config.Shards = max(config.Shards, 1)
+
+	b := &StatisticsBatcher{
+		updater: updater,
+		config:  config,
+		logger:  logger,
+		seed:    maphash.MakeSeed(),
+		shards:  make([]batchShard, config.Shards),
+		full:    make(chan int, config.Shards),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i := range b.shards {
+		b.shards[i].deltas = make(map[deltaKey]*HitDelta)
+	}
+
+	go b.run()
+	return b
+}
+
+// UpdateStats adds the hit to its buffer
+func (b *StatisticsBatcher) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBatcherClosed
+	}
+
+	key := query.Key()
+	index := int(maphash.String(b.seed, key) % uint64(len(b.shards)))
+	shard := &b.shards[index]
+
+	id := deltaKey{query: key, caller: caller.ID}
+
+	shard.mu.Lock()
+	if delta, ok := shard.deltas[id]; ok {
+		delta.Hits++
+	} else {
+		shard.deltas[id] = &HitDelta{Query: query, Caller: caller, Hits: 1}
+	}
+	shard.pending++
+	full := shard.pending == b.config.MaxPending
+	shard.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- index:
+		default: // a flush is already due
+		}
+	}
+	return nil
+}
+
+// Flush records every buffered hit now
+func (b *StatisticsBatcher) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	var deltas []HitDelta
+	for i := range b.shards {
+		deltas = append(deltas, b.shards[i].drain()...)
+	}
+	return b.record(ctx, deltas)
+}
+
+// Close stops background flushes and records the remaining hits
+// Safe to call more than once.
+func (b *StatisticsBatcher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.once.Do(func() { close(b.stop) })
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return b.Flush(ctx)
+}
+
+func (b *StatisticsBatcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.flushInBackground(b.Flush)
+		case index := <-b.full:
+			b.flushInBackground(func(ctx context.Context) error {
+				b.flushMu.Lock()
+				defer b.flushMu.Unlock()
+				return b.record(ctx, b.shards[index].drain())
+			})
+		}
+	}
+}
+
+func (b *StatisticsBatcher) flushInBackground(flush func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.Timeout)
+	defer cancel()
+
+	if err := flush(ctx); err != nil {
+		b.logger.Error("failed to flush statistics", "error", err)
+	}
+}
+
+// record forwards deltas to the wrapped updater
+func (b *StatisticsBatcher) record(ctx context.Context, deltas []HitDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	if batch, ok := b.updater.(BatchStatisticsUpdater); ok {
+		return batch.UpdateStatsBatch(ctx, deltas)
+	}
+
+	var errs []error
+	for _, delta := range deltas {
+		for range delta.Hits {
+			if err := b.updater.UpdateStats(ctx, delta.Query, delta.Caller); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// drain empties the shard and returns its deltas
+func (s *batchShard) drain() []HitDelta {
+	s.mu.Lock()
+	deltas := s.deltas
+	s.deltas = make(map[deltaKey]*HitDelta, len(deltas))
+	s.pending = 0
+	s.mu.Unlock()
+
+	list := make([]HitDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		list = append(list, *delta)
+	}
+	return list
+}
@@ -0,0 +1,288 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// OverflowPolicy decides what happens to a hit when the dispatcher queue is full
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards the new hit
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowDropOldest discards the oldest queued hit to make room
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowBlock waits for room, until the caller's context is done
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// ParseOverflowPolicy validates a policy name
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(s); policy {
+	case OverflowDrop, OverflowDropOldest, OverflowBlock:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy %q (expected %s, %s or %s)",
+			s, OverflowDrop, OverflowDropOldest, OverflowBlock)
+	}
+}
+
+var (
+	// ErrStatisticsDropped is returned by UpdateStats when the hit was discarded
+	ErrStatisticsDropped = errors.New("statistics queue full, hit dropped")
+	// ErrDispatcherClosed is returned by UpdateStats after Close
+	ErrDispatcherClosed = errors.New("statistics dispatcher closed")
+)
+
+// DispatcherConfig bounds the resources used by asynchronous statistics
+type DispatcherConfig struct {
+	// QueueSize is the number of hits waiting to be recorded
+	QueueSize int
+	// Workers is the number of concurrent UpdateStats calls on the repository (at least 1)
+	Workers int
+	// Overflow applies when the queue is full
+	Overflow OverflowPolicy
+	// Timeout bounds each UpdateStats call on the repository
+	Timeout time.Duration
+}
+
+// DefaultDispatcherConfig returns sensible defaults
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		QueueSize: 1024,
+		Workers:   4,
+		Overflow:  OverflowDrop,
+		Timeout:   5 * time.Second,
+	}
+}
+
+// DispatcherStats is a point-in-time view of the dispatcher, for metrics
+type DispatcherStats struct {
+	QueueDepth    int
+	QueueCapacity int
+	// Counters since startup
+	Processed uint64
+	Failed    uint64
+	Dropped   uint64
+}
+
+// DispatcherOption customizes the dispatcher beyond its required dependencies
+type DispatcherOption func(*StatisticsDispatcher)
+
+// WithDispatcherTracerProvider traces each background update
+// Its span starts a new trace, linked to the span of the request that queued
+// the hit. Defaults to a no-op provider.
+func WithDispatcherTracerProvider(tp trace.TracerProvider) DispatcherOption {
+	return func(d *StatisticsDispatcher) {
+		d.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// StatisticsDispatcher records hits in the background with bounded resources
+// It is a StatisticsUpdater: UpdateStats only queues the hit, and a fixed pool
+// of workers forwards it to the wrapped updater with a detached context, so
+// recording completes even if the request that caused it is cancelled.
+type StatisticsDispatcher struct {
+	updater StatisticsUpdater
+	config  DispatcherConfig
+	logger  *slog.Logger
+	tracer  trace.Tracer
+
+	// mu guards closing the queue against concurrent sends
+	mu     sync.RWMutex
+	closed bool
+	queue  chan queuedHit
+	done   chan struct{}
+	// closing is closed when Close starts, so blocked senders release mu
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// queuedHit is a hit waiting for a worker
+type queuedHit struct {
+	query  entity.FizzBuzzQuery
+	caller entity.Caller
+	// origin is the span of the request that caused the hit, if any
+	origin trace.SpanContext
+}
+
+// NewStatisticsDispatcher starts the workers
+// Call Close to drain the queue on shutdown.
+func NewStatisticsDispatcher(
+	updater StatisticsUpdater,
+	config DispatcherConfig,
+	logger *slog.Logger,
+	opts ...DispatcherOption,
+) *StatisticsDispatcher {
+	config.Workers = max(config.Workers, 1)
+
+	d := &StatisticsDispatcher{
+		updater: updater,
+		config:  config,
+		logger:  logger,
+		tracer:  noop.NewTracerProvider().Tracer(tracerName),
+		queue:   make(chan queuedHit, config.QueueSize),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	var workers sync.WaitGroup
+	workers.Add(config.Workers)
+	for range config.Workers {
+		go func() {
+			defer workers.Done()
+			d.work()
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(d.done)
+	}()
+
+	return d
+}
+
+// UpdateStats queues the hit according to the overflow policy
+// With OverflowBlock, ctx bounds the wait for room in the queue, which also
+// ends with ErrDispatcherClosed when Close is called.
+func (d *StatisticsDispatcher) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	hit := queuedHit{query: query, caller: caller, origin: trace.SpanContextFromContext(ctx)}
+
+	select {
+	case d.queue <- hit:
+		return nil
+	default:
+	}
+
+	switch d.config.Overflow {
+	case OverflowBlock:
+		select {
+		case d.queue <- hit:
+			return nil
+		case <-ctx.Done():
+			d.dropped.Add(1)
+			return fmt.Errorf("%w: %w", ErrStatisticsDropped, ctx.Err())
+		case <-d.closing:
+			return ErrDispatcherClosed
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case d.queue <- hit:
+				return nil
+			default:
+			}
+			select {
+			case <-d.queue:
+				d.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		d.dropped.Add(1)
+		return ErrStatisticsDropped
+	}
+}
+
+// Stats returns the current queue depth and counters
+func (d *StatisticsDispatcher) Stats() DispatcherStats {
+	return DispatcherStats{
+		QueueDepth:    len(d.queue),
+		QueueCapacity: cap(d.queue),
+		Processed:     d.processed.Load(),
+		Failed:        d.failed.Load(),
+		Dropped:       d.dropped.Load(),
+	}
+}
+
+// Close stops accepting hits and waits for the queued ones to be recorded
+// If ctx ends first, the remaining hits are lost and ctx.Err() is returned.
+// Safe to call more than once.
+func (d *StatisticsDispatcher) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() { close(d.closing) })
+
+	// Blocked senders give up on closing; the lock is still awaited with ctx
+	// so that Close never outlives it
+	locked := make(chan struct{})
+	go func() {
+		d.mu.Lock()
+		if !d.closed {
+			d.closed = true
+			close(d.queue)
+		}
+		d.mu.Unlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		return fmt.Errorf("close statistics queue: %w", ctx.Err())
+	}
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("drain statistics queue (%d pending): %w", len(d.queue), ctx.Err())
+	}
+}
+
+func (d *StatisticsDispatcher) work() {
+	for hit := range d.queue {
+		d.record(hit)
+	}
+}
+
+// record updates statistics with a context not tied to the request
+// Errors are logged but don't fail the main request (stats are non-critical)
+func (d *StatisticsDispatcher) record(hit queuedHit) {
+	query := hit.query
+
+	var links []trace.Link
+	if hit.origin.IsValid() {
+		links = append(links, trace.Link{SpanContext: hit.origin})
+	}
+	ctx, span := d.tracer.Start(context.Background(), "StatisticsDispatcher.record", trace.WithLinks(links...))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	if err := d.updater.UpdateStats(ctx, query, hit.caller); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		d.failed.Add(1)
+		d.logger.Error("failed to update statistics",
+			"error", err,
+			"query_key", query.Key(),
+		)
+		return
+	}
+	d.processed.Add(1)
+}
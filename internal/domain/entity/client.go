@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// Caller is who made a request, so statistics can be kept per client
+type Caller struct {
+	// ID identifies the client, e.g. "api_key:ci", "jwt:alice" or
+	// "ip:203.0.113.7"; empty when unknown, in which case hits are only
+	// counted globally
+	ID string
+}
+
+// ClientEntry is one client of the per-client statistics
+type ClientEntry struct {
+	Rank      int       `json:"rank"`
+	Client    string    `json:"client"`
+	HitCount  int64     `json:"hits"`
+	LastHitAt time.Time `json:"last_hit_at"`
+	// MostFrequentQuery is the request the client made most often
+	MostFrequentQuery *FizzBuzzQueryResponse `json:"most_frequent_request"`
+	MostFrequentHits  int64                  `json:"most_frequent_hits"`
+}
+
+// Cursor returns the position of the client in the ranking of clients
+func (e ClientEntry) Cursor() RankCursor {
+	return RankCursor{HitCount: e.HitCount, LastHitAt: e.LastHitAt, Key: e.Client}
+}
+
+// ClientStatistics is one page of the ranking of clients
+type ClientStatistics struct {
+	Entries []ClientEntry `json:"entries"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ClientQuery selects a page of the ranking of clients
+// Clients are ranked like queries (see RanksBefore), their ID as key.
+type ClientQuery struct {
+	// Limit is the maximum number of entries returned
+	Limit int
+	// After, when set, skips every client ranked up to and including it
+	After *RankCursor
+}
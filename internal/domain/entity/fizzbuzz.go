@@ -1,6 +1,20 @@
 package entity
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"fizzbuzz-service/internal/domain"
+)
+
+// MaxRules bounds the number of rules a single query may carry
+const MaxRules = 16
+
+// Rule replaces multiples of Divisor with Word
+type Rule struct {
+	Divisor int
+	Word    string
+}
 
 type FizzBuzzQuery struct {
 	FirstDivisor  int
@@ -8,52 +22,122 @@ type FizzBuzzQuery struct {
 	UpperLimit    int
 	FirstString   string
 	SecondString  string
+	// Rules, when set, replaces the two-divisor fields above with an
+	// ordered list of rules. Words of matching rules are joined in order.
+	Rules []Rule
 }
 
 type ValidationResult struct {
-	Valid  bool
-	Errors []string
+	Valid      bool
+	Violations []domain.Violation
 }
 
-func (q *FizzBuzzQuery) Validate(maxLimit int) ValidationResult {
-	var errors []string
+// HasRules reports whether the query uses the rules-list form
+func (q FizzBuzzQuery) HasRules() bool {
+	return len(q.Rules) > 0
+}
 
-	if q.FirstDivisor <= 0 {
-		errors = append(errors, "int1 must be greater than 0")
+// RuleSet returns the rules to apply, in order
+// The two-divisor form is the shortcut for a list of exactly two rules
+func (q FizzBuzzQuery) RuleSet() []Rule {
+	if q.HasRules() {
+		return q.Rules
 	}
+	return []Rule{
+		{Divisor: q.FirstDivisor, Word: q.FirstString},
+		{Divisor: q.SecondDivisor, Word: q.SecondString},
+	}
+}
 
-	if q.SecondDivisor <= 0 {
-		errors = append(errors, "int2 must be greater than 0")
+func (q *FizzBuzzQuery) Validate(maxLimit int) ValidationResult {
+	var violations []domain.Violation
+
+	if q.HasRules() {
+		violations = append(violations, q.validateRules()...)
+	} else {
+		violations = append(violations, q.validatePair()...)
 	}
 
 	if q.UpperLimit <= 0 {
-		errors = append(errors, "limit must be greater than 0")
+		violations = append(violations, domain.MustBePositive("limit"))
 	} else if q.UpperLimit > maxLimit {
-		errors = append(errors, fmt.Sprintf("limit exceeds maximum allowed value of %d", maxLimit))
+		violations = append(violations, domain.ExceedsMax("limit", maxLimit))
+	}
+
+	return ValidationResult{
+		Valid:      len(violations) == 0,
+		Violations: violations,
+	}
+}
+
+// validatePair checks the two-divisor form
+func (q *FizzBuzzQuery) validatePair() []domain.Violation {
+	var violations []domain.Violation
+
+	if q.FirstDivisor <= 0 {
+		violations = append(violations, domain.MustBePositive("int1"))
+	}
+
+	if q.SecondDivisor <= 0 {
+		violations = append(violations, domain.MustBePositive("int2"))
 	}
 
 	if q.FirstString == "" {
-		errors = append(errors, "str1 cannot be empty")
+		violations = append(violations, domain.Required("str1"))
 	}
 
 	if q.SecondString == "" {
-		errors = append(errors, "str2 cannot be empty")
+		violations = append(violations, domain.Required("str2"))
 	}
 
-	return ValidationResult{
-		Valid:  len(errors) == 0,
-		Errors: errors,
+	return violations
+}
+
+// validateRules checks the rules-list form
+func (q *FizzBuzzQuery) validateRules() []domain.Violation {
+	var violations []domain.Violation
+
+	if q.FirstDivisor != 0 || q.SecondDivisor != 0 || q.FirstString != "" || q.SecondString != "" {
+		violations = append(violations, domain.MutuallyExclusive("rules", "int1", "int2", "str1", "str2"))
 	}
+
+	if len(q.Rules) > MaxRules {
+		violations = append(violations, domain.TooMany("rules", MaxRules))
+	}
+
+	for i, rule := range q.Rules {
+		if rule.Divisor <= 0 {
+			violations = append(violations, domain.MustBePositive(fmt.Sprintf("rules[%d].divisor", i)))
+		}
+		if rule.Word == "" {
+			violations = append(violations, domain.Required(fmt.Sprintf("rules[%d].word", i)))
+		}
+	}
+
+	return violations
 }
 
 // Key generates a unique identifier for this query (used for statistics)
-// Includes ALL parameters to correctly track unique request patterns
+// Includes ALL parameters to correctly track unique request patterns.
+// Words are quoted, so separators inside them cannot make two queries share
+// a key. Two-rule queries have the same key whichever form they were built
+// with, so both forms are counted as the same request.
 func (q FizzBuzzQuery) Key() string {
-	return fmt.Sprintf("%d:%d:%d:%s:%s",
-		q.FirstDivisor,
-		q.SecondDivisor,
-		q.UpperLimit,
-		q.FirstString,
-		q.SecondString,
-	)
+	rules := q.RuleSet()
+	if len(rules) == 2 {
+		return fmt.Sprintf("%d:%d:%d:%q:%q",
+			rules[0].Divisor,
+			rules[1].Divisor,
+			q.UpperLimit,
+			rules[0].Word,
+			rules[1].Word,
+		)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", q.UpperLimit)
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "|%d:%q", rule.Divisor, rule.Word)
+	}
+	return b.String()
 }
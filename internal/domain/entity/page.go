@@ -0,0 +1,64 @@
+package entity
+
+import "fizzbuzz-service/internal/domain"
+
+// Page is a window of a sequence: Count numbers starting after the first
+// Offset ones
+type Page struct {
+	Offset int
+	Count  int
+}
+
+// Validate checks that the page starts within limit and is no longer than it
+// The last page may run past limit; it is then truncated (see End).
+func (p Page) Validate(limit int) []domain.Violation {
+	if limit <= 0 {
+		// The limit itself is invalid, reported by FizzBuzzQuery.Validate
+		return nil
+	}
+
+	var violations []domain.Violation
+
+	if p.Offset < 0 || p.Offset >= limit {
+		violations = append(violations, domain.OutOfRange("offset", 0, limit-1))
+	}
+
+	if p.Count < 1 || p.Count > limit {
+		violations = append(violations, domain.OutOfRange("count", 1, limit))
+	}
+
+	return violations
+}
+
+// Start is the number the page starts with
+func (p Page) Start() int {
+	return p.Offset + 1
+}
+
+// End is the number the page ends with, truncated to limit
+func (p Page) End(limit int) int {
+	return min(p.Offset+p.Count, limit)
+}
+
+// Prev returns the page before p, if any
+// It starts at 0 rather than before the beginning of the sequence.
+func (p Page) Prev() (Page, bool) {
+	if p.Offset == 0 {
+		return Page{}, false
+	}
+	return Page{Offset: max(p.Offset-p.Count, 0), Count: p.Count}, true
+}
+
+// Next returns the page after p, if any
+func (p Page) Next(limit int) (Page, bool) {
+	if p.Offset+p.Count >= limit {
+		return Page{}, false
+	}
+	return Page{Offset: p.Offset + p.Count, Count: p.Count}, true
+}
+
+// LastPage returns the page holding the end of the sequence, its offset
+// aligned on a multiple of Count
+func (p Page) LastPage(limit int) Page {
+	return Page{Offset: (limit - 1) / p.Count * p.Count, Count: p.Count}
+}
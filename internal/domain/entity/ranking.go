@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// StatisticsEntry is one query of the statistics ranking
+type StatisticsEntry struct {
+	Rank      int                    `json:"rank"`
+	Query     *FizzBuzzQueryResponse `json:"request"`
+	HitCount  int64                  `json:"hits"`
+	LastHitAt time.Time              `json:"last_hit_at"`
+	// Key identifies the query; it breaks the last ties of the ranking
+	Key string `json:"-"`
+}
+
+// TopStatistics is one page of the statistics ranking
+type TopStatistics struct {
+	Entries []StatisticsEntry `json:"entries"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TopQuery selects a page of the ranking
+type TopQuery struct {
+	// Limit is the maximum number of entries returned
+	Limit int
+	// After, when set, skips every entry ranked up to and including it
+	After *RankCursor
+	// Window, when set, only counts hits of that last period
+	Window time.Duration
+	// Client, when set, only counts hits of that client (see Caller)
+	// It cannot be combined with Window.
+	Client string
+}
+
+// RankCursor is the position of an entry in the ranking
+// Keyset-based, so pages stay consistent while unrelated entries change.
+type RankCursor struct {
+	HitCount  int64     `json:"h"`
+	LastHitAt time.Time `json:"t"`
+	Key       string    `json:"k"`
+}
+
+// ErrInvalidCursor is returned when a cursor was not produced by Encode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorOf returns the position of an entry
+func CursorOf(entry StatisticsEntry) RankCursor {
+	return RankCursor{
+		HitCount:  entry.HitCount,
+		LastHitAt: entry.LastHitAt,
+		Key:       entry.Key,
+	}
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c RankCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeRankCursor parses a cursor produced by Encode
+func DecodeRankCursor(s string) (RankCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return RankCursor{}, ErrInvalidCursor
+	}
+
+	var c RankCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Key == "" {
+		return RankCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// RanksBefore reports whether a is ranked before b
+// Most hits first, then most recently hit, then by key so the order is total.
+func RanksBefore(a, b RankCursor) bool {
+	if a.HitCount != b.HitCount {
+		return a.HitCount > b.HitCount
+	}
+	if !a.LastHitAt.Equal(b.LastHitAt) {
+		return a.LastHitAt.After(b.LastHitAt)
+	}
+	return a.Key < b.Key
+}
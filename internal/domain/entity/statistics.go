@@ -8,21 +8,51 @@ type StatisticsSummary struct {
 
 // FizzBuzzQueryResponse is the JSON representation of a query
 // Separate from FizzBuzzQuery to control API contract
+// int1/int2/str1/str2 are only set for two-rule queries, but always present
+// as in earlier versions
 type FizzBuzzQueryResponse struct {
-	Int1  int    `json:"int1"`
-	Int2  int    `json:"int2"`
-	Limit int    `json:"limit"`
-	Str1  string `json:"str1"`
-	Str2  string `json:"str2"`
+	Int1  int            `json:"int1"`
+	Int2  int            `json:"int2"`
+	Limit int            `json:"limit"`
+	Str1  string         `json:"str1"`
+	Str2  string         `json:"str2"`
+	Rules []RuleResponse `json:"rules,omitempty"`
+}
+
+// RuleResponse is the JSON representation of a rule
+type RuleResponse struct {
+	Divisor int    `json:"divisor"`
+	Word    string `json:"word"`
 }
 
 // DTO mapper to converts a FizzBuzzQuery to its API response format
 func (q FizzBuzzQuery) ToResponse() *FizzBuzzQueryResponse {
-	return &FizzBuzzQueryResponse{
-		Int1:  q.FirstDivisor,
-		Int2:  q.SecondDivisor,
+	rules := q.RuleSet()
+
+	resp := &FizzBuzzQueryResponse{
 		Limit: q.UpperLimit,
-		Str1:  q.FirstString,
-		Str2:  q.SecondString,
+		Rules: make([]RuleResponse, len(rules)),
+	}
+	for i, rule := range rules {
+		resp.Rules[i] = RuleResponse{Divisor: rule.Divisor, Word: rule.Word}
+	}
+
+	if len(rules) == 2 {
+		resp.Int1, resp.Str1 = rules[0].Divisor, rules[0].Word
+		resp.Int2, resp.Str2 = rules[1].Divisor, rules[1].Word
+	}
+
+	return resp
+}
+
+// ToQuery maps the response format back to the query it describes
+func (r FizzBuzzQueryResponse) ToQuery() FizzBuzzQuery {
+	query := FizzBuzzQuery{
+		UpperLimit: r.Limit,
+		Rules:      make([]Rule, len(r.Rules)),
+	}
+	for i, rule := range r.Rules {
+		query.Rules[i] = Rule{Divisor: rule.Divisor, Word: rule.Word}
 	}
+	return query
 }
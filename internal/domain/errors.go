@@ -5,24 +5,29 @@ import (
 	"strings"
 )
 
-// ValidationError represents a domain validation failure with detailed messages
+// ValidationError represents a domain validation failure with one violation
+// per unsatisfied constraint
 type ValidationError struct {
-	Message string
-	Details []string
+	Message    string
+	Violations []Violation
 }
 
 func (e ValidationError) Error() string {
-	if len(e.Details) == 0 {
+	if len(e.Violations) == 0 {
 		return fmt.Sprintf("validation failed: %s", e.Message)
 	}
-	return fmt.Sprintf("validation failed: %s [%s]", e.Message, strings.Join(e.Details, ", "))
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return fmt.Sprintf("validation failed: %s [%s]", e.Message, strings.Join(messages, ", "))
 }
 
-// NewValidationError creates a validation error with details
-func NewValidationError(message string, details ...string) ValidationError {
+// NewValidationError creates a validation error with violations
+func NewValidationError(message string, violations ...Violation) ValidationError {
 	return ValidationError{
-		Message: message,
-		Details: details,
+		Message:    message,
+		Violations: violations,
 	}
 }
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fizzbuzz-service/internal/domain/entity"
+	"iter"
 	"strconv"
 )
 
@@ -16,28 +17,56 @@ func NewFizzBuzzGenerator() *FizzBuzzGenerator {
 // Generate creates the fizzbuzz sequence
 // Precondition: query has been validated
 func (g *FizzBuzzGenerator) Generate(query entity.FizzBuzzQuery) []string {
-	result := make([]string, 0, query.UpperLimit)
+	return g.GenerateRange(query, 1, query.UpperLimit)
+}
+
+// GenerateRange creates the part of the sequence from number from to number
+// to, both included
+// Each number is computed on its own, so the numbers before from cost nothing.
+// Precondition: query has been validated and 1 <= from <= to <= its limit
+func (g *FizzBuzzGenerator) GenerateRange(query entity.FizzBuzzQuery, from, to int) []string {
+	result := make([]string, 0, to-from+1)
 
-	for i := 1; i <= query.UpperLimit; i++ {
-		result = append(result, g.generateSingle(i, query))
+	for _, value := range g.Range(query, from, to) {
+		result = append(result, value)
 	}
 
 	return result
 }
 
+// Sequence lazily yields each number with its output, in order
+// Nothing is buffered, so memory stays constant whatever the limit.
+// Precondition: query has been validated
+func (g *FizzBuzzGenerator) Sequence(query entity.FizzBuzzQuery) iter.Seq2[int, string] {
+	return g.Range(query, 1, query.UpperLimit)
+}
+
+// Range lazily yields the numbers from from to to, both included, with
+// their output
+func (g *FizzBuzzGenerator) Range(query entity.FizzBuzzQuery, from, to int) iter.Seq2[int, string] {
+	rules := query.RuleSet()
+
+	return func(yield func(int, string) bool) {
+		for i := from; i <= to; i++ {
+			if !yield(i, g.generateSingle(i, rules)) {
+				return
+			}
+		}
+	}
+}
+
 // generateSingle determines the output for a single number
-func (g *FizzBuzzGenerator) generateSingle(n int, query entity.FizzBuzzQuery) string {
-	divisibleByFirst := n%query.FirstDivisor == 0
-	divisibleBySecond := n%query.SecondDivisor == 0
-
-	switch {
-	case divisibleByFirst && divisibleBySecond:
-		return query.FirstString + query.SecondString
-	case divisibleByFirst:
-		return query.FirstString
-	case divisibleBySecond:
-		return query.SecondString
-	default:
+// Words of every matching rule are joined in rule order
+func (g *FizzBuzzGenerator) generateSingle(n int, rules []entity.Rule) string {
+	var word string
+	for _, rule := range rules {
+		if n%rule.Divisor == 0 {
+			word += rule.Word
+		}
+	}
+
+	if word == "" {
 		return strconv.Itoa(n)
 	}
+	return word
 }
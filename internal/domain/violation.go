@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codes of violations, stable identifiers clients can localize
+const (
+	CodeRequired          = "required"
+	CodeMustBePositive    = "must_be_positive"
+	CodeExceedsMax        = "exceeds_max"
+	CodeOutOfRange        = "out_of_range"
+	CodeTooMany           = "too_many"
+	CodeMutuallyExclusive = "mutually_exclusive"
+	CodeMustBeInteger     = "must_be_integer"
+	CodeInvalidFormat     = "invalid_format"
+	CodeUnknown           = "unknown"
+	CodeRepeated          = "repeated"
+	CodeUnsupported       = "unsupported"
+	CodeInvalid           = "invalid"
+)
+
+// Violation is a constraint a field does not satisfy
+type Violation struct {
+	// Field is the path of the field, e.g. "limit" or "rules[1].divisor"
+	Field string
+	// Code identifies the constraint (see the Code constants)
+	Code string
+	// Message explains the violation in English
+	Message string
+	// Params are the parameters of the constraint, e.g. "max" for
+	// CodeExceedsMax; nil when it has none
+	Params map[string]any
+}
+
+// Required reports an empty field
+func Required(field string) Violation {
+	return Violation{Field: field, Code: CodeRequired, Message: field + " cannot be empty"}
+}
+
+// MustBePositive reports a number that is zero or negative
+func MustBePositive(field string) Violation {
+	return Violation{Field: field, Code: CodeMustBePositive, Message: field + " must be greater than 0"}
+}
+
+// ExceedsMax reports a number above max
+func ExceedsMax(field string, max int) Violation {
+	return Violation{
+		Field:   field,
+		Code:    CodeExceedsMax,
+		Message: fmt.Sprintf("%s exceeds maximum allowed value of %d", field, max),
+		Params:  map[string]any{"max": max},
+	}
+}
+
+// OutOfRange reports a value outside [min, max]
+func OutOfRange(field string, min, max any) Violation {
+	return Violation{
+		Field:   field,
+		Code:    CodeOutOfRange,
+		Message: fmt.Sprintf("%s must be between %v and %v", field, min, max),
+		Params:  map[string]any{"min": min, "max": max},
+	}
+}
+
+// TooMany reports a list of more than max entries
+func TooMany(field string, max int) Violation {
+	return Violation{
+		Field:   field,
+		Code:    CodeTooMany,
+		Message: fmt.Sprintf("%s cannot contain more than %d entries", field, max),
+		Params:  map[string]any{"max": max},
+	}
+}
+
+// MutuallyExclusive reports a field given together with others it excludes
+func MutuallyExclusive(field string, others ...string) Violation {
+	list := strings.Join(others, ", ")
+	if n := len(others); n > 1 {
+		list = strings.Join(others[:n-1], ", ") + " or " + others[n-1]
+	}
+	return Violation{
+		Field:   field,
+		Code:    CodeMutuallyExclusive,
+		Message: fmt.Sprintf("%s cannot be combined with %s", field, list),
+		Params:  map[string]any{"fields": others},
+	}
+}
+
+// Invalid reports a value that is not acceptable for another reason,
+// explained by message
+func Invalid(field, code, message string) Violation {
+	return Violation{Field: field, Code: code, Message: message}
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hashPrefix tags the algorithm of stored key hashes
+const hashPrefix = "sha256:"
+
+// APIKey is a client key as configured; only its hash is kept
+type APIKey struct {
+	ID string
+	// Hash is "sha256:" followed by the hex SHA-256 of the key
+	Hash   string
+	Scopes []Scope
+}
+
+// HashKey returns the hash of a key, as written in the keys file
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// keysFile is the layout of the API keys file
+type keysFile struct {
+	Keys []struct {
+		ID     string   `yaml:"id"`
+		Hash   string   `yaml:"hash"`
+		Scopes []string `yaml:"scopes"`
+	} `yaml:"keys"`
+}
+
+// LoadAPIKeys reads the API keys file (YAML, or JSON which is valid YAML)
+// The returned error lists every invalid key, one per line.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+
+	var file keysFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse API keys %s: %w", path, err)
+	}
+
+	var problems []error
+	keys := make([]APIKey, 0, len(file.Keys))
+	ids := make(map[string]bool)
+	hashes := make(map[string]bool)
+	for i, k := range file.Keys {
+		name := fmt.Sprintf("key %d", i+1)
+		if k.ID != "" {
+			name += " (" + k.ID + ")"
+		}
+
+		key := APIKey{ID: k.ID, Hash: strings.ToLower(k.Hash)}
+		switch {
+		case k.ID == "":
+			problems = append(problems, fmt.Errorf("%s: id is required", name))
+		case ids[k.ID]:
+			problems = append(problems, fmt.Errorf("%s: duplicate id", name))
+		}
+		ids[k.ID] = true
+
+		if raw, ok := strings.CutPrefix(key.Hash, hashPrefix); !ok || len(raw) != sha256.Size*2 || !isHex(raw) {
+			problems = append(problems, fmt.Errorf("%s: hash must be %s followed by 64 hex digits", name, hashPrefix))
+		} else if hashes[key.Hash] {
+			problems = append(problems, fmt.Errorf("%s: duplicate hash", name))
+		}
+		hashes[key.Hash] = true
+
+		if len(k.Scopes) == 0 {
+			problems = append(problems, fmt.Errorf("%s: at least one scope is required", name))
+		}
+		for _, s := range k.Scopes {
+			scope, err := ParseScope(s)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			key.Scopes = append(key.Scopes, scope)
+		}
+		keys = append(keys, key)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return keys, nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// KeyStore authenticates clients by API key
+// Its keys can be replaced while requests are served.
+type KeyStore struct {
+	keys atomic.Pointer[map[string]*Principal]
+}
+
+// NewKeyStore creates a store accepting keys
+func NewKeyStore(keys []APIKey) *KeyStore {
+	s := &KeyStore{}
+	s.Update(keys)
+	return s
+}
+
+// Update atomically replaces the accepted keys
+func (s *KeyStore) Update(keys []APIKey) {
+	byHash := make(map[string]*Principal, len(keys))
+	for _, k := range keys {
+		byHash[k.Hash] = &Principal{ID: k.ID, Method: MethodAPIKey, Scopes: k.Scopes}
+	}
+	s.keys.Store(&byHash)
+}
+
+// Authenticate resolves a key to its principal
+// Keys are looked up by hash, so the comparison does not leak the key.
+func (s *KeyStore) Authenticate(_ context.Context, key string) (*Principal, error) {
+	if p, ok := (*s.keys.Load())[HashKey(key)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+}
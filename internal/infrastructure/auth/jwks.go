@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Signing algorithms accepted in JWTs
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// Minimum key sizes, below which keys are rejected as too weak
+const (
+	minHMACKeyBytes = 32
+	minRSAKeyBits   = 2048
+)
+
+// JWK is a verification key of a JWKS
+type JWK struct {
+	// ID matches the kid header of tokens; optional in a set of one key
+	ID string
+	// Algorithm is the only algorithm accepted with this key
+	Algorithm string
+	// Key is []byte for HS256, *rsa.PublicKey for RS256, *ecdsa.PublicKey for ES256
+	Key any
+	// fingerprint tells rotated keys apart without revealing them
+	fingerprint string
+}
+
+// KeySet is a parsed JSON Web Key Set (RFC 7517)
+type KeySet struct {
+	keys []JWK
+}
+
+// Keys returns the keys of the set
+func (s *KeySet) Keys() []JWK {
+	return slices.Clone(s.keys)
+}
+
+// Lookup returns the key a token with the kid header is signed with
+// Tokens without kid are accepted when the set has a single key.
+func (s *KeySet) Lookup(kid string) (JWK, bool) {
+	if s == nil {
+		return JWK{}, false
+	}
+	if kid == "" {
+		if len(s.keys) == 1 {
+			return s.keys[0], true
+		}
+		return JWK{}, false
+	}
+	for _, k := range s.keys {
+		if k.ID == kid {
+			return k, true
+		}
+	}
+	return JWK{}, false
+}
+
+// String describes the keys without revealing them, with a fingerprint of
+// their material, e.g. "k1(RS256)#1a2b3c4d,k2(ES256)#5e6f7a8b"
+func (s *KeySet) String() string {
+	if s == nil {
+		return ""
+	}
+	described := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		described[i] = k.ID + "(" + k.Algorithm + ")#" + k.fingerprint
+	}
+	return strings.Join(described, ",")
+}
+
+// jwkJSON holds the members of a JWK used by the supported key types
+type jwkJSON struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// oct
+	K string `json:"k"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// Private members must not be published
+	D string `json:"d"`
+}
+
+// LoadJWKS reads a JSON Web Key Set file
+// The returned error lists every invalid key, one per line.
+func LoadJWKS(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set with oct (HS256), RSA (RS256) and
+// EC P-256 (ES256) keys
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwkJSON `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	if len(doc.Keys) == 0 {
+		return nil, errors.New("JWKS has no keys")
+	}
+
+	var problems []error
+	set := &KeySet{}
+	ids := make(map[string]bool)
+	for i, raw := range doc.Keys {
+		name := fmt.Sprintf("key %d", i+1)
+		if raw.Kid != "" {
+			name += " (" + raw.Kid + ")"
+		}
+
+		switch {
+		case raw.Kid == "" && len(doc.Keys) > 1:
+			problems = append(problems, fmt.Errorf("%s: kid is required in a set of several keys", name))
+		case ids[raw.Kid]:
+			problems = append(problems, fmt.Errorf("%s: duplicate kid", name))
+		}
+		ids[raw.Kid] = true
+
+		if raw.Use != "" && raw.Use != "sig" {
+			problems = append(problems, fmt.Errorf("%s: use %q is not sig", name, raw.Use))
+			continue
+		}
+		key, err := parseJWK(raw)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		set.keys = append(set.keys, key)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return set, nil
+}
+
+func parseJWK(raw jwkJSON) (JWK, error) {
+	key := JWK{ID: raw.Kid}
+	switch raw.Kty {
+	case "oct":
+		key.Algorithm = AlgHS256
+		secret, err := decodeMember("k", raw.K)
+		if err != nil {
+			return key, err
+		}
+		if len(secret) < minHMACKeyBytes {
+			return key, fmt.Errorf("HS256 secret must be at least %d bytes", minHMACKeyBytes)
+		}
+		key.Key = secret
+	case "RSA":
+		key.Algorithm = AlgRS256
+		if raw.D != "" {
+			return key, errors.New("private key found, publish only public keys")
+		}
+		n, err := decodeMember("n", raw.N)
+		if err != nil {
+			return key, err
+		}
+		e, err := decodeMember("e", raw.E)
+		if err != nil {
+			return key, err
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < minRSAKeyBits {
+			return key, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		if pub.E < 3 || pub.E%2 == 0 {
+			return key, errors.New("invalid RSA exponent")
+		}
+		key.Key = pub
+	case "EC":
+		key.Algorithm = AlgES256
+		if raw.D != "" {
+			return key, errors.New("private key found, publish only public keys")
+		}
+		if raw.Crv != "P-256" {
+			return key, fmt.Errorf("curve %q is not supported, use P-256", raw.Crv)
+		}
+		x, err := decodeMember("x", raw.X)
+		if err != nil {
+			return key, err
+		}
+		y, err := decodeMember("y", raw.Y)
+		if err != nil {
+			return key, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return key, errors.New("P-256 coordinates must be 32 bytes")
+		}
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), slices.Concat([]byte{4}, x, y))
+		if err != nil {
+			return key, fmt.Errorf("invalid P-256 point: %w", err)
+		}
+		key.Key = pub
+	default:
+		return key, fmt.Errorf("key type %q is not supported (oct, RSA or EC)", raw.Kty)
+	}
+
+	if raw.Alg != "" && raw.Alg != key.Algorithm {
+		return key, fmt.Errorf("alg %q does not match key type %s (%s)", raw.Alg, raw.Kty, key.Algorithm)
+	}
+	sum := sha256.Sum256([]byte(raw.Kty + "|" + raw.K + "|" + raw.N + "|" + raw.E + "|" + raw.X + "|" + raw.Y))
+	key.fingerprint = hex.EncodeToString(sum[:4])
+	return key, nil
+}
+
+// decodeMember decodes a base64url member of a JWK
+func decodeMember(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not base64url: %w", name, err)
+	}
+	return b, nil
+}
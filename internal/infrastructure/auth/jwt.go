@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/clock"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MethodJWT authenticates clients with a JWT issued by a trusted gateway
+const MethodJWT = "jwt"
+
+// JWTConfig holds the claims every accepted token must carry
+type JWTConfig struct {
+	// Issuer must equal the iss claim
+	Issuer string
+	// Audience must be one of the aud claim
+	Audience string
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// jwtState is swapped as a whole, so a token is never checked against the
+// keys of one configuration and the claims of another
+type jwtState struct {
+	keys *KeySet
+	cfg  JWTConfig
+}
+
+// JWTAuthenticator authenticates clients by JWT, verified against a JWKS
+// Its keys and expected claims can be replaced while requests are served.
+type JWTAuthenticator struct {
+	state atomic.Pointer[jwtState]
+	clock clock.Clock
+}
+
+// JWTOption customizes the JWT authenticator
+type JWTOption func(*JWTAuthenticator)
+
+// WithJWTClock sets the time source exp and nbf are checked against
+// (defaults to the wall clock)
+func WithJWTClock(c clock.Clock) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.clock = c
+	}
+}
+
+// NewJWTAuthenticator creates an authenticator accepting tokens signed by keys
+func NewJWTAuthenticator(keys *KeySet, cfg JWTConfig, opts ...JWTOption) *JWTAuthenticator {
+	a := &JWTAuthenticator{clock: clock.System{}}
+	a.Update(keys, cfg)
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Update atomically replaces the keys and the expected claims
+func (a *JWTAuthenticator) Update(keys *KeySet, cfg JWTConfig) {
+	a.state.Store(&jwtState{keys: keys, cfg: cfg})
+}
+
+// claims are the registered claims plus the scopes granted to the client,
+// as a space-separated "scope" (RFC 8693) or a "scp" string or list
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope"`
+	Scp   any    `json:"scp"`
+}
+
+// Errors of keys not found in the JWKS
+var (
+	errUnknownKey   = errors.New("unknown signing key")
+	errKeyAlgorithm = errors.New("signing algorithm does not match the key")
+)
+
+// Authenticate verifies a token and resolves it to its principal
+// The token must be signed with a key of the set, using the key's algorithm,
+// be valid now (exp, nbf) and be issued by the configured issuer for the
+// configured audience. Its sub claim is the principal ID.
+func (a *JWTAuthenticator) Authenticate(_ context.Context, token string) (*Principal, error) {
+	state := a.state.Load()
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgES256}),
+		jwt.WithIssuer(state.cfg.Issuer),
+		jwt.WithAudience(state.cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(state.cfg.Leeway),
+		jwt.WithTimeFunc(a.clock.Now),
+	)
+
+	var c claims
+	_, err := parser.ParseWithClaims(token, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := state.keys.Lookup(kid)
+		if !ok {
+			return nil, errUnknownKey
+		}
+		if key.Algorithm != t.Method.Alg() {
+			return nil, errKeyAlgorithm
+		}
+		return key.Key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredentials, describeTokenError(err))
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	return &Principal{ID: c.Subject, Method: MethodJWT, Scopes: c.scopes()}, nil
+}
+
+// scopes returns the known scopes granted by the token
+// Scopes meant for other services are ignored.
+func (c *claims) scopes() []Scope {
+	var names []string
+	names = append(names, strings.Fields(c.Scope)...)
+	switch scp := c.Scp.(type) {
+	case string:
+		names = append(names, strings.Fields(scp)...)
+	case []any:
+		for _, s := range scp {
+			if name, ok := s.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var scopes []Scope
+	for _, name := range names {
+		if scope, err := ParseScope(name); err == nil && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// describeTokenError explains why a token was rejected, in terms safe to
+// return to the client
+func describeTokenError(err error) string {
+	switch {
+	case errors.Is(err, errUnknownKey):
+		return errUnknownKey.Error()
+	case errors.Is(err, errKeyAlgorithm):
+		return errKeyAlgorithm.Error()
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed token"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid signature"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "token is expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "token is missing a required claim (exp, iss or aud)"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "token issuer is not accepted"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "token audience is not accepted"
+	default:
+		return "invalid token"
+	}
+}
+
+// Dispatcher authenticates JWTs with JWT and other credentials with APIKeys
+// Either may be nil when that method is disabled.
+type Dispatcher struct {
+	APIKeys Authenticator
+	JWT     Authenticator
+}
+
+// Authenticate passes credential to the authenticator of its kind
+func (d Dispatcher) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if d.JWT != nil && (d.APIKeys == nil || IsJWT(credential)) {
+		return d.JWT.Authenticate(ctx, credential)
+	}
+	if d.APIKeys != nil {
+		return d.APIKeys.Authenticate(ctx, credential)
+	}
+	return nil, fmt.Errorf("%w: authentication is disabled", ErrInvalidCredentials)
+}
+
+// IsJWT reports whether credential has the shape of a compact JWT, three
+// base64url segments separated by dots
+func IsJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
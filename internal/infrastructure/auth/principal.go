@@ -0,0 +1,93 @@
+// Package auth authenticates API clients and describes what they may do.
+//
+// Credentials resolve to a Principal carrying scopes; HTTP middleware puts
+// it in the request context, where logging, rate limiting and statistics
+// find who is calling.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Scope grants access to a group of routes
+type Scope string
+
+// Scopes
+const (
+	// ScopeGenerate allows generating sequences (/fizzbuzz, /fizzbuzz/stream)
+	ScopeGenerate Scope = "fizzbuzz:generate"
+	// ScopeStatsRead allows reading statistics
+	ScopeStatsRead Scope = "stats:read"
+	// ScopeAdmin allows operating the service (e.g. reloading its configuration)
+	ScopeAdmin Scope = "admin"
+)
+
+// knownScopes lists every scope, to reject typos in configuration
+var knownScopes = []Scope{ScopeGenerate, ScopeStatsRead, ScopeAdmin}
+
+// ParseScope validates a scope name
+func ParseScope(s string) (Scope, error) {
+	if !slices.Contains(knownScopes, Scope(s)) {
+		return "", fmt.Errorf("unknown scope %q", s)
+	}
+	return Scope(s), nil
+}
+
+// Authentication methods
+const (
+	MethodAPIKey = "api_key"
+)
+
+// Principal is an authenticated client
+type Principal struct {
+	// ID identifies the client, e.g. the API key id
+	ID string
+	// Method is how the client authenticated (e.g. MethodAPIKey)
+	Method string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// ErrInvalidCredentials is returned for unknown, malformed or expired credentials
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator resolves a credential sent by a client to its principal
+type Authenticator interface {
+	// Authenticate returns an error wrapping ErrInvalidCredentials when the
+	// credential is not accepted
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+type errorKey struct{}
+
+// NewContext returns a context carrying p
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the request, if authenticated
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// WithError returns a context recording why the credentials of the request
+// were rejected, so routes requiring a scope can explain it
+func WithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// ErrorFromContext returns the authentication error of the request, if any
+func ErrorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
@@ -0,0 +1,17 @@
+// Package clock abstracts time so time-dependent components can be tested
+package clock
+
+import "time"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the wall clock
+type System struct{}
+
+// Now returns time.Now()
+func (System) Now() time.Time {
+	return time.Now()
+}
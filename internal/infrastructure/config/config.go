@@ -1,37 +1,345 @@
+// Package config loads the service configuration.
+//
+// Every setting can come from, by increasing precedence: its default, a
+// YAML or JSON config file, an environment variable and a command-line flag.
+// All sources are validated together, so a bad configuration is reported
+// at once with every invalid setting instead of one at a time.
 package config
 
 import (
+	"flag"
+	"fmt"
 	"os"
-	"strconv"
+	"strings"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/infrastructure/auth"
+)
+
+// Statistics backends
+const (
+	StatsBackendMemory = "memory"
+	StatsBackendFile   = "file"
+	StatsBackendSQLite = "sqlite"
 )
 
+// ConfigFileEnv names the config file when the -config flag is not given
+const ConfigFileEnv = "CONFIG_FILE"
+
 // Config holds all application configuration
 type Config struct {
 	Port     string
 	LogLevel string
 	MaxLimit int
+	// StreamMaxLimit caps the limit of streamed sequences, which are
+	// generated with constant memory and can go much higher than MaxLimit
+	StreamMaxLimit int
+
+	// ResultCacheMaxBytes bounds the cache of generated sequences; 0 disables it
+	ResultCacheMaxBytes int
+	// ResultCachePrewarm is the number of most frequent requests cached at startup
+	ResultCachePrewarm int
+
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound
+	// each connection (see http.Server)
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// StopTimeout bounds graceful shutdown
+	StopTimeout time.Duration
+	// RequestTimeout bounds each non-streaming request
+	RequestTimeout time.Duration
+	// MaxBodyBytes bounds the size of request bodies
+	MaxBodyBytes int
+
+	// StatsBackend selects where statistics are kept: "memory", "file" or "sqlite"
+	StatsBackend string
+	// StatsDir holds the files of the "file" and "sqlite" backends
+	StatsDir string
+	// StatsFlushInterval and StatsSnapshotInterval tune the "file" backend
+	StatsFlushInterval    time.Duration
+	StatsSnapshotInterval time.Duration
+
+	// StatsQueueSize, StatsWorkers, StatsOverflow and StatsTimeout bound the
+	// background recording of statistics (see application.DispatcherConfig)
+	StatsQueueSize int
+	StatsWorkers   int
+	StatsOverflow  application.OverflowPolicy
+	StatsTimeout   time.Duration
+
+	// StatsShards splits the "memory" backend into independently locked shards
+	StatsShards int
+	// StatsMaxClients bounds the clients whose hits the backends keep, per
+	// shard of the "memory" backend
+	StatsMaxClients int
+	// StatsBatchInterval, when positive, aggregates hits for that long before
+	// writing them to the backend; StatsBatchSize flushes earlier
+	StatsBatchInterval time.Duration
+	StatsBatchSize     int
+
+	// MetricsPath is where Prometheus metrics are served
+	MetricsPath string
+	// AdminPort, when set, serves metrics on that port instead of Port
+	AdminPort string
+
+	// DrainDelay is how long the server keeps serving, reported unready,
+	// once shutdown starts
+	DrainDelay time.Duration
+	// ReadyQueueMaxFill is the statistics queue fill ratio beyond which the
+	// service reports itself unready
+	ReadyQueueMaxFill float64
+	// ReadyCheckTimeout bounds each readiness check
+	ReadyCheckTimeout time.Duration
+
+	// TracingExporter selects where spans go: "none", "stdout", "file" or "otlp"
+	TracingExporter string
+	// TracingFile is the file of the "file" exporter
+	TracingFile string
+	// TracingEndpoint is the OTLP/HTTP traces URL of the "otlp" exporter
+	TracingEndpoint string
+	// TracingSampleRatio is the fraction of new traces recorded
+	TracingSampleRatio float64
+
+	// CORS is the cross-origin policy of the API
+	CORS CORS
+
+	// RateLimits throttle each client, per group of routes
+	RateLimits RateLimits
+
+	// APIKeysFile, when set, enables authentication with the API keys it lists
+	APIKeysFile string
+	// APIKeys are read from APIKeysFile
+	APIKeys []auth.APIKey
+
+	// JWKSFile, when set, enables authentication with JWTs signed by the keys
+	// of this JSON Web Key Set
+	JWKSFile string
+	// JWKS is read from JWKSFile
+	JWKS *auth.KeySet
+	// JWTIssuer and JWTAudience must match the iss and aud claims of tokens
+	JWTIssuer   string
+	JWTAudience string
+	// JWTLeeway tolerates clock skew when checking exp and nbf
+	JWTLeeway time.Duration
+}
+
+// CORS configures cross-origin requests
+type CORS struct {
+	// AllowedOrigins may contain "*" to allow any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers cache preflight responses
+	MaxAge time.Duration
+}
+
+// RateLimits configures the token buckets of each group of routes
+type RateLimits struct {
+	// Generate limits GET and POST /fizzbuzz
+	Generate RateLimit
+	// Stream limits POST /fizzbuzz/stream
+	Stream RateLimit
+	// Statistics limits /statistics and /statistics/top
+	Statistics RateLimit
+	// IdleTTL is how long the bucket of an inactive client is kept
+	IdleTTL time.Duration
+}
+
+// RateLimit is a token bucket per client
+type RateLimit struct {
+	// Rate is the number of tokens added per second; 0 disables limiting
+	Rate  float64
+	Burst int
+	// CostUnit, when positive, charges one extra token per CostUnit of the
+	// requested limit
+	CostUnit int
 }
 
-// Load reads configuration from environment
-func Load() *Config {
+// Default returns the configuration used when nothing is set
+func Default() *Config {
 	return &Config{
-		Port:     getEnv("PORT", "8080"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
-		MaxLimit: getEnvAsInt("MAX_LIMIT", 10000),
+		Port:           "8080",
+		LogLevel:       "info",
+		MaxLimit:       10000,
+		StreamMaxLimit: 10000000,
+
+		ResultCacheMaxBytes: 32 << 20,
+		ResultCachePrewarm:  10,
+
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		StopTimeout:       10 * time.Second,
+		RequestTimeout:    30 * time.Second,
+		MaxBodyBytes:      1 << 20,
+
+		StatsBackend:          StatsBackendMemory,
+		StatsDir:              "data",
+		StatsFlushInterval:    time.Second,
+		StatsSnapshotInterval: 5 * time.Minute,
+
+		StatsQueueSize: 1024,
+		StatsWorkers:   4,
+		StatsOverflow:  application.OverflowDrop,
+		StatsTimeout:   5 * time.Second,
+
+		StatsShards:        1,
+		StatsMaxClients:    10000,
+		StatsBatchInterval: 0,
+		StatsBatchSize:     1024,
+
+		MetricsPath: "/metrics",
+		AdminPort:   "",
+
+		DrainDelay:        5 * time.Second,
+		ReadyQueueMaxFill: 0.9,
+		ReadyCheckTimeout: 2 * time.Second,
+
+		TracingExporter:    "none",
+		TracingFile:        "traces.jsonl",
+		TracingEndpoint:    "http://localhost:4318/v1/traces",
+		TracingSampleRatio: 1,
+
+		CORS: CORS{
+			AllowedOrigins:   []string{"*"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "X-Requested-With"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		},
+
+		RateLimits: RateLimits{
+			Generate:   RateLimit{Rate: 10, Burst: 20, CostUnit: 1000},
+			Stream:     RateLimit{Rate: 1, Burst: 10, CostUnit: 1000000},
+			Statistics: RateLimit{Rate: 20, Burst: 40},
+			IdleTTL:    10 * time.Minute,
+		},
+
+		JWTLeeway: 30 * time.Second,
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Error lists every invalid setting found while loading
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load reads the configuration from the command-line args (without the
+// program name), the environment and the config file they name
+// It returns flag.ErrHelp when help was requested, and an *Error listing
+// every invalid setting otherwise.
+func Load(args []string) (*Config, error) {
+	settings := allSettings()
+
+	fs := flag.NewFlagSet("fizzbuzz-service", flag.ContinueOnError)
+	configFile := fs.String("config", "", "YAML or JSON config file (env "+ConfigFileEnv+")")
+	flagValues := make(map[string]*string, len(settings))
+	defaults := Default()
+	for _, s := range settings {
+		flagValues[s.key] = fs.String(s.key, "", fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.format(defaults)))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, &Error{Problems: []string{fmt.Sprintf("unexpected arguments %q", fs.Args())}}
+	}
+
+	cfg := Default()
+	var problems []string
+	apply := func(s setting, source, value string) {
+		if err := s.parse(cfg, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", s.key, source, err))
+		}
+	}
+
+	// Lowest precedence first, so later sources override earlier ones
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, &Error{Problems: []string{err.Error()}}
+		}
+		known := make(map[string]bool, len(settings))
+		for _, s := range settings {
+			known[s.key] = true
+			if value, ok := values[s.key]; ok {
+				apply(s, "file "+path, value)
+			}
+		}
+		for _, key := range sortedKeys(values) {
+			if !known[key] {
+				problems = append(problems, fmt.Sprintf("%s (file %s): unknown setting", key, path))
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			apply(s, "env "+s.env, value)
+		}
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, s := range settings {
+		if set[s.key] {
+			apply(s, "flag -"+s.key, *flagValues[s.key])
+		}
+	}
+
+	// Files named by settings are read once every source is applied
+	if cfg.APIKeysFile != "" {
+		keys, err := auth.LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			for line := range strings.SplitSeq(err.Error(), "\n") {
+				problems = append(problems, "auth.api_keys_file: "+line)
+			}
+		}
+		cfg.APIKeys = keys
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			for line := range strings.SplitSeq(err.Error(), "\n") {
+				problems = append(problems, "auth.jwks_file: "+line)
+			}
+		}
+		cfg.JWKS = keys
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
 	}
-	return defaultValue
+	return cfg, nil
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+// validate checks rules spanning several settings
+func (c *Config) validate() []string {
+	var problems []string
+	if c.AdminPort != "" && c.AdminPort == c.Port {
+		problems = append(problems, "metrics.admin_port: must differ from server.port")
+	}
+	if c.TracingExporter == "file" && c.TracingFile == "" {
+		problems = append(problems, "tracing.file: required by the file exporter")
+	}
+	if c.JWKSFile != "" && c.JWTIssuer == "" {
+		problems = append(problems, "auth.jwt_issuer: required to accept JWTs")
+	}
+	if c.JWKSFile != "" && c.JWTAudience == "" {
+		problems = append(problems, "auth.jwt_audience: required to accept JWTs")
 	}
-	return defaultValue
+	return problems
 }
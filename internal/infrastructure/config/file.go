@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads a YAML or JSON config file, chosen by its extension, into
+// dotted keys (e.g. server.port) mapped to their raw values
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .json)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+	return values, nil
+}
+
+// flatten writes the leaves of a nested document into values
+// Lists become comma-separated values, as in environment variables.
+func flatten(prefix string, doc map[string]any, values map[string]string) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, values)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = scalar(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = scalar(v)
+		}
+	}
+}
+
+func scalar(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(values map[string]string) []string {
+	return slices.Sorted(maps.Keys(values))
+}
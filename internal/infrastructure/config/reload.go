@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+)
+
+// Change is a setting whose value differs after a reload
+type Change struct {
+	// Setting is the file key of the setting (e.g. fizzbuzz.max_limit)
+	Setting string
+	Old     string
+	New     string
+	// Applied is false for settings that only take effect on restart
+	Applied bool
+}
+
+// Reloader re-reads the configuration from the sources given to Load and
+// applies the settings that can change while the service runs
+type Reloader struct {
+	args    []string
+	logger  *slog.Logger
+	mu      sync.Mutex // serializes reloads
+	current atomic.Pointer[Config]
+	hooks   []func(*Config)
+}
+
+// NewReloader creates a reloader of current, which was loaded from args
+func NewReloader(args []string, current *Config, logger *slog.Logger) *Reloader {
+	r := &Reloader{args: args, logger: logger}
+	r.current.Store(current)
+	return r
+}
+
+// OnReload registers a hook receiving the new configuration after each
+// reload that changes a reloadable setting
+// Hooks run in registration order and must be registered before any reload.
+func (r *Reloader) OnReload(hook func(*Config)) {
+	r.hooks = append(r.hooks, hook)
+}
+
+// Current returns the configuration in effect
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Reload loads the configuration again and applies its reloadable settings
+// An invalid configuration is rejected as a whole with an *Error, keeping
+// the current one. Changes of settings that need a restart are reported,
+// not applied.
+func (r *Reloader) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.args)
+	if err != nil {
+		r.logger.Warn("configuration reload rejected", "error", err)
+		return nil, err
+	}
+
+	// Start from the configuration in effect, so settings needing a
+	// restart keep their value
+	current := r.current.Load()
+	updated := *current
+	var changes []Change
+	applied := 0
+	for _, s := range allSettings() {
+		was, now := s.format(current), s.format(next)
+		if was == now {
+			continue
+		}
+		changes = append(changes, Change{Setting: s.key, Old: was, New: now, Applied: s.reloadable})
+		if !s.reloadable {
+			r.logger.Warn("configuration change requires a restart", "setting", s.key, "old", was, "new", now)
+			continue
+		}
+		if err := s.parse(&updated, now); err != nil {
+			return nil, fmt.Errorf("apply %s: %w", s.key, err)
+		}
+		applied++
+		r.logger.Info("configuration changed", "setting", s.key, "old", was, "new", now)
+	}
+
+	// Keys are read again from the same file; a new file needs a restart
+	if was, now := formatKeys(current.APIKeys), formatKeys(next.APIKeys); was != now && current.APIKeysFile == next.APIKeysFile {
+		changes = append(changes, Change{Setting: "auth.api_keys", Old: was, New: now, Applied: true})
+		updated.APIKeys = next.APIKeys
+		applied++
+		r.logger.Info("configuration changed", "setting", "auth.api_keys", "old", was, "new", now)
+	}
+	if was, now := current.JWKS.String(), next.JWKS.String(); was != now && current.JWKSFile == next.JWKSFile {
+		changes = append(changes, Change{Setting: "auth.jwks", Old: was, New: now, Applied: true})
+		updated.JWKS = next.JWKS
+		applied++
+		r.logger.Info("configuration changed", "setting", "auth.jwks", "old", was, "new", now)
+	}
+
+	if applied == 0 {
+		r.logger.Info("configuration reloaded", "applied", 0)
+		return changes, nil
+	}
+	r.current.Store(&updated)
+	for _, hook := range r.hooks {
+		hook(&updated)
+	}
+	r.logger.Info("configuration reloaded", "applied", applied)
+	return changes, nil
+}
+
+// formatKeys describes API keys with a fingerprint of their hash, enough to
+// tell a rotated key without revealing it
+func formatKeys(keys []auth.APIKey) string {
+	described := make([]string, len(keys))
+	for i, k := range keys {
+		scopes := make([]string, len(k.Scopes))
+		for j, s := range k.Scopes {
+			scopes[j] = string(s)
+		}
+		fingerprint := strings.TrimPrefix(k.Hash, "sha256:")[:8]
+		described[i] = k.ID + "#" + fingerprint + "(" + strings.Join(scopes, " ") + ")"
+	}
+	return strings.Join(described, ",")
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+)
+
+// setting is one configurable value, the same whatever its source
+type setting struct {
+	// key is the dotted path in the config file and the flag name
+	key string
+	// env is the environment variable
+	env   string
+	usage string
+	// parse validates a value and stores it in the config
+	parse func(c *Config, value string) error
+	// format renders the current value, as it would be written
+	format func(c *Config) string
+	// reloadable settings are applied by Reloader without a restart
+	reloadable bool
+}
+
+// reloadable marks s as applied on reload
+func reloadable(s setting) setting {
+	s.reloadable = true
+	return s
+}
+
+// allSettings lists every setting, in documentation order
+func allSettings() []setting {
+	return []setting{
+		portSetting("server.port", "PORT", "HTTP server port", false, func(c *Config) *string { return &c.Port }),
+		durationSetting("server.read_timeout", "SERVER_READ_TIMEOUT", "maximum time to read a request", 1, func(c *Config) *time.Duration { return &c.ReadTimeout }),
+		durationSetting("server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT", "maximum time to read request headers", 1, func(c *Config) *time.Duration { return &c.ReadHeaderTimeout }),
+		durationSetting("server.write_timeout", "SERVER_WRITE_TIMEOUT", "maximum time to write a response", 1, func(c *Config) *time.Duration { return &c.WriteTimeout }),
+		durationSetting("server.idle_timeout", "SERVER_IDLE_TIMEOUT", "keep-alive connection timeout", 1, func(c *Config) *time.Duration { return &c.IdleTimeout }),
+		durationSetting("server.request_timeout", "SERVER_REQUEST_TIMEOUT", "maximum duration of a non-streaming request", 1, func(c *Config) *time.Duration { return &c.RequestTimeout }),
+		intSetting("server.max_body_bytes", "MAX_BODY_BYTES", "maximum size of request bodies", 1, func(c *Config) *int { return &c.MaxBodyBytes }),
+		durationSetting("server.stop_timeout", "SERVER_STOP_TIMEOUT", "graceful shutdown grace period", 1, func(c *Config) *time.Duration { return &c.StopTimeout }),
+		durationSetting("server.drain_delay", "SHUTDOWN_DRAIN_DELAY", "serving time while reported unready, before shutdown", 0, func(c *Config) *time.Duration { return &c.DrainDelay }),
+
+		reloadable(enumSetting("log.level", "LOG_LEVEL", "log level", []string{"debug", "info", "warn", "error"}, func(c *Config) *string { return &c.LogLevel })),
+
+		reloadable(intSetting("fizzbuzz.max_limit", "MAX_LIMIT", "maximum limit of generated sequences", 1, func(c *Config) *int { return &c.MaxLimit })),
+		reloadable(intSetting("fizzbuzz.stream_max_limit", "STREAM_MAX_LIMIT", "maximum limit of streamed sequences", 1, func(c *Config) *int { return &c.StreamMaxLimit })),
+
+		intSetting("cache.max_bytes", "RESULT_CACHE_MAX_BYTES", "size of the cache of generated sequences (0 disables)", 0, func(c *Config) *int { return &c.ResultCacheMaxBytes }),
+		intSetting("cache.prewarm", "RESULT_CACHE_PREWARM", "most frequent requests cached at startup", 0, func(c *Config) *int { return &c.ResultCachePrewarm }),
+
+		enumSetting("stats.backend", "STATS_BACKEND", "statistics storage", []string{StatsBackendMemory, StatsBackendFile, StatsBackendSQLite}, func(c *Config) *string { return &c.StatsBackend }),
+		stringSetting("stats.dir", "STATS_DIR", "directory of the file and sqlite backends", func(c *Config) *string { return &c.StatsDir }),
+		durationSetting("stats.flush_interval", "STATS_FLUSH_INTERVAL", "how often the file backend syncs hits", 1, func(c *Config) *time.Duration { return &c.StatsFlushInterval }),
+		durationSetting("stats.snapshot_interval", "STATS_SNAPSHOT_INTERVAL", "how often the file backend compacts its log", 1, func(c *Config) *time.Duration { return &c.StatsSnapshotInterval }),
+		intSetting("stats.queue_size", "STATS_QUEUE_SIZE", "hits waiting to be recorded", 1, func(c *Config) *int { return &c.StatsQueueSize }),
+		intSetting("stats.workers", "STATS_WORKERS", "concurrent statistics updates", 1, func(c *Config) *int { return &c.StatsWorkers }),
+		overflowSetting("stats.overflow", "STATS_OVERFLOW", "full queue policy", func(c *Config) *application.OverflowPolicy { return &c.StatsOverflow }),
+		durationSetting("stats.timeout", "STATS_TIMEOUT", "maximum duration of one statistics update", 1, func(c *Config) *time.Duration { return &c.StatsTimeout }),
+		intSetting("stats.shards", "STATS_SHARDS", "shards of the memory backend", 1, func(c *Config) *int { return &c.StatsShards }),
+		intSetting("stats.max_clients", "STATS_MAX_CLIENTS", "clients whose hits are kept", 1, func(c *Config) *int { return &c.StatsMaxClients }),
+		durationSetting("stats.batch_interval", "STATS_BATCH_INTERVAL", "aggregate hits for this long before writing them (0 disables)", 0, func(c *Config) *time.Duration { return &c.StatsBatchInterval }),
+		intSetting("stats.batch_size", "STATS_BATCH_SIZE", "hits per batch shard before an early flush", 1, func(c *Config) *int { return &c.StatsBatchSize }),
+
+		pathSetting("metrics.path", "METRICS_PATH", "path of the metrics endpoint", func(c *Config) *string { return &c.MetricsPath }),
+		portSetting("metrics.admin_port", "ADMIN_PORT", "separate port of the metrics endpoint", true, func(c *Config) *string { return &c.AdminPort }),
+
+		ratioSetting("health.queue_max_fill", "READY_QUEUE_MAX_FILL", "statistics queue fill ratio beyond which the service is unready", func(c *Config) *float64 { return &c.ReadyQueueMaxFill }),
+		durationSetting("health.check_timeout", "READY_CHECK_TIMEOUT", "maximum duration of each readiness check", 1, func(c *Config) *time.Duration { return &c.ReadyCheckTimeout }),
+
+		enumSetting("tracing.exporter", "TRACING_EXPORTER", "span exporter", []string{"none", "stdout", "file", "otlp"}, func(c *Config) *string { return &c.TracingExporter }),
+		stringSetting("tracing.file", "TRACING_FILE", "file of the file exporter", func(c *Config) *string { return &c.TracingFile }),
+		urlSetting("tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT", "OTLP/HTTP traces URL", func(c *Config) *string { return &c.TracingEndpoint }),
+		ratioSetting("tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "fraction of new traces recorded", func(c *Config) *float64 { return &c.TracingSampleRatio }),
+
+		reloadable(listSetting("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "origins allowed to call the API, * for any", func(c *Config) *[]string { return &c.CORS.AllowedOrigins })),
+		reloadable(listSetting("cors.allowed_methods", "CORS_ALLOWED_METHODS", "methods allowed in cross-origin requests", func(c *Config) *[]string { return &c.CORS.AllowedMethods })),
+		reloadable(listSetting("cors.allowed_headers", "CORS_ALLOWED_HEADERS", "headers allowed in cross-origin requests", func(c *Config) *[]string { return &c.CORS.AllowedHeaders })),
+		reloadable(boolSetting("cors.allow_credentials", "CORS_ALLOW_CREDENTIALS", "allow cookies and authorization headers", func(c *Config) *bool { return &c.CORS.AllowCredentials })),
+		reloadable(durationSetting("cors.max_age", "CORS_MAX_AGE", "how long browsers cache preflight responses", 0, func(c *Config) *time.Duration { return &c.CORS.MaxAge })),
+
+		reloadable(rateSetting("ratelimit.generate.rate", "RATE_LIMIT_GENERATE_RATE", "tokens per second of GET and POST /fizzbuzz (0 disables)", func(c *Config) *float64 { return &c.RateLimits.Generate.Rate })),
+		reloadable(intSetting("ratelimit.generate.burst", "RATE_LIMIT_GENERATE_BURST", "token bucket capacity of GET and POST /fizzbuzz", 1, func(c *Config) *int { return &c.RateLimits.Generate.Burst })),
+		reloadable(intSetting("ratelimit.generate.cost_unit", "RATE_LIMIT_GENERATE_COST_UNIT", "one extra token per this many numbers generated (0 for a flat cost)", 0, func(c *Config) *int { return &c.RateLimits.Generate.CostUnit })),
+		reloadable(rateSetting("ratelimit.stream.rate", "RATE_LIMIT_STREAM_RATE", "tokens per second of POST /fizzbuzz/stream (0 disables)", func(c *Config) *float64 { return &c.RateLimits.Stream.Rate })),
+		reloadable(intSetting("ratelimit.stream.burst", "RATE_LIMIT_STREAM_BURST", "token bucket capacity of POST /fizzbuzz/stream", 1, func(c *Config) *int { return &c.RateLimits.Stream.Burst })),
+		reloadable(intSetting("ratelimit.stream.cost_unit", "RATE_LIMIT_STREAM_COST_UNIT", "one extra token per this many numbers streamed (0 for a flat cost)", 0, func(c *Config) *int { return &c.RateLimits.Stream.CostUnit })),
+		reloadable(rateSetting("ratelimit.statistics.rate", "RATE_LIMIT_STATISTICS_RATE", "tokens per second of the statistics endpoints (0 disables)", func(c *Config) *float64 { return &c.RateLimits.Statistics.Rate })),
+		reloadable(intSetting("ratelimit.statistics.burst", "RATE_LIMIT_STATISTICS_BURST", "token bucket capacity of the statistics endpoints", 1, func(c *Config) *int { return &c.RateLimits.Statistics.Burst })),
+		durationSetting("ratelimit.idle_ttl", "RATE_LIMIT_IDLE_TTL", "how long the bucket of an inactive client is kept", 1, func(c *Config) *time.Duration { return &c.RateLimits.IdleTTL }),
+
+		fileSetting("auth.api_keys_file", "AUTH_API_KEYS_FILE", "YAML or JSON file of API keys; enables authentication", func(c *Config) *string { return &c.APIKeysFile }),
+		fileSetting("auth.jwks_file", "AUTH_JWKS_FILE", "JSON Web Key Set verifying JWTs; enables authentication", func(c *Config) *string { return &c.JWKSFile }),
+		reloadable(stringSetting("auth.jwt_issuer", "AUTH_JWT_ISSUER", "iss claim required in JWTs", func(c *Config) *string { return &c.JWTIssuer })),
+		reloadable(stringSetting("auth.jwt_audience", "AUTH_JWT_AUDIENCE", "aud claim required in JWTs", func(c *Config) *string { return &c.JWTAudience })),
+		reloadable(durationSetting("auth.jwt_leeway", "AUTH_JWT_LEEWAY", "clock skew tolerated when checking exp and nbf", 0, func(c *Config) *time.Duration { return &c.JWTLeeway })),
+	}
+}
+
+// newSetting binds a parser and a formatter to a field of the config
+func newSetting[T any](key, env, usage string, field func(*Config) *T, parse func(string) (T, error), format func(T) string) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		usage: usage,
+		parse: func(c *Config, value string) error {
+			v, err := parse(strings.TrimSpace(value))
+			if err != nil {
+				return err
+			}
+			*field(c) = v
+			return nil
+		},
+		format: func(c *Config) string { return format(*field(c)) },
+	}
+}
+
+func intSetting(key, env, usage string, min int, field func(*Config) *int) setting {
+	return newSetting(key, env, usage, field, func(s string) (int, error) {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an integer", s)
+		}
+		if v < min {
+			return 0, fmt.Errorf("must be at least %d, got %d", min, v)
+		}
+		return v, nil
+	}, strconv.Itoa)
+}
+
+// durationSetting accepts Go durations (e.g. 1m30s) of at least min
+func durationSetting(key, env, usage string, min time.Duration, field func(*Config) *time.Duration) setting {
+	return newSetting(key, env, usage, field, func(s string) (time.Duration, error) {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration (e.g. 500ms, 10s, 1m)", s)
+		}
+		if v < min {
+			if min == 1 {
+				return 0, fmt.Errorf("must be positive, got %s", v)
+			}
+			return 0, fmt.Errorf("must be at least %s, got %s", min, v)
+		}
+		return v, nil
+	}, time.Duration.String)
+}
+
+// ratioSetting accepts a number between 0 and 1
+func ratioSetting(key, env, usage string, field func(*Config) *float64) setting {
+	return newSetting(key, env, usage, field, func(s string) (float64, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+		if v < 0 || v > 1 {
+			return 0, fmt.Errorf("must be between 0 and 1, got %g", v)
+		}
+		return v, nil
+	}, func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) })
+}
+
+// rateSetting accepts a non-negative number of tokens per second
+func rateSetting(key, env, usage string, field func(*Config) *float64) setting {
+	return newSetting(key, env, usage, field, func(s string) (float64, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+		if v < 0 {
+			return 0, fmt.Errorf("must not be negative, got %g", v)
+		}
+		return v, nil
+	}, func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) })
+}
+
+func boolSetting(key, env, usage string, field func(*Config) *bool) setting {
+	return newSetting(key, env, usage, field, func(s string) (bool, error) {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("%q is not a boolean", s)
+		}
+		return v, nil
+	}, strconv.FormatBool)
+}
+
+func stringSetting(key, env, usage string, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) {
+		if s == "" {
+			return "", fmt.Errorf("must not be empty")
+		}
+		return s, nil
+	}, identity)
+}
+
+// fileSetting accepts a file path, or an empty value when there is no file
+func fileSetting(key, env, usage string, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) { return s, nil }, identity)
+}
+
+func enumSetting(key, env, usage string, allowed []string, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) {
+		if !slices.Contains(allowed, s) {
+			return "", fmt.Errorf("%q is not one of %s", s, strings.Join(allowed, ", "))
+		}
+		return s, nil
+	}, identity)
+}
+
+func overflowSetting(key, env, usage string, field func(*Config) *application.OverflowPolicy) setting {
+	return newSetting(key, env, usage, field, application.ParseOverflowPolicy,
+		func(p application.OverflowPolicy) string { return string(p) })
+}
+
+// portSetting accepts a TCP port number, or an empty value when optional
+func portSetting(key, env, usage string, optional bool, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) {
+		if s == "" && optional {
+			return "", nil
+		}
+		if v, err := strconv.Atoi(s); err != nil || v < 1 || v > 65535 {
+			return "", fmt.Errorf("%q is not a port between 1 and 65535", s)
+		}
+		return s, nil
+	}, identity)
+}
+
+// pathSetting accepts an absolute URL path
+func pathSetting(key, env, usage string, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) {
+		if !strings.HasPrefix(s, "/") {
+			return "", fmt.Errorf("%q must start with /", s)
+		}
+		return s, nil
+	}, identity)
+}
+
+// urlSetting accepts an absolute http(s) URL
+func urlSetting(key, env, usage string, field func(*Config) *string) setting {
+	return newSetting(key, env, usage, field, func(s string) (string, error) {
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("%q is not an http(s) URL", s)
+		}
+		return s, nil
+	}, identity)
+}
+
+// listSetting accepts comma-separated values (or a list in the config file)
+func listSetting(key, env, usage string, field func(*Config) *[]string) setting {
+	return newSetting(key, env, usage, field, func(s string) ([]string, error) {
+		var list []string
+		for item := range strings.SplitSeq(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	}, func(list []string) string { return strings.Join(list, ",") })
+}
+
+func identity(s string) string { return s }
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"fizzbuzz-service/internal/application"
+)
+
+// Pinger is implemented by every statistics backend to verify its storage
+// cheaply, without reading statistics
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// StatisticsChecker passes when the statistics backend answers its ping
+func StatisticsChecker(store Pinger) Checker {
+	return CheckerFunc(store.Ping)
+}
+
+// QueueStats is implemented by application.StatisticsDispatcher
+type QueueStats interface {
+	Stats() application.DispatcherStats
+}
+
+// QueueChecker fails while the statistics queue is filled beyond maxFill
+// (a fraction of its capacity), when hits are about to be dropped or block
+func QueueChecker(queue QueueStats, maxFill float64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		stats := queue.Stats()
+		if stats.QueueCapacity == 0 {
+			return nil
+		}
+		if fill := float64(stats.QueueDepth) / float64(stats.QueueCapacity); fill >= maxFill {
+			return fmt.Errorf("statistics queue saturated: %d/%d hits pending", stats.QueueDepth, stats.QueueCapacity)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,140 @@
+// Package health tells orchestrators whether the service can take traffic.
+//
+// Liveness only says the process responds. Readiness aggregates registered
+// checkers (dependencies reachable, capacity left) and turns false as soon as
+// graceful shutdown starts, so load balancers stop routing to the instance
+// before it stops accepting connections.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCheckTimeout bounds each checker when none is configured
+const DefaultCheckTimeout = 2 * time.Second
+
+// Readiness statuses
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+)
+
+// Check statuses
+const (
+	CheckOK      = "ok"
+	CheckFailing = "failing"
+)
+
+// Checker reports whether one dependency is usable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+// Report is the outcome of a readiness check
+type Report struct {
+	Status string
+	// Checks is keyed by checker name; empty while draining
+	Checks map[string]CheckResult
+}
+
+// Ready reports whether traffic should be routed to the service
+func (r Report) Ready() bool { return r.Status == StatusReady }
+
+// CheckResult is the outcome of one checker
+// The error of a failing checker is logged, not reported: probes are
+// unauthenticated and dependency errors may reveal paths or driver details.
+type CheckResult struct {
+	Status   string
+	Duration time.Duration
+}
+
+// Readiness aggregates checkers into a single readiness status
+type Readiness struct {
+	timeout time.Duration
+	logger  *slog.Logger
+
+	mu       sync.RWMutex
+	checkers []namedChecker
+
+	draining atomic.Bool
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// NewReadiness creates a readiness without checkers, which is ready until drained
+// timeout bounds each checker; zero means DefaultCheckTimeout.
+func NewReadiness(timeout time.Duration, logger *slog.Logger) *Readiness {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Readiness{timeout: timeout, logger: logger}
+}
+
+// Register adds a checker; the service is ready only when all of them pass
+func (r *Readiness) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, namedChecker{name, checker})
+}
+
+// Drain marks the service as shutting down: it is not ready from now on
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Check runs every checker concurrently and aggregates their results
+func (r *Readiness) Check(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	r.mu.RLock()
+	checkers := append([]namedChecker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Go(func() {
+			results[i] = r.run(ctx, c)
+		})
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(checkers))}
+	for i, c := range checkers {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != CheckOK {
+			report.Status = StatusNotReady
+		}
+	}
+	return report
+}
+
+func (r *Readiness) run(ctx context.Context, c namedChecker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	result := CheckResult{Status: CheckOK, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = CheckFailing
+		r.logger.Warn("readiness check failing", "check", c.name, "error", err)
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+// Package encoding renders FizzBuzz sequences in the media types negotiated
+// with the client. Formats are pluggable: implement Encoder and register it.
+package encoding
+
+import (
+	"bufio"
+	"io"
+	"iter"
+)
+
+// bufferSize bounds the memory held per response
+const bufferSize = 32 * 1024
+
+// Encoder writes a sequence in one media type, one item at a time
+// Encoders never see the whole sequence, so every format can be streamed.
+type Encoder interface {
+	// MediaType is matched against the Accept header (e.g. "text/csv")
+	MediaType() string
+	// ContentType is the Content-Type response header value
+	ContentType() string
+	// Start writes anything preceding the first item
+	Start(w *bufio.Writer) error
+	// Item writes one value; pos is its 0-based position in the output
+	// and n the number it was computed from
+	Item(w *bufio.Writer, pos, n int, value string) error
+	// Finish writes anything following the last item
+	Finish(w *bufio.Writer) error
+}
+
+// Encode writes seq to w with enc
+// flush, when not nil, is called every flushEvery items and at the end,
+// after the buffered output has been handed to w; an error from flush
+// (e.g. a cancelled request) stops the sequence.
+func Encode(w io.Writer, enc Encoder, seq iter.Seq2[int, string], flushEvery int, flush func() error) error {
+	bw := bufio.NewWriterSize(w, bufferSize)
+
+	flushAll := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if flush != nil {
+			return flush()
+		}
+		return nil
+	}
+
+	if err := enc.Start(bw); err != nil {
+		return err
+	}
+
+	pos := 0
+	for n, value := range seq {
+		if err := enc.Item(bw, pos, n, value); err != nil {
+			return err
+		}
+
+		pos++
+		if flushEvery > 0 && pos%flushEvery == 0 {
+			if err := flushAll(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := enc.Finish(bw); err != nil {
+		return err
+	}
+	return flushAll()
+}
@@ -0,0 +1,114 @@
+package encoding
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSON renders {"result": [...]}, the historical response shape
+type JSON struct{}
+
+func (JSON) MediaType() string   { return "application/json" }
+func (JSON) ContentType() string { return "application/json" }
+
+func (JSON) Start(w *bufio.Writer) error {
+	_, err := w.WriteString(`{"result":[`)
+	return err
+}
+
+func (JSON) Item(w *bufio.Writer, pos, _ int, value string) error {
+	if pos > 0 {
+		w.WriteByte(',')
+	}
+	return writeJSONString(w, value)
+}
+
+func (JSON) Finish(w *bufio.Writer) error {
+	_, err := w.WriteString("]}\n")
+	return err
+}
+
+// NDJSON renders one JSON string per line
+type NDJSON struct{}
+
+func (NDJSON) MediaType() string          { return "application/x-ndjson" }
+func (NDJSON) ContentType() string        { return "application/x-ndjson" }
+func (NDJSON) Start(*bufio.Writer) error  { return nil }
+func (NDJSON) Finish(*bufio.Writer) error { return nil }
+
+func (NDJSON) Item(w *bufio.Writer, _, _ int, value string) error {
+	if err := writeJSONString(w, value); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+// CSV renders an "index,value" table (RFC 4180)
+type CSV struct{}
+
+func (CSV) MediaType() string          { return "text/csv" }
+func (CSV) ContentType() string        { return "text/csv; charset=utf-8" }
+func (CSV) Finish(*bufio.Writer) error { return nil }
+
+func (CSV) Start(w *bufio.Writer) error {
+	_, err := w.WriteString("index,value\r\n")
+	return err
+}
+
+func (CSV) Item(w *bufio.Writer, _, n int, value string) error {
+	var buf [20]byte
+	w.Write(strconv.AppendInt(buf[:0], int64(n), 10))
+	w.WriteByte(',')
+
+	if strings.ContainsAny(value, ",\"\r\n") {
+		w.WriteByte('"')
+		w.WriteString(strings.ReplaceAll(value, `"`, `""`))
+		w.WriteByte('"')
+	} else {
+		w.WriteString(value)
+	}
+
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// Text renders one value per line
+type Text struct{}
+
+func (Text) MediaType() string          { return "text/plain" }
+func (Text) ContentType() string        { return "text/plain; charset=utf-8" }
+func (Text) Start(*bufio.Writer) error  { return nil }
+func (Text) Finish(*bufio.Writer) error { return nil }
+
+func (Text) Item(w *bufio.Writer, _, _ int, value string) error {
+	w.WriteString(value)
+	return w.WriteByte('\n')
+}
+
+// writeJSONString writes value as a JSON string
+// Numbers, by far the most common values, skip the generic encoder.
+func writeJSONString(w *bufio.Writer, value string) error {
+	if isDigits(value) {
+		w.WriteByte('"')
+		w.WriteString(value)
+		return w.WriteByte('"')
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,136 @@
+package encoding
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Registry holds the available encoders and negotiates between them
+// The first registered encoder is the default, used for "*/*" or a
+// missing Accept header.
+type Registry struct {
+	encoders []Encoder
+}
+
+// NewRegistry creates a registry with the given encoders, in preference order
+func NewRegistry(encoders ...Encoder) *Registry {
+	r := &Registry{}
+	for _, enc := range encoders {
+		r.Register(enc)
+	}
+	return r
+}
+
+// DefaultRegistry returns the formats served by the API, JSON first
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		JSON{},
+		NDJSON{},
+		CSV{},
+		Text{},
+	)
+}
+
+// Register adds an encoder, replacing any encoder for the same media type
+func (r *Registry) Register(enc Encoder) {
+	for i, existing := range r.encoders {
+		if existing.MediaType() == enc.MediaType() {
+			r.encoders[i] = enc
+			return
+		}
+	}
+	r.encoders = append(r.encoders, enc)
+}
+
+// MediaTypes lists the registered media types, in preference order
+func (r *Registry) MediaTypes() []string {
+	types := make([]string, len(r.encoders))
+	for i, enc := range r.encoders {
+		types[i] = enc.MediaType()
+	}
+	return types
+}
+
+// Negotiate picks the encoder matching the Accept header best
+// Ranges are honoured by quality, then specificity; ok is false when
+// nothing registered is acceptable (HTTP 406).
+func (r *Registry) Negotiate(accept string) (enc Encoder, ok bool) {
+	if len(r.encoders) == 0 {
+		return nil, false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return r.encoders[0], true
+	}
+
+	ranges := parseAccept(accept)
+
+	bestQ := 0.0
+	for _, candidate := range r.encoders {
+		q := quality(ranges, candidate.MediaType())
+		if q > bestQ {
+			enc, bestQ = candidate, q
+		}
+	}
+	return enc, enc != nil
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// specificity orders ranges so the most specific match wins (RFC 9110 §12.5.1)
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (m mediaRange) matches(typ, subtype string) bool {
+	return (m.typ == "*" || m.typ == typ) && (m.subtype == "*" || m.subtype == subtype)
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, found := strings.Cut(mediaType, "/")
+		if !found {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+// quality returns the q-value of the most specific range matching mediaType
+func quality(ranges []mediaRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	for _, m := range ranges {
+		if m.matches(typ, subtype) {
+			return m.q
+		}
+	}
+	return 0
+}
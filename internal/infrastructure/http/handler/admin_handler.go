@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"fizzbuzz-service/internal/infrastructure/config"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ConfigReloader reloads the service configuration (see config.Reloader)
+type ConfigReloader interface {
+	Reload() ([]config.Change, error)
+}
+
+// AdminHandler handles HTTP requests for operating the service
+type AdminHandler struct {
+	reloader ConfigReloader
+	problems *problem.Registry
+	logger   *slog.Logger
+}
+
+// ReloadResponse lists the settings changed by a reload
+// swagger:model
+type reloadResponse struct {
+	// Settings whose value changed, applied or not
+	// required: true
+	Changes []changeResponse `json:"changes"`
+}
+
+// ChangeResponse is one changed setting
+// swagger:model
+type changeResponse struct {
+	// File key of the setting
+	// required: true
+	// example: fizzbuzz.max_limit
+	Setting string `json:"setting"`
+	// Value before the reload
+	// required: true
+	// example: 10000
+	Old string `json:"old"`
+	// Value after the reload
+	// required: true
+	// example: 500
+	New string `json:"new"`
+	// False when the setting only takes effect on restart
+	// required: true
+	// example: true
+	Applied bool `json:"applied"`
+}
+
+// NewAdminHandler creates a new Admin HTTP handler
+// Errors are mapped as on other routes, configuration errors included.
+func NewAdminHandler(reloader ConfigReloader, logger *slog.Logger) *AdminHandler {
+	problems := problem.DefaultRegistry()
+	problems.Register(configProblem)
+
+	return &AdminHandler{
+		reloader: reloader,
+		problems: problems,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes registers all admin routes
+func (h *AdminHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/admin/reload", h.Reload)
+}
+
+// swagger:route POST /admin/reload admin reloadConfig
+//
+// # Reload Configuration
+//
+// Re-reads the configuration file and environment, as on SIGHUP. Reloadable
+// settings (max limits, log level, CORS) take effect immediately; changes of
+// other settings are reported with applied false. An invalid configuration
+// is rejected as a whole and the current one is kept.
+//
+// Responses:
+//
+//	200: reloadResponse
+//	422: problemResponse
+//	500: problemResponse
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.reloader.Reload()
+	if err != nil {
+		writeError(w, r, h.problems, h.logger, err)
+		return
+	}
+
+	resp := reloadResponse{Changes: make([]changeResponse, 0, len(changes))}
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, changeResponse{
+			Setting: c.Setting,
+			Old:     c.Old,
+			New:     c.New,
+			Applied: c.Applied,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// swagger:response reloadResponse
+type reloadResponseWrapper struct {
+	// in: body
+	Body reloadResponse
+}
+
+// configProblem maps config.Error to InvalidConfiguration, with one invalid
+// parameter per problem, named after its setting
+func configProblem(err error) (problem.Problem, bool) {
+	var cfgErr *config.Error
+	if !errors.As(err, &cfgErr) {
+		return problem.Problem{}, false
+	}
+
+	p := problem.InvalidConfiguration.New(strings.Join(cfgErr.Problems, "; "))
+	for _, reason := range cfgErr.Problems {
+		// Problems about a setting start with its dotted key, e.g.
+		// "log.level (file config.yaml): ..."
+		setting, _, _ := strings.Cut(reason, ":")
+		setting, _, _ = strings.Cut(setting, " ")
+		if !strings.Contains(setting, ".") {
+			setting = ""
+		}
+		p.InvalidParams = append(p.InvalidParams, problem.InvalidParam{Name: setting, Reason: reason})
+	}
+	return p, true
+}
+
+func (h *AdminHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
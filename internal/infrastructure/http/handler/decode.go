@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+// DefaultMaxBodyBytes bounds request bodies unless configured otherwise
+const DefaultMaxBodyBytes = 1 << 20
+
+// Codes of invalid parameters of malformed bodies, next to those of domain
+// violations
+const (
+	codeInvalidType = "invalid_type"
+	codeSyntax      = "syntax"
+	codeTrailing    = "trailing_data"
+)
+
+// decodeJSON strictly decodes the JSON body of r into dst
+// The body must be application/json, at most maxBytes long, and hold a
+// single value without fields unknown to dst. Otherwise the returned
+// problem names the offending field and its position, and ok is false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, dst any) (p problem.Problem, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return problem.UnsupportedMediaType.New("Content-Type must be application/json"), false
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return problem.PayloadTooLarge.New(fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)), false
+		}
+		return problem.MalformedRequest.New("cannot read request body"), false
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return problem.MalformedRequest.New("request body is empty"), false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return malformedBody(data, err), false
+	}
+
+	// Only whitespace may follow the value
+	end := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		end += int64(len(data[end:]) - len(bytes.TrimLeft(data[end:], " \t\r\n")))
+		return malformedAt(data, end, "", codeTrailing, "unexpected data after the JSON value"), false
+	}
+	return problem.Problem{}, true
+}
+
+// malformedBody explains why data failed to decode
+func malformedBody(data []byte, err error) problem.Problem {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is past the offending byte
+		return malformedAt(data, syntaxErr.Offset-1, "", codeSyntax, syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		field, subject := fieldPath(typeErr.Field), "request body"
+		if field != "" {
+			subject = field
+		}
+		offset, _ := locate(data, func(path []string, key bool) bool {
+			return !key && strings.Join(path, ".") == typeErr.Field
+		})
+		return malformedAt(data, offset, field, codeInvalidType,
+			fmt.Sprintf("%s must be %s, got %s", subject, jsonType(typeErr.Type), typeErr.Value))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return malformedAt(data, int64(len(data)), "", codeSyntax, "unexpected end of JSON")
+	}
+
+	if quoted, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		name := strings.Trim(quoted, `"`)
+		offset, path := locate(data, func(path []string, key bool) bool {
+			return key && path[len(path)-1] == name
+		})
+		field := name
+		if path != nil {
+			field = fieldPath(strings.Join(path, "."))
+		}
+		return malformedAt(data, offset, field, domain.CodeUnknown, "unknown field "+quoted)
+	}
+	return problem.MalformedRequest.New("invalid JSON body")
+}
+
+// malformedAt reports a malformed body, about the byte at offset in data
+func malformedAt(data []byte, offset int64, field, code, reason string) problem.Problem {
+	line, column := position(data, offset)
+
+	p := problem.MalformedRequest.New(fmt.Sprintf("%s at line %d, column %d", reason, line, column))
+	p.InvalidParams = []problem.InvalidParam{{
+		Name:   field,
+		Code:   code,
+		Reason: reason,
+		Params: map[string]any{"offset": offset, "line": line, "column": column},
+	}}
+	return p
+}
+
+// locate returns the offset and the path of the first key, or start of
+// value, that matches; the offset is -1 when none does
+// Paths are those of encoding/json, e.g. ["rules", "1", "divisor"].
+func locate(data []byte, match func(path []string, key bool) bool) (int64, []string) {
+	type frame struct {
+		object    bool
+		expectKey bool
+		name      string
+		index     int
+	}
+	var stack []*frame
+
+	path := func() []string {
+		path := make([]string, 0, len(stack))
+		for _, f := range stack {
+			if f.object {
+				path = append(path, f.name)
+			} else {
+				path = append(path, strconv.Itoa(f.index))
+			}
+		}
+		return path
+	}
+	// next moves the innermost container past a complete value
+	next := func() {
+		if n := len(stack); n > 0 {
+			if f := stack[n-1]; f.object {
+				f.expectKey = true
+			} else {
+				f.index++
+			}
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		start := skipSeparators(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return -1, nil
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			next()
+			continue
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].expectKey {
+			stack[n-1].name, stack[n-1].expectKey = tok.(string), false
+			if match(path(), true) {
+				return start, path()
+			}
+			continue
+		}
+
+		if match(path(), false) {
+			return start, path()
+		}
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, &frame{object: d == '{', expectKey: d == '{'})
+			continue
+		}
+		next()
+	}
+}
+
+// skipSeparators returns the offset of the first token at or after offset
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// position converts the offset of a byte into its line and column, both
+// 1-based
+func position(data []byte, offset int64) (line, column int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// fieldPath converts the path of encoding/json ("rules.1.divisor") to the
+// one of validation errors ("rules[1].divisor")
+func fieldPath(path string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		switch {
+		case part != "" && strings.Trim(part, "0123456789") == "":
+			b.WriteString("[" + part + "]")
+		case i > 0:
+			b.WriteString("." + part)
+		default:
+			b.WriteString(part)
+		}
+	}
+	return b.String()
+}
+
+// jsonType names the JSON type decoded into t
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
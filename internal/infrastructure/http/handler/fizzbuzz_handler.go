@@ -1,21 +1,73 @@
 package handler
 
 import (
-	"encoding/json"
 	"fizzbuzz-service/internal/application"
-	"fizzbuzz-service/internal/domain"
 	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/http/encoding"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+	"iter"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// tracerName is the instrumentation scope of the spans of this package
+const tracerName = "fizzbuzz-service/internal/infrastructure/http/handler"
+
 type FizzBuzzHandler struct {
 	generateUseCase *application.GenerateFizzBuzzUseCase
+	encoders        *encoding.Registry
+	observers       []SequenceObserver
+	problems        *problem.Registry
+	maxBodyBytes    int64
+	tracer          trace.Tracer
 	logger          *slog.Logger
 }
 
+// SequenceObserver is notified of every generated sequence (e.g. metrics)
+type SequenceObserver interface {
+	ObserveSequenceLength(length int)
+}
+
+// FizzBuzzHandlerOption customizes the handler beyond its required dependencies
+type FizzBuzzHandlerOption func(*FizzBuzzHandler)
+
+// WithEncoders replaces the response formats offered to clients
+// Defaults to encoding.DefaultRegistry()
+func WithEncoders(encoders *encoding.Registry) FizzBuzzHandlerOption {
+	return func(h *FizzBuzzHandler) {
+		h.encoders = encoders
+	}
+}
+
+// WithSequenceObserver reports the length of generated sequences to o
+// Streams report the requested limit, even if the client disconnects early.
+func WithSequenceObserver(o SequenceObserver) FizzBuzzHandlerOption {
+	return func(h *FizzBuzzHandler) {
+		h.observers = append(h.observers, o)
+	}
+}
+
+// WithMaxBodyBytes rejects request bodies longer than n bytes with 413
+// Defaults to DefaultMaxBodyBytes
+func WithMaxBodyBytes(n int64) FizzBuzzHandlerOption {
+	return func(h *FizzBuzzHandler) {
+		h.maxBodyBytes = n
+	}
+}
+
+// WithTracerProvider opens a span around each fizzbuzz endpoint
+// Defaults to a no-op provider
+func WithTracerProvider(tp trace.TracerProvider) FizzBuzzHandlerOption {
+	return func(h *FizzBuzzHandler) {
+		h.tracer = tp.Tracer(tracerName)
+	}
+}
+
 // Request/Response DTOs
 
 // swagger:parameters generateFizzBuzz
@@ -27,14 +79,15 @@ type generateFizzBuzzParams struct {
 }
 
 // GenerateRequest represents the input for FizzBuzz generation
+// Either int1/int2/str1/str2 or rules must be provided, not both
 // swagger:model
 type generateRequest struct {
 	// First divisor (must be > 0)
-	// required: true
+	// required: false
 	// example: 3
 	Int1 int `json:"int1"`
 	// Second divisor (must be > 0)
-	// required: true
+	// required: false
 	// example: 5
 	Int2 int `json:"int2"`
 	// Upper limit for the sequence (must be > 0 and <= MAX_LIMIT)
@@ -42,13 +95,37 @@ type generateRequest struct {
 	// example: 15
 	Limit int `json:"limit"`
 	// String to replace multiples of int1
-	// required: true
+	// required: false
 	// example: fizz
 	Str1 string `json:"str1"`
 	// String to replace multiples of int2
-	// required: true
+	// required: false
 	// example: buzz
 	Str2 string `json:"str2"`
+	// Ordered list of rules, replaces int1/int2/str1/str2
+	// required: false
+	Rules []ruleRequest `json:"rules,omitempty"`
+	// Numbers to skip before the returned page (0 to limit-1), defaults to 0
+	// required: false
+	// example: 10
+	Offset *int `json:"offset,omitempty"`
+	// Numbers in the returned page (1 to limit), defaults to the rest of the sequence
+	// required: false
+	// example: 5
+	Count *int `json:"count,omitempty"`
+}
+
+// RuleRequest maps a divisor to its replacement word
+// swagger:model
+type ruleRequest struct {
+	// Divisor (must be > 0)
+	// required: true
+	// example: 7
+	Divisor int `json:"divisor"`
+	// Word replacing multiples of divisor
+	// required: true
+	// example: bazz
+	Word string `json:"word"`
 }
 
 // GenerateResponse contains the FizzBuzz sequence
@@ -60,31 +137,29 @@ type generateResponse struct {
 	Result []string `json:"result"`
 }
 
-// ErrorResponse represents an error response
-// swagger:model
-type errorResponse struct {
-	// Error message
-	// required: true
-	// example: invalid parameters
-	Error string `json:"error"`
-	// Detailed error messages
-	// required: false
-	Details []string `json:"details,omitempty"`
-}
-
 // NewFizzBuzzHandler creates a new FizzBuzz HTTP handler
 func NewFizzBuzzHandler(
 	generateUseCase *application.GenerateFizzBuzzUseCase,
 	logger *slog.Logger,
+	opts ...FizzBuzzHandlerOption,
 ) *FizzBuzzHandler {
-	return &FizzBuzzHandler{
+	h := &FizzBuzzHandler{
 		generateUseCase: generateUseCase,
+		encoders:        encoding.DefaultRegistry(),
+		problems:        problem.DefaultRegistry(),
+		maxBodyBytes:    DefaultMaxBodyBytes,
+		tracer:          noop.NewTracerProvider().Tracer(tracerName),
 		logger:          logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers all fizzbuzz-related routes
 func (h *FizzBuzzHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/fizzbuzz", h.GenerateFromQuery)
 	r.Post("/fizzbuzz", h.Generate)
 }
 
@@ -95,20 +170,131 @@ func (h *FizzBuzzHandler) RegisterRoutes(r chi.Router) {
 // Generates a customizable FizzBuzz sequence based on the provided parameters.
 // The algorithm replaces numbers divisible by int1 with str1, numbers divisible
 // by int2 with str2, and numbers divisible by both with str1+str2.
+// A "rules" list can be sent instead to use any number of divisors; words of
+// all matching rules are joined in rule order.
+//
+// "offset" and "count" return a page of the sequence, computing only its
+// numbers; a Link header then points to the first, previous, next and last
+// pages through GET /fizzbuzz. Statistics count the whole query.
+//
+// The response format follows the Accept header: JSON (default), NDJSON,
+// CSV (index,value) or plain text (one value per line).
+// The body must be sent as application/json, without unknown fields or
+// trailing data.
+//
+// Consumes:
+// - application/json
+//
+// Produces:
+// - application/json
+// - application/x-ndjson
+// - text/csv
+// - text/plain
 //
 // Responses:
 //
 //	200: generateResponse
-//	400: errorResponse
-//	500: errorResponse
+//	400: problemResponse
+//	406: problemResponse
+//	413: problemResponse
+//	415: problemResponse
+//	500: problemResponse
 func (h *FizzBuzzHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	r, span := h.startSpan(r, "FizzBuzzHandler.Generate")
+	defer span.End()
+
+	enc, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
+
 	var req generateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Debug("failed to decode request", "error", err)
-		h.writeError(w, http.StatusBadRequest, "invalid JSON body", nil)
+	if !h.decode(w, r, &req) {
 		return
 	}
 
+	query := req.toQuery()
+	page := newPage(query.UpperLimit, req.Offset, req.Count)
+
+	result, err := h.generate(r.Context(), query, page)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.observeLength(len(result))
+
+	if page != nil {
+		setPageLinks(w, r, query, *page)
+	}
+
+	if err := h.writeSequence(w, enc, sliceSequence(result, firstNumber(page)), 0, nil); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// decode strictly decodes the JSON body into req, answering with a problem
+// when it is rejected
+func (h *FizzBuzzHandler) decode(w http.ResponseWriter, r *http.Request, req *generateRequest) bool {
+	p, ok := decodeJSON(w, r, h.maxBodyBytes, req)
+	if !ok {
+		h.logger.Debug("failed to decode request", "detail", p.Detail)
+		writeProblem(w, r, h.logger, p)
+	}
+	return ok
+}
+
+// startSpan opens a span as a child of the request's, and returns the request carrying it
+func (h *FizzBuzzHandler) startSpan(r *http.Request, name string) (*http.Request, trace.Span) {
+	ctx, span := h.tracer.Start(r.Context(), name)
+	return r.WithContext(ctx), span
+}
+
+func (h *FizzBuzzHandler) observeLength(length int) {
+	for _, o := range h.observers {
+		o.ObserveSequenceLength(length)
+	}
+}
+
+// negotiate selects the response encoder, answering 406 when none is acceptable
+func (h *FizzBuzzHandler) negotiate(w http.ResponseWriter, r *http.Request) (encoding.Encoder, bool) {
+	enc, ok := h.encoders.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		writeProblem(w, r, h.logger, problem.NotAcceptable.New(
+			"supported media types: "+strings.Join(h.encoders.MediaTypes(), ", "),
+		))
+	}
+	return enc, ok
+}
+
+// writeSequence writes a 200 response encoded with enc
+func (h *FizzBuzzHandler) writeSequence(
+	w http.ResponseWriter,
+	enc encoding.Encoder,
+	seq iter.Seq2[int, string],
+	flushEvery int,
+	flush func() error,
+) error {
+	w.Header().Set("Content-Type", enc.ContentType())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	return encoding.Encode(w, enc, seq, flushEvery, flush)
+}
+
+// sliceSequence adapts a generated sequence, starting with number first, to
+// the encoders' input
+func sliceSequence(result []string, first int) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, value := range result {
+			if !yield(first+i, value) {
+				return
+			}
+		}
+	}
+}
+
+// toQuery maps the request DTO to the domain query
+func (req generateRequest) toQuery() entity.FizzBuzzQuery {
 	query := entity.FizzBuzzQuery{
 		FirstDivisor:  req.Int1,
 		SecondDivisor: req.Int2,
@@ -117,13 +303,14 @@ func (h *FizzBuzzHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		SecondString:  req.Str2,
 	}
 
-	result, err := h.generateUseCase.Generate(r.Context(), query)
-	if err != nil {
-		h.handleError(w, err)
-		return
+	for _, rule := range req.Rules {
+		query.Rules = append(query.Rules, entity.Rule{
+			Divisor: rule.Divisor,
+			Word:    rule.Word,
+		})
 	}
 
-	h.writeJSON(w, http.StatusOK, generateResponse{Result: result})
+	return query
 }
 
 // swagger:response generateResponse
@@ -132,34 +319,7 @@ type generateResponseWrapper struct {
 	Body generateResponse
 }
 
-// swagger:response errorResponse
-type errorResponseWrapper struct {
-	// in: body
-	Body errorResponse
-}
-
 // handleError maps domain errors to HTTP responses
-func (h *FizzBuzzHandler) handleError(w http.ResponseWriter, err error) {
-	switch e := err.(type) {
-	case domain.ValidationError:
-		h.writeError(w, http.StatusBadRequest, e.Message, e.Details)
-	default:
-		h.logger.Error("unexpected error", "error", err)
-		h.writeError(w, http.StatusInternalServerError, "internal server error", nil)
-	}
-}
-
-func (h *FizzBuzzHandler) writeError(w http.ResponseWriter, status int, message string, details []string) {
-	h.writeJSON(w, status, errorResponse{
-		Error:   message,
-		Details: details,
-	})
-}
-
-func (h *FizzBuzzHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.logger.Error("failed to encode response", "error", err)
-	}
+func (h *FizzBuzzHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, h.problems, h.logger, err)
 }
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// newPage builds the page requested by the optional offset and count
+// parameters, or nil when neither is given
+// offset defaults to 0 and count to the whole limit, truncated at its end.
+func newPage(limit int, offset, count *int) *entity.Page {
+	if offset == nil && count == nil {
+		return nil
+	}
+
+	page := entity.Page{Count: limit}
+	if offset != nil {
+		page.Offset = *offset
+	}
+	if count != nil {
+		page.Count = *count
+	}
+	return &page
+}
+
+// firstNumber is the number a response starts with
+func firstNumber(page *entity.Page) int {
+	if page == nil {
+		return 1
+	}
+	return page.Start()
+}
+
+// unsupportedPage reports the pagination parameters of a stream request
+func unsupportedPage(offset, count *int) error {
+	var violations []domain.Violation
+	if offset != nil {
+		violations = append(violations, domain.Invalid("offset", domain.CodeUnsupported, "offset is not supported by streams"))
+	}
+	if count != nil {
+		violations = append(violations, domain.Invalid("count", domain.CodeUnsupported, "count is not supported by streams"))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return domain.NewValidationError("invalid parameters", violations...)
+}
+
+// validate checks the query, and its page when one is requested
+func (h *FizzBuzzHandler) validate(query entity.FizzBuzzQuery, page *entity.Page) error {
+	if page == nil {
+		return h.generateUseCase.Validate(query)
+	}
+	return h.generateUseCase.ValidatePage(query, *page)
+}
+
+// generate computes the whole sequence, or only its page when one is requested
+func (h *FizzBuzzHandler) generate(ctx context.Context, query entity.FizzBuzzQuery, page *entity.Page) ([]string, error) {
+	if page == nil {
+		return h.generateUseCase.Generate(ctx, query)
+	}
+	return h.generateUseCase.GeneratePage(ctx, query, *page)
+}
+
+// setPageLinks advertises the first, previous, next and last pages in a
+// Link header (RFC 8288)
+// Links target GET on the request path, so the pages of a POST can be
+// browsed too.
+func setPageLinks(w http.ResponseWriter, r *http.Request, query entity.FizzBuzzQuery, page entity.Page) {
+	limit := query.UpperLimit
+	values := queryValues(query)
+
+	links := []string{pageLink(r.URL.Path, values, entity.Page{Count: page.Count}, "first")}
+	if prev, ok := page.Prev(); ok {
+		links = append(links, pageLink(r.URL.Path, values, prev, "prev"))
+	}
+	if next, ok := page.Next(limit); ok {
+		links = append(links, pageLink(r.URL.Path, values, next, "next"))
+	}
+	links = append(links, pageLink(r.URL.Path, values, page.LastPage(limit), "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageLink formats one link of the Link header
+func pageLink(path string, values url.Values, page entity.Page, rel string) string {
+	values.Set("offset", strconv.Itoa(page.Offset))
+	values.Set("count", strconv.Itoa(page.Count))
+	target := url.URL{Path: path, RawQuery: values.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel)
+}
+
+// queryValues maps a query to the parameters of GET /fizzbuzz
+func queryValues(query entity.FizzBuzzQuery) url.Values {
+	values := url.Values{"limit": {strconv.Itoa(query.UpperLimit)}}
+
+	if query.HasRules() {
+		for _, rule := range query.Rules {
+			values.Add("rule", fmt.Sprintf("%d:%s", rule.Divisor, rule.Word))
+		}
+		return values
+	}
+
+	values.Set("int1", strconv.Itoa(query.FirstDivisor))
+	values.Set("int2", strconv.Itoa(query.SecondDivisor))
+	values.Set("str1", query.FirstString)
+	values.Set("str2", query.SecondString)
+	return values
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/http/encoding"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cacheControl applies to GET responses: a query always yields the same sequence
+const cacheControl = "public, max-age=3600"
+
+// swagger:parameters generateFizzBuzzFromQuery
+type generateFizzBuzzQueryParams struct {
+	// First divisor (must be > 0)
+	// in: query
+	// example: 3
+	Int1 int `json:"int1"`
+	// Second divisor (must be > 0)
+	// in: query
+	// example: 5
+	Int2 int `json:"int2"`
+	// Upper limit for the sequence (must be > 0 and <= MAX_LIMIT)
+	// in: query
+	// required: true
+	// example: 15
+	Limit int `json:"limit"`
+	// String to replace multiples of int1
+	// in: query
+	// example: fizz
+	Str1 string `json:"str1"`
+	// String to replace multiples of int2
+	// in: query
+	// example: buzz
+	Str2 string `json:"str2"`
+	// Rule as "divisor:word", repeated in order; replaces int1/int2/str1/str2
+	// in: query
+	// collectionFormat: multi
+	// example: ["3:fizz", "5:buzz", "7:bazz"]
+	Rule []string `json:"rule"`
+	// Numbers to skip before the returned page (0 to limit-1), defaults to 0
+	// in: query
+	// example: 10
+	Offset int `json:"offset"`
+	// Numbers in the returned page (1 to limit), defaults to the rest of the sequence
+	// in: query
+	// example: 5
+	Count int `json:"count"`
+}
+
+// swagger:route GET /fizzbuzz fizzbuzz generateFizzBuzzFromQuery
+//
+// # Generate FizzBuzz Sequence (query string)
+//
+// Bookmarkable and cacheable variant of POST /fizzbuzz taking its parameters
+// from the query string. Responses carry an ETag derived from the query and the
+// negotiated format; send it back in If-None-Match to get a 304.
+// Revalidations answered with 304 are not counted in statistics.
+// "offset" and "count" return a page, as with POST /fizzbuzz.
+//
+// Produces:
+// - application/json
+// - application/x-ndjson
+// - text/csv
+// - text/plain
+//
+// Responses:
+//
+//	200: generateResponse
+//	304: description: Not Modified
+//	400: problemResponse
+//	406: problemResponse
+//	500: problemResponse
+func (h *FizzBuzzHandler) GenerateFromQuery(w http.ResponseWriter, r *http.Request) {
+	r, span := h.startSpan(r, "FizzBuzzHandler.GenerateFromQuery")
+	defer span.End()
+
+	enc, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	query, page, err := parseQueryParams(r.URL.Query())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	if err := h.validate(query, page); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	etag := computeETag(query, page, enc)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if page != nil {
+		setPageLinks(w, r, query, *page)
+	}
+
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	result, err := h.generate(r.Context(), query, page)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.observeLength(len(result))
+
+	if err := h.writeSequence(w, enc, sliceSequence(result, firstNumber(page)), 0, nil); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// parseQueryParams strictly maps the query string to a domain query, and the
+// page requested if any
+// Unknown, repeated or malformed parameters are reported together.
+func parseQueryParams(values url.Values) (entity.FizzBuzzQuery, *entity.Page, error) {
+	var (
+		query         entity.FizzBuzzQuery
+		offset, count int
+		violations    []domain.Violation
+	)
+
+	intParams := map[string]*int{
+		"int1":   &query.FirstDivisor,
+		"int2":   &query.SecondDivisor,
+		"limit":  &query.UpperLimit,
+		"offset": &offset,
+		"count":  &count,
+	}
+	stringParams := map[string]*string{
+		"str1": &query.FirstString,
+		"str2": &query.SecondString,
+	}
+
+	// Sorted for deterministic error ordering
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		vals := values[name]
+
+		if name == "rule" {
+			for i, raw := range vals {
+				rule, violation := parseRule(i, raw)
+				if violation != nil {
+					violations = append(violations, *violation)
+					continue
+				}
+				query.Rules = append(query.Rules, rule)
+			}
+			continue
+		}
+
+		intTarget, isInt := intParams[name]
+		stringTarget, isString := stringParams[name]
+
+		switch {
+		case !isInt && !isString:
+			violations = append(violations, domain.Invalid(name, domain.CodeUnknown,
+				fmt.Sprintf("unknown parameter %q", name)))
+		case len(vals) > 1:
+			violations = append(violations, domain.Invalid(name, domain.CodeRepeated,
+				name+" must be given only once"))
+		case isInt:
+			n, err := strconv.Atoi(vals[0])
+			if err != nil {
+				violations = append(violations, domain.Invalid(name, domain.CodeMustBeInteger,
+					name+" must be an integer"))
+				continue
+			}
+			*intTarget = n
+		default:
+			*stringTarget = vals[0]
+		}
+	}
+
+	if len(violations) > 0 {
+		return entity.FizzBuzzQuery{}, nil, domain.NewValidationError("invalid parameters", violations...)
+	}
+
+	var offsetParam, countParam *int
+	if values.Has("offset") {
+		offsetParam = &offset
+	}
+	if values.Has("count") {
+		countParam = &count
+	}
+	return query, newPage(query.UpperLimit, offsetParam, countParam), nil
+}
+
+// parseRule parses the i-th rule, "divisor:word"; the word may itself
+// contain colons
+func parseRule(i int, raw string) (entity.Rule, *domain.Violation) {
+	field := fmt.Sprintf("rules[%d]", i)
+
+	divisor, word, found := strings.Cut(raw, ":")
+	if !found {
+		v := domain.Invalid(field, domain.CodeInvalidFormat, field+" must be formatted as divisor:word")
+		return entity.Rule{}, &v
+	}
+
+	n, err := strconv.Atoi(divisor)
+	if err != nil {
+		field += ".divisor"
+		v := domain.Invalid(field, domain.CodeMustBeInteger, field+" must be an integer")
+		return entity.Rule{}, &v
+	}
+
+	return entity.Rule{Divisor: n, Word: word}, nil
+}
+
+// computeETag derives a strong validator from the limit, the rules, the
+// page and the format
+// Numbers are varints and strings are length-prefixed, so two distinct
+// requests never hash the same bytes, whatever their words contain.
+func computeETag(query entity.FizzBuzzQuery, page *entity.Page, enc encoding.Encoder) string {
+	var buf []byte
+	number := func(n int) { buf = binary.AppendVarint(buf, int64(n)) }
+	text := func(s string) {
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		buf = append(buf, s...)
+	}
+
+	number(query.UpperLimit)
+	rules := query.RuleSet()
+	number(len(rules))
+	for _, rule := range rules {
+		number(rule.Divisor)
+		text(rule.Word)
+	}
+	if page != nil {
+		buf = append(buf, 1)
+		number(page.Offset)
+		number(page.Count)
+	} else {
+		buf = append(buf, 0)
+	}
+	text(enc.ContentType())
+
+	sum := sha256.Sum256(buf)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchesETag evaluates If-None-Match with the weak comparison of RFC 9110 §13.1.2
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// streamFlushEvery is the number of items written between two flushes
+const streamFlushEvery = 4096
+
+// RegisterStreamRoutes registers the streaming routes
+// They must not be wrapped by a request timeout middleware
+func (h *FizzBuzzHandler) RegisterStreamRoutes(r chi.Router) {
+	r.Post("/fizzbuzz/stream", h.Stream)
+}
+
+// swagger:route POST /fizzbuzz/stream fizzbuzz streamFizzBuzz
+//
+// # Stream FizzBuzz Sequence
+//
+// Same input and output formats as POST /fizzbuzz, but the limit is capped by
+// STREAM_MAX_LIMIT and the sequence is written progressively with chunked
+// transfer encoding. Generation stops as soon as the client disconnects.
+// Pages (offset and count) are not supported.
+//
+// Consumes:
+// - application/json
+//
+// Produces:
+// - application/json
+// - application/x-ndjson
+// - text/csv
+// - text/plain
+//
+// Responses:
+//
+//	200: generateResponse
+//	400: problemResponse
+//	406: problemResponse
+//	413: problemResponse
+//	415: problemResponse
+//	500: problemResponse
+func (h *FizzBuzzHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	r, span := h.startSpan(r, "FizzBuzzHandler.Stream")
+	defer span.End()
+
+	enc, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	var req generateRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := unsupportedPage(req.Offset, req.Count); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	query := req.toQuery()
+
+	seq, err := h.generateUseCase.Stream(r.Context(), query)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.observeLength(query.UpperLimit)
+
+	// Streams legitimately outlive the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debug("cannot clear write deadline", "error", err)
+	}
+
+	flush := func() error {
+		if err := rc.Flush(); err != nil && err != http.ErrNotSupported {
+			return err
+		}
+		return r.Context().Err()
+	}
+
+	if err := h.writeSequence(w, enc, seq, streamFlushEvery, flush); err != nil {
+		h.logger.Debug("stream interrupted", "error", err)
+	}
+}
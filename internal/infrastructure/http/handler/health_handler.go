@@ -2,13 +2,28 @@ package handler
 
 import (
 	"encoding/json"
+	"fizzbuzz-service/internal/infrastructure/health"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // HealthHandler handles HTTP requests for health checks
-type HealthHandler struct{}
+type HealthHandler struct {
+	readiness *health.Readiness
+}
+
+// HealthHandlerOption customizes the handler
+type HealthHandlerOption func(*HealthHandler)
+
+// WithReadiness answers /readyz from r
+// Defaults to a readiness without checkers, which is always ready.
+func WithReadiness(r *health.Readiness) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		h.readiness = r
+	}
+}
 
 // HealthResponse represents the health status
 // swagger:model
@@ -19,14 +34,46 @@ type healthResponse struct {
 	Status string `json:"status"`
 }
 
+// ReadinessResponse details whether the service can take traffic
+// swagger:model
+type readinessResponse struct {
+	// ready, not_ready or draining (graceful shutdown in progress)
+	// required: true
+	// example: ready
+	Status string `json:"status"`
+	// Outcome of each dependency check, by name (omitted while draining)
+	// required: false
+	Checks map[string]checkResponse `json:"checks,omitempty"`
+}
+
+// CheckResponse is the outcome of one dependency check
+// Why a check fails is only logged, not exposed to unauthenticated probes.
+// swagger:model
+type checkResponse struct {
+	// ok or failing
+	// required: true
+	// example: ok
+	Status string `json:"status"`
+	// Time taken by the check in milliseconds
+	// required: true
+	// example: 0.42
+	DurationMs float64 `json:"duration_ms"`
+}
+
 // NewHealthHandler creates a new Health HTTP handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(opts ...HealthHandlerOption) *HealthHandler {
+	h := &HealthHandler{readiness: health.NewReadiness(0, slog.New(slog.DiscardHandler))}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers all health-related routes
 func (h *HealthHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/health", h.Check)
+	r.Get("/livez", h.Live)
+	r.Get("/readyz", h.Ready)
 }
 
 // swagger:route GET /health health healthCheck
@@ -34,15 +81,69 @@ func (h *HealthHandler) RegisterRoutes(r chi.Router) {
 // # Health Check
 //
 // Returns the current health status of the service.
-// This endpoint is designed for Kubernetes/Docker health checks.
+// Kept for compatibility; same as /livez.
 //
 // Responses:
 //
 //	200: healthResponse
 func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: "healthy"})
+}
+
+// swagger:route GET /livez health liveness
+//
+// # Liveness Probe
+//
+// Succeeds while the process serves requests; a failure means it should be
+// restarted. Dependencies are not checked, so an outage of the statistics
+// backend does not restart every instance.
+//
+// Responses:
+//
+//	200: healthResponse
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: "alive"})
+}
+
+// swagger:route GET /readyz health readiness
+//
+// # Readiness Probe
+//
+// Succeeds when the service can take traffic: every dependency check passes
+// and no graceful shutdown is in progress. Otherwise answers 503 so load
+// balancers route requests to other instances.
+//
+// Responses:
+//
+//	200: readinessResponse
+//	503: readinessResponse
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.readiness.Check(r.Context())
+
+	resp := readinessResponse{Status: report.Status}
+	if len(report.Checks) > 0 {
+		resp.Checks = make(map[string]checkResponse, len(report.Checks))
+		for name, check := range report.Checks {
+			resp.Checks[name] = checkResponse{
+				Status:     check.Status,
+				DurationMs: float64(check.Duration.Microseconds()) / 1000,
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, status, resp)
+}
+
+func writeHealth(w http.ResponseWriter, status int, body any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(healthResponse{Status: "healthy"})
+	// Probes must see the current state, never a cached one
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
 }
 
 // swagger:response healthResponse
@@ -50,3 +151,9 @@ type healthResponseWrapper struct {
 	// in: body
 	Body healthResponse
 }
+
+// swagger:response readinessResponse
+type readinessResponseWrapper struct {
+	// in: body
+	Body readinessResponse
+}
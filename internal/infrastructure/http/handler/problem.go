@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+// swagger:response problemResponse
+type problemResponseWrapper struct {
+	// in: body
+	Body problem.Problem
+}
+
+// writeError responds with the problem err maps to in problems, logging
+// errors that are not expected
+func writeError(w http.ResponseWriter, r *http.Request, problems *problem.Registry, logger *slog.Logger, err error) {
+	p, ok := problems.Map(err)
+	if !ok {
+		logger.Error("unexpected error", "error", err, "path", r.URL.Path)
+	}
+	writeProblem(w, r, logger, p)
+}
+
+// writeProblem responds with p
+func writeProblem(w http.ResponseWriter, r *http.Request, logger *slog.Logger, p problem.Problem) {
+	if err := problem.Write(w, r, p); err != nil {
+		logger.Error("failed to encode response", "error", err)
+	}
+}
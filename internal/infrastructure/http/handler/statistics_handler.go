@@ -4,9 +4,14 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain"
 	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -14,6 +19,7 @@ import (
 // StatisticsHandler handles HTTP requests for statistics operations
 type StatisticsHandler struct {
 	getStatsUseCase *application.GetStatisticsUseCase
+	problems        *problem.Registry
 	logger          *slog.Logger
 }
 
@@ -36,6 +42,7 @@ func NewStatisticsHandler(
 ) *StatisticsHandler {
 	return &StatisticsHandler{
 		getStatsUseCase: getStatsUseCase,
+		problems:        problem.DefaultRegistry(),
 		logger:          logger,
 	}
 }
@@ -43,6 +50,25 @@ func NewStatisticsHandler(
 // RegisterRoutes registers all statistics-related routes
 func (h *StatisticsHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/statistics", h.GetMostFrequent)
+	r.Get("/statistics/top", h.GetTop)
+}
+
+// RegisterAdminRoutes registers the statistics of every client, to be
+// mounted with the admin routes
+func (h *StatisticsHandler) RegisterAdminRoutes(r chi.Router) {
+	r.Get("/admin/statistics/clients", h.GetClients)
+	r.Get("/admin/statistics/clients/{client}", h.GetClientTop)
+}
+
+// swagger:parameters getStatistics
+type statisticsParams struct {
+	// Only count hits of this last period (Go duration, 1m to 24h, e.g. 5m, 1h, 24h)
+	// in: query
+	Window string `json:"window"`
+	// Only count hits of the caller; the only accepted value is "me"
+	// (cannot be combined with window)
+	// in: query
+	Client string `json:"client"`
 }
 
 // swagger:route GET /statistics statistics getStatistics
@@ -51,24 +77,273 @@ func (h *StatisticsHandler) RegisterRoutes(r chi.Router) {
 //
 // Returns the most frequently requested FizzBuzz configuration and its hit count.
 // If no requests have been made yet, returns null for most_frequent_request and 0 hits.
+// With a window, only hits of that last period are counted. With client=me,
+// only hits of the caller (its API key, JWT subject or, when anonymous, its
+// address) are counted.
 //
 // Responses:
 //
 //	200: statisticsResponse
-//	500: errorResponse
+//	400: problemResponse
+//	500: problemResponse
 func (h *StatisticsHandler) GetMostFrequent(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.getStatsUseCase.Get(r.Context())
+	window, err := parseWindow(r.URL.Query().Get("window"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	client, err := parseClient(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	var stats *entity.StatisticsSummary
+	if client != "" {
+		stats, err = h.getStatsUseCase.GetForClient(r.Context(), client, window)
+	} else {
+		stats, err = h.getStatsUseCase.GetInWindow(r.Context(), window)
+	}
 	if err != nil {
-		h.logger.Error("failed to get statistics", "error", err)
-		h.writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
-		})
+		h.handleError(w, r, err)
 		return
 	}
 
 	h.writeJSON(w, http.StatusOK, stats)
 }
 
+// swagger:parameters getTopStatistics
+type topStatisticsParams struct {
+	// Page size (1 to 100)
+	// in: query
+	// minimum: 1
+	// maximum: 100
+	// default: 10
+	N int `json:"n"`
+	// Cursor returned as next_cursor by the previous page
+	// in: query
+	Cursor string `json:"cursor"`
+	// Only count hits of this last period (Go duration, 1m to 24h, e.g. 5m, 1h, 24h)
+	// in: query
+	Window string `json:"window"`
+	// Only count hits of the caller; the only accepted value is "me"
+	// (cannot be combined with window)
+	// in: query
+	Client string `json:"client"`
+}
+
+// swagger:route GET /statistics/top statistics getTopStatistics
+//
+// # Get Request Ranking
+//
+// Returns the most frequent requests, ranked by hits, then most recent hit,
+// then request key, so the order is deterministic under ties.
+// Pages are chained with the opaque next_cursor value; keep the same window
+// while paginating. With client=me, only hits of the caller are counted.
+//
+// Responses:
+//
+//	200: topStatisticsResponse
+//	400: problemResponse
+//	500: problemResponse
+func (h *StatisticsHandler) GetTop(w http.ResponseWriter, r *http.Request) {
+	client, err := parseClient(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	page, err := h.top(r, client)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// swagger:parameters getClientStatistics
+type clientStatisticsParams struct {
+	// Page size (1 to 100)
+	// in: query
+	// minimum: 1
+	// maximum: 100
+	// default: 10
+	N int `json:"n"`
+	// Cursor returned as next_cursor by the previous page
+	// in: query
+	Cursor string `json:"cursor"`
+}
+
+// swagger:route GET /admin/statistics/clients admin getClientStatistics
+//
+// # Get Client Ranking
+//
+// Returns the clients with the most hits, with the request each made most
+// often. Clients are ranked like requests (hits, then most recent hit, then
+// client ID) and paginated the same way. Anonymous clients are identified by
+// their address.
+//
+// Responses:
+//
+//	200: clientStatisticsResponse
+//	400: problemResponse
+//	500: problemResponse
+func (h *StatisticsHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	limit, err := parseLimit(params.Get("n"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	page, err := h.getStatsUseCase.Clients(r.Context(), application.ClientsRequest{
+		Limit:  limit,
+		Cursor: params.Get("cursor"),
+	})
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// swagger:parameters getClientTopStatistics
+type clientTopStatisticsParams struct {
+	// Client ID, as listed by getClientStatistics (URL-encoded)
+	// in: path
+	// required: true
+	Client string `json:"client"`
+	// Page size (1 to 100)
+	// in: query
+	// minimum: 1
+	// maximum: 100
+	// default: 10
+	N int `json:"n"`
+	// Cursor returned as next_cursor by the previous page
+	// in: query
+	Cursor string `json:"cursor"`
+}
+
+// swagger:route GET /admin/statistics/clients/{client} admin getClientTopStatistics
+//
+// # Get Request Ranking of a Client
+//
+// Returns the requests one client made most often, as getTopStatistics with
+// client=me would for that client. Clients without hits are not found.
+//
+// Responses:
+//
+//	200: topStatisticsResponse
+//	400: problemResponse
+//	404: problemResponse
+//	500: problemResponse
+func (h *StatisticsHandler) GetClientTop(w http.ResponseWriter, r *http.Request) {
+	client, err := url.PathUnescape(chi.URLParam(r, "client"))
+	if err != nil || client == "" {
+		h.handleError(w, r, domain.NewValidationError("invalid parameters",
+			domain.Invalid("client", domain.CodeInvalid, "client is invalid")))
+		return
+	}
+
+	page, err := h.top(r, client)
+	if err == nil && len(page.Entries) == 0 && r.URL.Query().Get("cursor") == "" {
+		err = domain.NotFoundError{Resource: "client " + client}
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// top returns the page of the ranking selected by the query string,
+// restricted to client unless empty
+func (h *StatisticsHandler) top(r *http.Request, client string) (*entity.TopStatistics, error) {
+	params := r.URL.Query()
+
+	limit, err := parseLimit(params.Get("n"))
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := parseWindow(params.Get("window"))
+	if err != nil {
+		return nil, err
+	}
+
+	return h.getStatsUseCase.Top(r.Context(), application.TopRequest{
+		Limit:  limit,
+		Cursor: params.Get("cursor"),
+		Window: window,
+		Client: client,
+	})
+}
+
+// swagger:response topStatisticsResponse
+type topStatisticsResponseWrapper struct {
+	// in: body
+	Body entity.TopStatistics
+}
+
+// swagger:response clientStatisticsResponse
+type clientStatisticsResponseWrapper struct {
+	// in: body
+	Body entity.ClientStatistics
+}
+
+// parseLimit parses the optional n parameter; empty means the default
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return application.DefaultTopLimit, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid parameters",
+			domain.Invalid("n", domain.CodeMustBeInteger, "n must be an integer"))
+	}
+	return n, nil
+}
+
+// parseClient resolves the optional client parameter to the ID of the
+// caller; empty means all clients
+// Other clients are only visible through the admin routes.
+func parseClient(r *http.Request) (string, error) {
+	switch r.URL.Query().Get("client") {
+	case "":
+		return "", nil
+	case "me":
+		return application.CallerFromContext(r.Context()).ID, nil
+	default:
+		return "", domain.NewValidationError("invalid parameters",
+			domain.Invalid("client", domain.CodeInvalid, "client must be me, other clients are listed under /admin/statistics/clients"))
+	}
+}
+
+// parseWindow parses the optional window parameter; empty means all time
+func parseWindow(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	window, err := time.ParseDuration(raw)
+	if err != nil || window <= 0 {
+		return 0, domain.NewValidationError("invalid parameters",
+			domain.Invalid("window", domain.CodeInvalidFormat, "window must be a duration such as 5m, 1h or 24h"))
+	}
+	return window, nil
+}
+
+// handleError maps domain errors to HTTP responses
+func (h *StatisticsHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, h.problems, h.logger, err)
+}
+
 // swagger:response statisticsResponse
 type statisticsResponseWrapper struct {
 	// in: body
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+// authRealm is announced in WWW-Authenticate challenges
+const authRealm = "fizzbuzz-service"
+
+// AuthenticationMiddleware resolves the credentials of each request to a
+// principal, stored in the request context
+// Requests without credentials proceed anonymously and rejected credentials
+// are recorded; RequireScope decides whether the route allows either, so
+// refused requests are still logged and counted. A nil authn disables it.
+func AuthenticationMiddleware(authn auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if authn == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := Credential(r)
+			if credential == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			if p, err := authn.Authenticate(ctx, credential); err != nil {
+				ctx = auth.WithError(ctx, err)
+			} else {
+				ctx = auth.NewContext(ctx, p)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Credential returns the credential sent in the X-API-Key header or as an
+// Authorization Bearer token
+func Credential(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// RequireScope lets through authenticated principals granted scope
+// Others get 401 Unauthorized (missing or invalid credentials) or 403
+// Forbidden (insufficient scope), with an RFC 6750 WWW-Authenticate challenge.
+func RequireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := auth.ErrorFromContext(r.Context()); err != nil {
+				description := describeAuthError(err)
+				challenge(w, r, problem.Unauthorized, `error="invalid_token", error_description="`+description+`"`, description)
+				return
+			}
+
+			p, ok := auth.FromContext(r.Context())
+			if !ok {
+				challenge(w, r, problem.Unauthorized, "", "authentication required")
+				return
+			}
+			if !p.HasScope(scope) {
+				challenge(w, r, problem.Forbidden, `error="insufficient_scope", scope="`+string(scope)+`"`, "missing scope "+string(scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// describeAuthError explains why credentials were rejected, e.g. "token is
+// expired", as an RFC 6750 error_description: printable ASCII without quotes
+// or backslashes
+func describeAuthError(err error) string {
+	if !errors.Is(err, auth.ErrInvalidCredentials) {
+		return "credentials could not be verified"
+	}
+	description, ok := strings.CutPrefix(err.Error(), auth.ErrInvalidCredentials.Error()+": ")
+	if !ok {
+		return auth.ErrInvalidCredentials.Error()
+	}
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '\''
+		}
+		return r
+	}, description)
+}
+
+// challenge refuses a request with a Bearer challenge (RFC 6750 section 3)
+func challenge(w http.ResponseWriter, r *http.Request, t problem.Type, params, detail string) {
+	value := `Bearer realm="` + authRealm + `"`
+	if params != "" {
+		value += ", " + params
+	}
+	w.Header().Set("WWW-Authenticate", value)
+	problem.Write(w, r, t.New(detail))
+}
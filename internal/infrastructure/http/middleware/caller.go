@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/auth"
+)
+
+// CallerMiddleware attributes each request to a client, so statistics can be
+// kept per client
+// Authenticated requests are attributed to their principal, as
+// "<method>:<id>" (e.g. "api_key:ci", "jwt:alice"), anonymous ones to the
+// client address, as "ip:<address>". Install it after
+// AuthenticationMiddleware and chi's RealIP.
+func CallerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := application.WithCaller(r.Context(), Caller(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Caller returns the client a request is attributed to
+func Caller(r *http.Request) entity.Caller {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return entity.Caller{ID: p.Method + ":" + p.ID}
+	}
+	return entity.Caller{ID: "ip:" + clientIP(r)}
+}
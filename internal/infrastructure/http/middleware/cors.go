@@ -2,28 +2,95 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// CORSConfig is the cross-origin policy applied by CORSMiddleware
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the API; "*" allows any
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers cache preflight responses
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig allows any origin, which is convenient for development
+// and testing with tools like Swagger Editor
+// In production, you might want to restrict this to specific domains.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "X-Requested-With"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+}
+
+// CORS holds the live cross-origin policy, which can be replaced while
+// requests are served
+type CORS struct {
+	policy atomic.Pointer[corsPolicy]
+}
+
+// corsPolicy is a CORSConfig with its headers precomputed
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     []string
+	methods     string
+	headers     string
+	credentials bool
+	maxAge      string
+}
+
+// NewCORS creates a policy from config
+func NewCORS(config CORSConfig) *CORS {
+	c := &CORS{}
+	c.Update(config)
+	return c
+}
+
+// Update atomically replaces the policy; in-flight requests keep the old one
+func (c *CORS) Update(config CORSConfig) {
+	c.policy.Store(&corsPolicy{
+		anyOrigin:   slices.Contains(config.AllowedOrigins, "*"),
+		origins:     slices.Clone(config.AllowedOrigins),
+		methods:     strings.Join(config.AllowedMethods, ", "),
+		headers:     strings.Join(config.AllowedHeaders, ", "),
+		credentials: config.AllowCredentials,
+		maxAge:      strconv.Itoa(int(config.MaxAge.Seconds())),
+	})
+}
+
 // CORSMiddleware adds CORS headers to allow cross-origin requests
-// This is particularly useful for development and testing with tools like Swagger Editor
-func CORSMiddleware() func(http.Handler) http.Handler {
+// When origins are restricted, the request Origin is echoed back only if allowed.
+func CORSMiddleware(cors *CORS) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Allow requests from any origin (for development/testing)
-			// In production, you might want to restrict this to specific domains
-			w.Header().Set("Access-Control-Allow-Origin", "*")
+			policy := cors.policy.Load()
 
-			// Allow common HTTP methods
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-			// Allow common headers
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-
-			// Allow credentials (cookies, authorization headers)
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			if policy.anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				// The answer depends on the Origin, so caches must key on it
+				w.Header().Add("Vary", "Origin")
+				if origin := r.Header.Get("Origin"); origin != "" && slices.Contains(policy.origins, origin) {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
 
-			// Cache preflight requests for 1 hour
-			w.Header().Set("Access-Control-Max-Age", "3600")
+			w.Header().Set("Access-Control-Allow-Methods", policy.methods)
+			w.Header().Set("Access-Control-Allow-Headers", policy.headers)
+			if policy.credentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			w.Header().Set("Access-Control-Max-Age", policy.maxAge)
 
 			// Handle preflight OPTIONS requests
 			if r.Method == "OPTIONS" {
@@ -6,33 +6,96 @@ import (
 	"net/http"
 	"time"
 
+	"fizzbuzz-service/internal/infrastructure/auth"
+
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+// RequestObserver is notified of every completed request (e.g. metrics)
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// LoggingOption customizes the logging middleware
+type LoggingOption func(*loggingConfig)
+
+type loggingConfig struct {
+	observers []RequestObserver
+}
+
+// WithRequestObserver reports completed requests to o
+func WithRequestObserver(o RequestObserver) LoggingOption {
+	return func(c *loggingConfig) {
+		c.observers = append(c.observers, o)
+	}
+}
+
+// unmatchedRoute labels requests that matched no route, to bound cardinality
+const unmatchedRoute = "unmatched"
+
+func LoggingMiddleware(logger *slog.Logger, opts ...LoggingOption) func(http.Handler) http.Handler {
+	var config loggingConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
 			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
-			next.ServeHTTP(wrapped, r)
+			// Deferred so requests that panic are logged and counted too, as
+			// the 500 the recovery middleware answers; the panic is left to it
+			completed := false
+			defer func() {
+				if !completed {
+					wrapped.statusCode = http.StatusInternalServerError
+				}
+				logRequest(logger, config, wrapped, r, time.Since(start))
+			}()
 
-			// Get request ID from Chi's middleware
-			requestID := middleware.GetReqID(r.Context())
-
-			logger.Info("request completed",
-				"request_id", requestID,
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", wrapped.statusCode,
-				"duration_ms", time.Since(start).Milliseconds(),
-				"remote_addr", r.RemoteAddr,
-			)
+			next.ServeHTTP(wrapped, r)
+			completed = true
 		})
 	}
 }
 
+// logRequest logs a completed request and reports it to the observers
+func logRequest(logger *slog.Logger, config loggingConfig, wrapped *responseWriter, r *http.Request, duration time.Duration) {
+	// Get request ID from Chi's middleware
+	requestID := middleware.GetReqID(r.Context())
+
+	attrs := []any{
+		"request_id", requestID,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", wrapped.statusCode,
+		"duration_ms", duration.Milliseconds(),
+		"remote_addr", r.RemoteAddr,
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		attrs = append(attrs, "principal", p.ID)
+	}
+	// Correlates the log line with the request's trace, when traced
+	if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+		attrs = append(attrs, "trace_id", sc.TraceID().String())
+	}
+	logger.Info("request completed", attrs...)
+
+	if len(config.observers) > 0 {
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		for _, o := range config.observers {
+			o.ObserveRequest(r.Method, route, wrapped.statusCode, duration)
+		}
+	}
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -42,3 +105,8 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.statusCode = statusCode
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController (flush, deadlines)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
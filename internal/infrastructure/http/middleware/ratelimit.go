@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/clock"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+// DefaultIdleTTL is how long the bucket of an inactive client is kept
+const DefaultIdleTTL = 10 * time.Minute
+
+// maxCostPeek bounds the part of a request body read to find its limit
+const maxCostPeek = 64 << 10
+
+// RateLimit is the token bucket of each client
+type RateLimit struct {
+	// Rate is the number of tokens added per second; 0 disables limiting
+	Rate float64
+	// Burst is the bucket capacity, i.e. the tokens a client can spend at once
+	Burst int
+	// CostUnit, when positive, makes requests cost one extra token per
+	// CostUnit of the numbers they request, so huge sequences weigh more
+	CostUnit int
+}
+
+// Enabled reports whether requests are limited at all
+func (l RateLimit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// KeyFunc identifies the client a request is charged to
+type KeyFunc func(r *http.Request) string
+
+// RateLimiter keeps a token bucket per client
+// Buckets idle long enough to be full again are forgotten.
+type RateLimiter struct {
+	limit   atomic.Pointer[RateLimit]
+	key     KeyFunc
+	clock   clock.Clock
+	idleTTL time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimitOption customizes the rate limiter
+type RateLimitOption func(*RateLimiter)
+
+// WithKeyFunc charges requests to the client returned by key
+// Defaults to ClientKey
+func WithKeyFunc(key KeyFunc) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.key = key
+	}
+}
+
+// WithRateLimitClock sets the time source (defaults to the wall clock)
+func WithRateLimitClock(c clock.Clock) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.clock = c
+	}
+}
+
+// WithIdleTTL forgets buckets unused for d
+// Defaults to DefaultIdleTTL; buckets are kept at least until they are full.
+func WithIdleTTL(d time.Duration) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.idleTTL = d
+	}
+}
+
+// NewRateLimiter creates a limiter applying limit to each client
+func NewRateLimiter(limit RateLimit, opts ...RateLimitOption) *RateLimiter {
+	l := &RateLimiter{
+		key:     ClientKey,
+		clock:   clock.System{},
+		idleTTL: DefaultIdleTTL,
+		buckets: make(map[string]*bucket),
+	}
+	l.limit.Store(&limit)
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Update atomically replaces the limit; existing buckets keep their tokens,
+// capped to the new burst
+func (l *RateLimiter) Update(limit RateLimit) {
+	l.limit.Store(&limit)
+}
+
+// Limit returns the limit in effect
+func (l *RateLimiter) Limit() RateLimit {
+	return *l.limit.Load()
+}
+
+// Len returns the number of clients tracked
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// Decision is the outcome of charging a request
+type Decision struct {
+	Allowed bool
+	Limit   RateLimit
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is how long to wait before the request can be allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Allow charges cost tokens to key, if available
+// Costs beyond the burst are capped to it, so any request can eventually pass.
+func (l *RateLimiter) Allow(key string, cost float64) Decision {
+	limit := l.Limit()
+	if !limit.Enabled() {
+		return Decision{Allowed: true, Limit: limit}
+	}
+	burst := float64(limit.Burst)
+	cost = min(max(cost, 1), burst)
+
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now, limit)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	d := Decision{Limit: limit}
+	if b.tokens >= cost {
+		b.tokens -= cost
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((cost - b.tokens) / limit.Rate)
+	}
+	d.Remaining = int(math.Floor(b.tokens))
+	d.Reset = seconds((burst - b.tokens) / limit.Rate)
+	return d
+}
+
+// sweep drops idle buckets, at most once per idle TTL
+func (l *RateLimiter) sweep(now time.Time, limit RateLimit) {
+	if now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	l.lastSweep = now
+
+	// A bucket refilled to its burst is the same as a new one
+	ttl := max(l.idleTTL, seconds(float64(limit.Burst)/limit.Rate))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= ttl {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RateLimitMiddleware answers 429 Too Many Requests once a client has spent
+// its tokens, and reports its quota in RateLimit-* headers
+func RateLimitMiddleware(l *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := l.Limit()
+			if !limit.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cost := 1.0
+			if limit.CostUnit > 0 {
+				cost += float64(requestSize(r)) / float64(limit.CostUnit)
+				cost = math.Floor(cost)
+			}
+			d := l.Allow(l.key(r), cost)
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.Limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+			h.Set("RateLimit-Policy", strconv.Itoa(d.Limit.Burst)+";w="+strconv.Itoa(ceilSeconds(seconds(float64(d.Limit.Burst)/d.Limit.Rate))))
+
+			if !d.Allowed {
+				retryAfter := strconv.Itoa(max(1, ceilSeconds(d.RetryAfter)))
+				h.Set("Retry-After", retryAfter)
+				problem.Write(w, r, problem.RateLimited.New("retry after "+retryAfter+" seconds"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ClientKey identifies clients as their statistics do (see Caller): by
+// authentication method and principal, by IP address otherwise
+// The method keeps an API key and a JWT subject with the same ID apart.
+// Unverified credentials are ignored, so made-up keys do not get fresh buckets.
+func ClientKey(r *http.Request) string {
+	return Caller(r).ID
+}
+
+// clientIP is the address set by chi's RealIP middleware, without port
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// requestSize reads the number of values a request asks for, without
+// consuming its body
+// It is the limit, or the part of it covered by the offset and count of a
+// page. GET requests are read from their query string, others from their
+// JSON body only, as the handlers do: a query string cannot lower the cost
+// of a POST.
+func requestSize(r *http.Request) int {
+	var params struct {
+		Limit  int  `json:"limit"`
+		Offset *int `json:"offset"`
+		Count  *int `json:"count"`
+	}
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		params.Limit, _ = strconv.Atoi(query.Get("limit"))
+		if query.Has("offset") {
+			offset, _ := strconv.Atoi(query.Get("offset"))
+			params.Offset = &offset
+		}
+		if query.Has("count") {
+			count, _ := strconv.Atoi(query.Get("count"))
+			params.Count = &count
+		}
+	case r.Body == nil || r.Body == http.NoBody:
+		return 0
+	default:
+		peeked, err := io.ReadAll(io.LimitReader(r.Body, maxCostPeek))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+		if err != nil {
+			return 0
+		}
+		if len(peeked) == maxCostPeek {
+			// Padding a body must not hide its limit: charge the maximum
+			return math.MaxInt
+		}
+		// Malformed bodies are rejected by the handler; they cost the base token
+		if json.Unmarshal(peeked, &params) != nil {
+			return 0
+		}
+	}
+
+	size := params.Limit
+	if params.Offset != nil {
+		size -= max(*params.Offset, 0)
+	}
+	if params.Count != nil {
+		size = min(size, *params.Count)
+	}
+	return max(size, 0)
+}
@@ -2,19 +2,50 @@
 package middleware
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
 
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+// PanicObserver is notified of every recovered panic (e.g. metrics)
+type PanicObserver interface {
+	PanicRecovered()
+}
+
+// RecoveryOption customizes the recovery middleware
+type RecoveryOption func(*recoveryConfig)
+
+type recoveryConfig struct {
+	observers []PanicObserver
+}
+
+// WithPanicObserver reports recovered panics to o
+func WithPanicObserver(o PanicObserver) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.observers = append(c.observers, o)
+	}
+}
+
+func RecoveryMiddleware(logger *slog.Logger, opts ...RecoveryOption) func(http.Handler) http.Handler {
+	var config recoveryConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tracked := &writeTracker{ResponseWriter: w}
 			defer func() {
 				if rec := recover(); rec != nil {
+					// Deliberate aborts are for net/http to handle
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+
 					requestID := middleware.GetReqID(r.Context())
 
 					logger.Error("panic recovered",
@@ -24,17 +55,46 @@ func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 						"path", r.URL.Path,
 						"method", r.Method,
 					)
+					for _, o := range config.observers {
+						o.PanicRecovered()
+					}
 
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-					json.NewEncoder(w).Encode(map[string]string{
-						"error":      "internal server error",
-						"request_id": requestID,
-					})
+					// A problem appended to a response already started (e.g. a
+					// stream) would corrupt it; the client sees it cut instead
+					if !tracked.written {
+						problem.Write(w, r, problem.Internal.New(""))
+					}
 				}
 			}()
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(tracked, r)
 		})
 	}
 }
+
+// writeTracker records whether the response was started
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (t *writeTracker) WriteHeader(statusCode int) {
+	t.written = true
+	t.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (t *writeTracker) Write(b []byte) (int, error) {
+	t.written = true
+	return t.ResponseWriter.Write(b)
+}
+
+// FlushError sends the headers, if not yet sent, and buffered data
+func (t *writeTracker) FlushError() error {
+	t.written = true
+	return http.NewResponseController(t.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController (deadlines)
+func (t *writeTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of this package
+const tracerName = "fizzbuzz-service/internal/infrastructure/http/middleware"
+
+// TracingMiddleware opens a server span for every request
+// The span continues the trace of the W3C traceparent header, when present, and
+// is named after the matched route pattern so span names stay bounded.
+func TracingMiddleware(tp trace.TracerProvider) func(http.Handler) http.Handler {
+	tracer := tp.Tracer(tracerName)
+	propagator := propagation.TraceContext{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+					attribute.String("request.id", middleware.GetReqID(r.Context())),
+				),
+			)
+			defer span.End()
+
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", wrapped.statusCode),
+			)
+			// Client errors are the client's; only server errors fail the span
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", wrapped.statusCode))
+			}
+		})
+	}
+}
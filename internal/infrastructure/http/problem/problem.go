@@ -0,0 +1,110 @@
+// Package problem renders errors as RFC 7807 problem details
+// (application/problem+json), the single error format of the API.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object
+// swagger:model problem
+type Problem struct {
+	// URI reference identifying the kind of problem
+	// required: true
+	// example: /problems/invalid-parameters
+	Type string `json:"type"`
+	// Short summary of the kind of problem, the same for every occurrence
+	// required: true
+	// example: Invalid parameters
+	Title string `json:"title"`
+	// HTTP status code
+	// required: true
+	// example: 400
+	Status int `json:"status"`
+	// Explanation specific to this occurrence
+	// required: false
+	// example: limit must be greater than 0
+	Detail string `json:"detail,omitempty"`
+	// Path of the request the problem occurred on
+	// required: false
+	// example: /fizzbuzz
+	Instance string `json:"instance,omitempty"`
+	// ID of the request, as in the X-Request-Id header and the logs
+	// required: false
+	RequestID string `json:"request_id,omitempty"`
+	// Parameters that failed validation
+	// required: false
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam is a parameter rejected by validation
+// swagger:model invalidParam
+type InvalidParam struct {
+	// Parameter name, e.g. limit or rules[1].divisor; empty when the
+	// reason is not about a single parameter
+	// required: false
+	// example: limit
+	Name string `json:"name,omitempty"`
+	// Machine-readable reason, e.g. must_be_positive, exceeds_max or required
+	// required: false
+	// example: exceeds_max
+	Code string `json:"code,omitempty"`
+	// Why the parameter was rejected, in English
+	// required: true
+	// example: limit exceeds maximum allowed value of 100000
+	Reason string `json:"reason"`
+	// Parameters of the violated constraint, e.g. {"max": 100000}
+	// required: false
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// Type is a kind of problem
+type Type struct {
+	URI    string
+	Title  string
+	Status int
+}
+
+// Kinds of problems returned by the API
+var (
+	InvalidParameters    = Type{"/problems/invalid-parameters", "Invalid parameters", http.StatusBadRequest}
+	MalformedRequest     = Type{"/problems/malformed-request", "Malformed request", http.StatusBadRequest}
+	Unauthorized         = Type{"/problems/unauthorized", "Unauthorized", http.StatusUnauthorized}
+	Forbidden            = Type{"/problems/forbidden", "Forbidden", http.StatusForbidden}
+	NotFound             = Type{"/problems/not-found", "Not found", http.StatusNotFound}
+	MethodNotAllowed     = Type{"/problems/method-not-allowed", "Method not allowed", http.StatusMethodNotAllowed}
+	NotAcceptable        = Type{"/problems/not-acceptable", "Not acceptable", http.StatusNotAcceptable}
+	PayloadTooLarge      = Type{"/problems/payload-too-large", "Payload too large", http.StatusRequestEntityTooLarge}
+	UnsupportedMediaType = Type{"/problems/unsupported-media-type", "Unsupported media type", http.StatusUnsupportedMediaType}
+	InvalidConfiguration = Type{"/problems/invalid-configuration", "Invalid configuration", http.StatusUnprocessableEntity}
+	RateLimited          = Type{"/problems/rate-limited", "Rate limit exceeded", http.StatusTooManyRequests}
+	Internal             = Type{"/problems/internal", "Internal server error", http.StatusInternalServerError}
+)
+
+// New returns a problem of kind t
+func (t Type) New(detail string) Problem {
+	return Problem{Type: t.URI, Title: t.Title, Status: t.Status, Detail: detail}
+}
+
+// Write responds with p
+// Instance defaults to the request path and the request ID is taken from
+// chi's RequestID middleware.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) error {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = middleware.GetReqID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,86 @@
+package problem
+
+import (
+	"errors"
+	"strings"
+
+	"fizzbuzz-service/internal/domain"
+)
+
+// Mapper converts errors of one kind to a problem; ok is false for errors
+// of other kinds
+type Mapper func(err error) (p Problem, ok bool)
+
+// Registry maps errors to problems
+// Mappers are tried in registration order; the first to accept an error wins.
+type Registry struct {
+	mappers []Mapper
+}
+
+// NewRegistry creates a registry with the given mappers
+func NewRegistry(mappers ...Mapper) *Registry {
+	r := &Registry{}
+	for _, m := range mappers {
+		r.Register(m)
+	}
+	return r
+}
+
+// DefaultRegistry returns a registry mapping every domain error
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		FromValidationError,
+		FromNotFoundError,
+	)
+}
+
+// Register adds a mapper, tried after those already registered
+func (r *Registry) Register(m Mapper) {
+	r.mappers = append(r.mappers, m)
+}
+
+// Map returns the problem of err
+// Unknown errors are internal errors, whose detail is not disclosed; ok is
+// false for them, so callers can log the cause.
+func (r *Registry) Map(err error) (p Problem, ok bool) {
+	for _, m := range r.mappers {
+		if p, ok := m(err); ok {
+			return p, true
+		}
+	}
+	return Internal.New(""), false
+}
+
+// FromValidationError maps domain.ValidationError to InvalidParameters,
+// with one invalid parameter per violation
+func FromValidationError(err error) (Problem, bool) {
+	var validationErr domain.ValidationError
+	if !errors.As(err, &validationErr) {
+		return Problem{}, false
+	}
+
+	p := InvalidParameters.New(validationErr.Message)
+	reasons := make([]string, 0, len(validationErr.Violations))
+	for _, v := range validationErr.Violations {
+		reasons = append(reasons, v.Message)
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{
+			Name:   v.Field,
+			Code:   v.Code,
+			Reason: v.Message,
+			Params: v.Params,
+		})
+	}
+	if len(reasons) > 0 {
+		p.Detail = strings.Join(reasons, "; ")
+	}
+	return p, true
+}
+
+// FromNotFoundError maps domain.NotFoundError to NotFound
+func FromNotFoundError(err error) (Problem, bool) {
+	var notFoundErr domain.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return Problem{}, false
+	}
+	return NotFound.New(notFoundErr.Error()), true
+}
@@ -1,38 +1,208 @@
 package http
 
 import (
+	"fizzbuzz-service/internal/infrastructure/auth"
 	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+	"fizzbuzz-service/internal/infrastructure/metrics"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	custommw "fizzbuzz-service/internal/infrastructure/http/middleware"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// RouterOption customizes the router beyond its required handlers
+type RouterOption func(*routerConfig)
+
+type routerConfig struct {
+	tracing        trace.TracerProvider
+	requestTimeout time.Duration
+	cors           *custommw.CORS
+	recovery       []custommw.RecoveryOption
+	logging        []custommw.LoggingOption
+	routes         []route
+	admin          *handler.AdminHandler
+	rateLimits     RateLimiters
+	authn          auth.Authenticator
+}
+
+// RateLimiters throttle each group of routes; nil leaves a group unlimited
+type RateLimiters struct {
+	// Generate limits GET and POST /fizzbuzz
+	Generate *custommw.RateLimiter
+	// Stream limits POST /fizzbuzz/stream
+	Stream *custommw.RateLimiter
+	// Statistics limits the statistics endpoints
+	Statistics *custommw.RateLimiter
+}
+
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+// WithMetrics records request and panic metrics
+func WithMetrics(m *metrics.HTTPMetrics) RouterOption {
+	return func(c *routerConfig) {
+		c.recovery = append(c.recovery, custommw.WithPanicObserver(m))
+		c.logging = append(c.logging, custommw.WithRequestObserver(m))
+	}
+}
+
+// WithTracing opens a server span per request, continuing incoming W3C traces
+func WithTracing(tp trace.TracerProvider) RouterOption {
+	return func(c *routerConfig) {
+		c.tracing = tp
+	}
+}
+
+// WithRequestTimeout bounds each non-streaming request
+// Defaults to 30s
+func WithRequestTimeout(d time.Duration) RouterOption {
+	return func(c *routerConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithCORS applies cors, whose policy can be updated later
+// Defaults to custommw.DefaultCORSConfig()
+func WithCORS(cors *custommw.CORS) RouterOption {
+	return func(c *routerConfig) {
+		c.cors = cors
+	}
+}
+
+// WithAdmin serves the admin routes (e.g. configuration reload) on the API
+// port, for deployments without a separate admin port
+// They are only mounted along with WithAuthentication: the API port is
+// public, so anonymous callers must never reach them.
+func WithAdmin(h *handler.AdminHandler) RouterOption {
+	return func(c *routerConfig) {
+		c.admin = h
+	}
+}
+
+// WithRateLimits throttles clients per group of routes
+// Health, metrics and admin routes are never limited.
+func WithRateLimits(limiters RateLimiters) RouterOption {
+	return func(c *routerConfig) {
+		c.rateLimits = limiters
+	}
+}
+
+// WithAuthentication requires credentials accepted by authn, with the scope
+// of each group of routes: fizzbuzz:generate for generation, stats:read for
+// statistics, admin for admin routes
+// Health and metrics routes stay anonymous. Without this option, every route is.
+func WithAuthentication(authn auth.Authenticator) RouterOption {
+	return func(c *routerConfig) {
+		c.authn = authn
+	}
+}
+
+// WithHandler serves handler on pattern, outside the request timeout
+// (e.g. the metrics endpoint when no admin port is configured)
+func WithHandler(pattern string, handler http.Handler) RouterOption {
+	return func(c *routerConfig) {
+		c.routes = append(c.routes, route{pattern, handler})
+	}
+}
+
 func NewRouter(
 	fizzBuzzHandler *handler.FizzBuzzHandler,
 	statsHandler *handler.StatisticsHandler,
 	healthHandler *handler.HealthHandler,
 	logger *slog.Logger,
-
+	opts ...RouterOption,
 ) http.Handler {
+	config := routerConfig{
+		tracing:        noop.NewTracerProvider(),
+		requestTimeout: 30 * time.Second,
+		cors:           custommw.NewCORS(custommw.DefaultCORSConfig()),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	r := chi.NewRouter()
+	r.NotFound(notFound)
+	r.MethodNotAllowed(methodNotAllowed(r))
 
 	// Middleware stack (top = outermost, executes first)
-	r.Use(middleware.RequestID)                 // Chi: inject X-Request-Id
-	r.Use(middleware.RealIP)                    // Chi: get real IP
-	r.Use(custommw.CORSMiddleware())            // Custom: CORS headers for cross-origin requests
-	r.Use(custommw.RecoveryMiddleware(logger))  // Custom: slog + JSON response
-	r.Use(custommw.LoggingMiddleware(logger))   // Custom: slog structured logging
-	r.Use(middleware.Timeout(30 * time.Second)) // Chi: request timeout
-
-	// Register routes
-	fizzBuzzHandler.RegisterRoutes(r)
-	statsHandler.RegisterRoutes(r)
-	healthHandler.RegisterRoutes(r)
+	r.Use(middleware.RequestID)                                    // Chi: inject X-Request-Id
+	r.Use(middleware.RealIP)                                       // Chi: get real IP
+	r.Use(custommw.TracingMiddleware(config.tracing))              // Custom: W3C trace context + server span
+	r.Use(custommw.CORSMiddleware(config.cors))                    // Custom: CORS headers for cross-origin requests
+	r.Use(custommw.RecoveryMiddleware(logger, config.recovery...)) // Custom: slog + JSON response
+	r.Use(custommw.AuthenticationMiddleware(config.authn))         // Custom: principal in context (no-op without authentication)
+	r.Use(custommw.CallerMiddleware)                               // Custom: client the request's statistics are attributed to
+	r.Use(custommw.LoggingMiddleware(logger, config.logging...))   // Custom: slog structured logging
+
+	// Streaming routes are bounded by client disconnection, not by the request timeout
+	fizzBuzzHandler.RegisterStreamRoutes(config.protect(r, auth.ScopeGenerate, config.rateLimits.Stream))
+
+	for _, route := range config.routes {
+		r.Handle(route.pattern, route.handler)
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(config.requestTimeout)) // Chi: request timeout
+
+		// Register routes
+		fizzBuzzHandler.RegisterRoutes(config.protect(r, auth.ScopeGenerate, config.rateLimits.Generate))
+		statsHandler.RegisterRoutes(config.protect(r, auth.ScopeStatsRead, config.rateLimits.Statistics))
+		healthHandler.RegisterRoutes(r)
+		if config.admin != nil && config.authn != nil {
+			admin := config.protect(r, auth.ScopeAdmin, nil)
+			config.admin.RegisterRoutes(admin)
+			statsHandler.RegisterAdminRoutes(admin)
+		}
+	})
 
 	return r
 }
+
+// protect requires scope (when authentication is enabled), then applies
+// limiter (when not nil) to the routes registered on the returned router
+// Scopes are checked first, so refused requests do not spend tokens.
+func (c *routerConfig) protect(r chi.Router, scope auth.Scope, limiter *custommw.RateLimiter) chi.Router {
+	var mws []func(http.Handler) http.Handler
+	if c.authn != nil {
+		mws = append(mws, custommw.RequireScope(scope))
+	}
+	if limiter != nil {
+		mws = append(mws, custommw.RateLimitMiddleware(limiter))
+	}
+	if len(mws) == 0 {
+		return r
+	}
+	return r.With(mws...)
+}
+
+// notFound answers requests for unknown routes
+func notFound(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.NotFound.New("no route matches "+r.URL.Path))
+}
+
+// methodNotAllowed answers requests for known routes with another method,
+// listing the allowed ones
+func methodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	methods := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range methods {
+			if routes.Match(chi.NewRouteContext(), method, r.URL.Path) {
+				allowed = append(allowed, method)
+			}
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		problem.Write(w, r, problem.MethodNotAllowed.New(r.Method+" is not allowed on "+r.URL.Path))
+	}
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import "fizzbuzz-service/internal/application"
+
+// RegisterResultCache exposes the size and efficiency of the result cache
+func (r *Registry) RegisterResultCache(c *application.ResultCache) {
+	r.NewGaugeFunc("result_cache_entries", "Number of sequences in the result cache.",
+		func() float64 { return float64(c.Stats().Entries) })
+	r.NewGaugeFunc("result_cache_bytes", "Estimated size of the sequences in the result cache.",
+		func() float64 { return float64(c.Stats().Bytes) })
+	r.NewGaugeFunc("result_cache_max_bytes", "Maximum size of the sequences in the result cache.",
+		func() float64 { return float64(c.Stats().MaxBytes) })
+	r.NewCounterFunc("result_cache_hits_total", "Number of sequences served from the result cache.",
+		func() float64 { return float64(c.Stats().Hits) })
+	r.NewCounterFunc("result_cache_misses_total", "Number of sequences generated on a result cache miss.",
+		func() float64 { return float64(c.Stats().Misses) })
+	r.NewCounterFunc("result_cache_shared_total", "Number of misses served by a concurrent generation of the same sequence.",
+		func() float64 { return float64(c.Stats().Shared) })
+	r.NewCounterFunc("result_cache_evictions_total", "Number of sequences evicted from the result cache.",
+		func() float64 { return float64(c.Stats().Evictions) })
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// HTTPMetrics records what the HTTP layer observes
+// It implements the observer interfaces of the middleware and handler packages.
+type HTTPMetrics struct {
+	requests       *CounterVec
+	duration       *HistogramVec
+	panics         *Counter
+	sequenceLength *Histogram
+}
+
+// NewHTTPMetrics registers the HTTP metrics
+func NewHTTPMetrics(r *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: r.NewCounterVec("http_requests_total",
+			"Number of HTTP requests by route and status.", "method", "route", "status"),
+		duration: r.NewHistogramVec("http_request_duration_seconds",
+			"Duration of HTTP requests by route and status.", DefBuckets, "method", "route", "status"),
+		panics: r.NewCounter("http_panics_recovered_total",
+			"Number of handler panics recovered."),
+		sequenceLength: r.NewHistogram("fizzbuzz_sequence_length",
+			"Length of the generated FizzBuzz sequences.", ExponentialBuckets(1, 10, 9)),
+	}
+}
+
+// ObserveRequest counts a completed request
+// route is the matched route pattern, so unknown paths share one series.
+func (m *HTTPMetrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.With(method, route, code).Inc()
+	m.duration.With(method, route, code).Observe(duration.Seconds())
+}
+
+// PanicRecovered counts a recovered panic
+func (m *HTTPMetrics) PanicRecovered() {
+	m.panics.Inc()
+}
+
+// ObserveSequenceLength records the length of a generated sequence
+func (m *HTTPMetrics) ObserveSequenceLength(length int) {
+	m.sequenceLength.Observe(float64(length))
+}
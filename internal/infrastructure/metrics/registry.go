@@ -0,0 +1,154 @@
+// Package metrics exposes application metrics in the Prometheus text format.
+//
+// It implements the small subset needed by the service (counters, gauges and
+// histograms with labels, plus values computed at scrape time) so that no
+// client library or remote collector is required.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry holds the metric families exposed on scrape
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+// family is one named metric with all its label combinations
+type family interface {
+	name() string
+	write(b *bytes.Buffer)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[f.name()] {
+		panic(fmt.Sprintf("metrics: %s registered twice", f.name()))
+	}
+	r.names[f.name()] = true
+	r.families = append(r.families, f)
+}
+
+// Write renders every family, sorted by name
+func (r *Registry) Write(b *bytes.Buffer) {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+	for _, f := range families {
+		f.write(b)
+	}
+}
+
+// Handler serves the registry in the text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var b bytes.Buffer
+		r.Write(&b)
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(b.Bytes())
+	})
+}
+
+// desc is the metadata shared by every kind of family
+type desc struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+}
+
+func (d desc) name() string { return d.metricName }
+
+func (d desc) writeHeader(b *bytes.Buffer) {
+	fmt.Fprintf(b, "# HELP %s %s\n", d.metricName, escapeHelp(d.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", d.metricName, d.kind)
+}
+
+// series is the values of one label combination, in label order
+type series struct {
+	values []string
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// writeSample writes one sample line; extra is an additional label (e.g. le)
+func writeSample(b *bytes.Buffer, name string, labels, values []string, extraName, extraValue string, value float64) {
+	b.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, `%s="%s"`, label, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, `%s="%s"`, extraName, escapeLabel(extraValue))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// sortedKeys returns the series keys in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// runtimeCollector reports Go runtime statistics, read once per scrape
+type runtimeCollector struct{}
+
+// RegisterRuntimeMetrics adds goroutine, memory and GC metrics (go_*)
+func (r *Registry) RegisterRuntimeMetrics() {
+	r.register(runtimeCollector{})
+}
+
+func (runtimeCollector) name() string { return "go_" }
+
+func (runtimeCollector) write(b *bytes.Buffer) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	info := desc{"go_info", "Information about the Go environment.", "gauge", []string{"version"}}
+	info.writeHeader(b)
+	writeSample(b, info.metricName, info.labels, []string{runtime.Version()}, "", "", 1)
+
+	for _, m := range []struct {
+		name, help, kind string
+		value            float64
+	}{
+		{"go_goroutines", "Number of goroutines that currently exist.", "gauge", float64(runtime.NumGoroutine())},
+		{"go_gomaxprocs", "Number of operating system threads that can execute Go code simultaneously.", "gauge", float64(runtime.GOMAXPROCS(0))},
+		{"go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", "gauge", float64(stats.Alloc)},
+		{"go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", "counter", float64(stats.TotalAlloc)},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from the system.", "gauge", float64(stats.Sys)},
+		{"go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", "gauge", float64(stats.HeapInuse)},
+		{"go_memstats_heap_objects", "Number of allocated objects.", "gauge", float64(stats.HeapObjects)},
+		{"go_memstats_mallocs_total", "Total number of mallocs.", "counter", float64(stats.Mallocs)},
+		{"go_memstats_frees_total", "Total number of frees.", "counter", float64(stats.Frees)},
+		{"go_gc_cycles_total", "Number of completed GC cycles.", "counter", float64(stats.NumGC)},
+		{"go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", "counter", float64(stats.PauseTotalNs) / 1e9},
+	} {
+		d := desc{m.name, m.help, m.kind, nil}
+		d.writeHeader(b)
+		writeSample(b, m.name, nil, nil, "", "", m.value)
+	}
+}
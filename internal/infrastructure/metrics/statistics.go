@@ -0,0 +1,17 @@
+package metrics
+
+import "fizzbuzz-service/internal/application"
+
+// RegisterStatisticsDispatcher exposes the queue of background statistics
+func (r *Registry) RegisterStatisticsDispatcher(d *application.StatisticsDispatcher) {
+	r.NewGaugeFunc("stats_queue_depth", "Number of hits waiting to be recorded.",
+		func() float64 { return float64(d.Stats().QueueDepth) })
+	r.NewGaugeFunc("stats_queue_capacity", "Maximum number of hits waiting to be recorded.",
+		func() float64 { return float64(d.Stats().QueueCapacity) })
+	r.NewCounterFunc("stats_hits_recorded_total", "Number of hits recorded in statistics.",
+		func() float64 { return float64(d.Stats().Processed) })
+	r.NewCounterFunc("stats_hits_failed_total", "Number of hits whose recording failed.",
+		func() float64 { return float64(d.Stats().Failed) })
+	r.NewCounterFunc("stats_hits_dropped_total", "Number of hits dropped because the queue was full.",
+		func() float64 { return float64(d.Stats().Dropped) })
+}
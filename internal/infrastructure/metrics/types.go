@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets are latency buckets in seconds, from 5ms to 10s
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// ExponentialBuckets returns count buckets starting at start, each factor times the previous
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// atomicFloat is a float64 updated without locks
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) { f.bits.Store(math.Float64bits(v)) }
+func (f *atomicFloat) load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+// vec maps label values to one metric of type M
+type vec[M any] struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*vecEntry[M]
+	create func() *M
+}
+
+type vecEntry[M any] struct {
+	series
+	metric *M
+}
+
+func newVec[M any](d desc, create func() *M) *vec[M] {
+	return &vec[M]{desc: d, series: make(map[string]*vecEntry[M]), create: create}
+}
+
+func (v *vec[M]) with(values []string) *M {
+	key := v.key(values)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry, ok := v.series[key]
+	if !ok {
+		entry = &vecEntry[M]{series: series{values: append([]string(nil), values...)}, metric: v.create()}
+		v.series[key] = entry
+	}
+	return entry.metric
+}
+
+// each visits the series sorted by label values
+func (v *vec[M]) each(fn func(values []string, metric *M)) {
+	v.mu.Lock()
+	entries := make(map[string]*vecEntry[M], len(v.series))
+	for k, e := range v.series {
+		entries[k] = e
+	}
+	v.mu.Unlock()
+
+	for _, k := range sortedKeys(entries) {
+		fn(entries[k].values, entries[k].metric)
+	}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc adds one
+func (c *Counter) Inc() { c.value.add(1) }
+
+// Add adds v, which must not be negative
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.value.add(v)
+}
+
+// CounterVec is a counter per label combination
+type CounterVec struct {
+	*vec[Counter]
+}
+
+// With returns the counter of the given label values, creating it if needed
+func (v *CounterVec) With(values ...string) *Counter { return v.with(values) }
+
+func (v *CounterVec) write(b *bytes.Buffer) {
+	v.writeHeader(b)
+	v.each(func(values []string, c *Counter) {
+		writeSample(b, v.metricName, v.labels, values, "", "", c.value.load())
+	})
+}
+
+// NewCounterVec registers a counter family
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{newVec(desc{name, help, "counter", labels}, func() *Counter { return &Counter{} })}
+	r.register(v)
+	return v
+}
+
+// NewCounter registers a counter without labels
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set replaces the value
+func (g *Gauge) Set(v float64) { g.value.set(v) }
+
+// Add adds v, which may be negative
+func (g *Gauge) Add(v float64) { g.value.add(v) }
+
+// GaugeVec is a gauge per label combination
+type GaugeVec struct {
+	*vec[Gauge]
+}
+
+// With returns the gauge of the given label values, creating it if needed
+func (v *GaugeVec) With(values ...string) *Gauge { return v.with(values) }
+
+func (v *GaugeVec) write(b *bytes.Buffer) {
+	v.writeHeader(b)
+	v.each(func(values []string, g *Gauge) {
+		writeSample(b, v.metricName, v.labels, values, "", "", g.value.load())
+	})
+}
+
+// NewGaugeVec registers a gauge family
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	v := &GaugeVec{newVec(desc{name, help, "gauge", labels}, func() *Gauge { return &Gauge{} })}
+	r.register(v)
+	return v
+}
+
+// NewGauge registers a gauge without labels
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return r.NewGaugeVec(name, help).With()
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	upperBounds []float64
+	mu          sync.Mutex
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// Observe adds one observation
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// HistogramVec is a histogram per label combination
+type HistogramVec struct {
+	*vec[Histogram]
+}
+
+// With returns the histogram of the given label values, creating it if needed
+func (v *HistogramVec) With(values ...string) *Histogram { return v.with(values) }
+
+func (v *HistogramVec) write(b *bytes.Buffer) {
+	v.writeHeader(b)
+	v.each(func(values []string, h *Histogram) {
+		h.mu.Lock()
+		counts := append([]uint64(nil), h.counts...)
+		count, sum := h.count, h.sum
+		h.mu.Unlock()
+
+		var cumulative uint64
+		for i, bound := range h.upperBounds {
+			cumulative += counts[i]
+			writeSample(b, v.metricName+"_bucket", v.labels, values, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(b, v.metricName+"_bucket", v.labels, values, "le", "+Inf", float64(count))
+		writeSample(b, v.metricName+"_sum", v.labels, values, "", "", sum)
+		writeSample(b, v.metricName+"_count", v.labels, values, "", "", float64(count))
+	})
+}
+
+// NewHistogramVec registers a histogram family with the given bucket upper bounds
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+
+	v := &HistogramVec{newVec(desc{name, help, "histogram", labels}, func() *Histogram {
+		return &Histogram{upperBounds: bounds, counts: make([]uint64, len(bounds))}
+	})}
+	r.register(v)
+	return v
+}
+
+// NewHistogram registers a histogram without labels
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	return r.NewHistogramVec(name, help, buckets).With()
+}
+
+// funcMetric is a value computed at scrape time
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+func (f *funcMetric) write(b *bytes.Buffer) {
+	f.writeHeader(b)
+	writeSample(b, f.metricName, nil, nil, "", "", f.fn())
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on each scrape
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc{name, help, "gauge", nil}, fn})
+}
+
+// NewCounterFunc registers a counter whose value is read from fn on each scrape
+// fn must never return a smaller value than before.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc{name, help, "counter", nil}, fn})
+}
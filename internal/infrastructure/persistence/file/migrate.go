@@ -0,0 +1,72 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+)
+
+// snapshotVersion is the format of the snapshots written today
+//
+//	0: recent hits keyed by the entity.FizzBuzzQuery.Key() of the time,
+//	   whose two-rule keys left words unquoted
+//	1: recent hits refer to their entry, whatever its key
+//
+// Log records carry their whole query, whose key is computed on replay, so
+// they need no migration.
+const snapshotVersion = 1
+
+// migrateSnapshot upgrades snap, decoded from data, from an earlier version
+func migrateSnapshot(data []byte, snap *snapshotFile) error {
+	if snap.Version >= snapshotVersion {
+		return nil
+	}
+
+	var legacy struct {
+		State struct {
+			Recent []struct {
+				Resolution time.Duration    `json:"resolution"`
+				Start      time.Time        `json:"start"`
+				Counts     map[string]int64 `json:"counts"`
+			} `json:"recent"`
+		} `json:"state"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("decode statistics snapshot version %d: %w", snap.Version, err)
+	}
+
+	index := make(map[string]int, len(snap.State.Entries))
+	for i, e := range snap.State.Entries {
+		index[unquotedKey(e.Query)] = i
+	}
+
+	snap.State.Recent = make([]inmemory.SnapshotBucket, 0, len(legacy.State.Recent))
+	for _, bucket := range legacy.State.Recent {
+		hits := make(map[int]int64, len(bucket.Counts))
+		for key, n := range bucket.Counts {
+			if i, ok := index[key]; ok {
+				hits[i] += n
+			}
+		}
+		snap.State.Recent = append(snap.State.Recent, inmemory.SnapshotBucket{
+			Resolution: bucket.Resolution,
+			Start:      bucket.Start,
+			Hits:       hits,
+		})
+	}
+	snap.Version = snapshotVersion
+	return nil
+}
+
+// unquotedKey is the key of query in version 0 snapshots
+func unquotedKey(query entity.FizzBuzzQuery) string {
+	rules := query.RuleSet()
+	if len(rules) != 2 {
+		return query.Key()
+	}
+	return fmt.Sprintf("%d:%d:%d:%s:%s",
+		rules[0].Divisor, rules[1].Divisor, query.UpperLimit, rules[0].Word, rules[1].Word)
+}
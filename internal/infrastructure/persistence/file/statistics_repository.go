@@ -0,0 +1,403 @@
+// Package file persists statistics on local disk so they survive restarts.
+//
+// Every hit is appended to a log (hits.log, one JSON record per line). The
+// log is periodically compacted into a snapshot (snapshot.json) of the whole
+// state, then truncated. On startup the snapshot is loaded and the log
+// replayed; sequence numbers make the replay idempotent if the process died
+// between writing a snapshot and truncating the log.
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+)
+
+const (
+	logFileName      = "hits.log"
+	snapshotFileName = "snapshot.json"
+)
+
+// ErrClosed is returned by UpdateStats after Close
+var ErrClosed = errors.New("statistics repository closed")
+
+// Config tunes durability versus write cost
+type Config struct {
+	// Dir holds the log and snapshot files; created if missing
+	Dir string
+	// FlushInterval is how often buffered log records reach the disk
+	FlushInterval time.Duration
+	// SnapshotInterval is how often the log is compacted into a snapshot
+	SnapshotInterval time.Duration
+	// MaxClients bounds the clients whose hits are kept, see
+	// inmemory.WithMaxClients
+	MaxClients int
+}
+
+// DefaultConfig returns sensible defaults for the given directory
+func DefaultConfig(dir string) Config {
+	return Config{
+		Dir:              dir,
+		FlushInterval:    time.Second,
+		SnapshotInterval: 5 * time.Minute,
+		MaxClients:       inmemory.DefaultMaxClients,
+	}
+}
+
+// StatisticsRepository implements both StatisticsUpdater and StatisticsRepository interfaces
+// Reads are served from memory; writes are logged before being applied.
+type StatisticsRepository struct {
+	config Config
+	memory *inmemory.StatisticsRepository
+	clock  clock.Clock
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	log    *bufio.Writer
+	seq    uint64
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Option customizes the repository
+type Option func(*StatisticsRepository)
+
+// WithClock sets the time source (defaults to the wall clock)
+func WithClock(c clock.Clock) Option {
+	return func(r *StatisticsRepository) {
+		r.clock = c
+	}
+}
+
+// logRecord is one line of the hit log
+type logRecord struct {
+	Seq   uint64               `json:"seq"`
+	Query entity.FizzBuzzQuery `json:"query"`
+	// Client is the caller ID, absent for anonymous hits
+	Client string    `json:"client,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// snapshotFile is the content of the snapshot file
+type snapshotFile struct {
+	// Version is the format of the file, see snapshotVersion
+	Version int `json:"version"`
+	// Seq is the last log record included in the snapshot
+	Seq   uint64            `json:"seq"`
+	State inmemory.Snapshot `json:"state"`
+}
+
+// Open recovers the state stored in config.Dir and starts background flushing
+// Call Close to flush and compact on shutdown.
+func Open(config Config, logger *slog.Logger, opts ...Option) (*StatisticsRepository, error) {
+	r := &StatisticsRepository{
+		config: config,
+		clock:  clock.System{},
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.memory = inmemory.NewStatisticsRepository(inmemory.WithClock(r.clock), inmemory.WithMaxClients(config.MaxClients))
+
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create statistics dir: %w", err)
+	}
+
+	if err := r.recover(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(r.path(logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open statistics log: %w", err)
+	}
+	r.file = file
+	r.log = bufio.NewWriter(file)
+
+	go r.run()
+
+	return r, nil
+}
+
+// UpdateStats logs the hit, then counts it
+func (r *StatisticsRepository) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	at := r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+
+	record := logRecord{Seq: r.seq + 1, Query: query, Client: caller.ID, At: at}
+	if err := json.NewEncoder(r.log).Encode(record); err != nil {
+		return fmt.Errorf("append statistics log: %w", err)
+	}
+	r.seq = record.Seq
+
+	r.memory.RecordHits(query, caller, at, 1)
+	return nil
+}
+
+// GetMostFrequent returns the query with the highest hit count
+func (r *StatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
+	return r.memory.GetMostFrequent(ctx)
+}
+
+// GetTop returns a page of the ranking, see application.StatisticsRepository
+func (r *StatisticsRepository) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	return r.memory.GetTop(ctx, query)
+}
+
+// GetClients returns a page of the ranking of clients, see application.StatisticsRepository
+func (r *StatisticsRepository) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	return r.memory.GetClients(ctx, query)
+}
+
+// Ping fails once the repository is closed or its log file is gone
+func (r *StatisticsRepository) Ping(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	if _, err := os.Stat(r.path(logFileName)); err != nil {
+		return fmt.Errorf("statistics log: %w", err)
+	}
+	return nil
+}
+
+// Flush writes buffered log records to disk
+// Fails with ErrClosed once Close started, which flushes on its own.
+func (r *StatisticsRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	return r.flushLocked()
+}
+
+// Compact writes a snapshot of the current state and truncates the log
+// Fails with ErrClosed once Close started, which compacts on its own.
+func (r *StatisticsRepository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	return r.compactLocked()
+}
+
+// Close stops background work, compacts the log and releases the files
+// The log is compacted and closed even when ctx ends first, once a flush
+// already running completes; ctx.Err() is then reported along.
+// Safe to call more than once.
+func (r *StatisticsRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	var waitErr error
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.compactLocked()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return errors.Join(waitErr, err)
+}
+
+// run flushes and compacts periodically until Close
+func (r *StatisticsRepository) run() {
+	defer close(r.done)
+
+	flush := time.NewTicker(r.config.FlushInterval)
+	defer flush.Stop()
+	snapshot := time.NewTicker(r.config.SnapshotInterval)
+	defer snapshot.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-flush.C:
+			if err := r.Flush(); err != nil && !errors.Is(err, ErrClosed) {
+				r.logger.Error("failed to flush statistics log", "error", err)
+			}
+		case <-snapshot.C:
+			if err := r.Compact(); err != nil && !errors.Is(err, ErrClosed) {
+				r.logger.Error("failed to compact statistics log", "error", err)
+			}
+		}
+	}
+}
+
+func (r *StatisticsRepository) flushLocked() error {
+	if err := r.log.Flush(); err != nil {
+		return fmt.Errorf("flush statistics log: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("sync statistics log: %w", err)
+	}
+	return nil
+}
+
+func (r *StatisticsRepository) compactLocked() error {
+	if err := r.flushLocked(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshotFile{Version: snapshotVersion, Seq: r.seq, State: r.memory.Snapshot()})
+	if err != nil {
+		return fmt.Errorf("encode statistics snapshot: %w", err)
+	}
+	if err := writeFileAtomic(r.path(snapshotFileName), data); err != nil {
+		return err
+	}
+
+	// Records up to r.seq are in the snapshot; replay skips them if we die here
+	if err := r.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate statistics log: %w", err)
+	}
+	return r.file.Sync()
+}
+
+// recover loads the snapshot, then replays the log records it does not include
+func (r *StatisticsRepository) recover() error {
+	data, err := os.ReadFile(r.path(snapshotFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read statistics snapshot: %w", err)
+	default:
+		var snap snapshotFile
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return fmt.Errorf("decode statistics snapshot: %w", err)
+		}
+		if err := migrateSnapshot(data, &snap); err != nil {
+			return err
+		}
+		r.memory.Restore(snap.State)
+		r.seq = snap.Seq
+	}
+
+	return r.replay()
+}
+
+func (r *StatisticsRepository) replay() error {
+	file, err := os.OpenFile(r.path(logFileName), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open statistics log: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		reader   = bufio.NewReader(file)
+		valid    int64
+		replayed int
+	)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A partial last line is a write torn by a crash
+			if len(line) > 0 {
+				r.logger.Warn("discarding torn statistics log record", "offset", valid)
+			}
+			break
+		}
+
+		var record logRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			r.logger.Warn("discarding corrupt statistics log tail", "offset", valid, "error", err)
+			break
+		}
+		valid += int64(len(line))
+
+		if record.Seq <= r.seq {
+			continue
+		}
+		r.memory.RecordHits(record.Query, entity.Caller{ID: record.Client}, record.At, 1)
+		r.seq = record.Seq
+		replayed++
+	}
+
+	if err := file.Truncate(valid); err != nil {
+		return fmt.Errorf("truncate statistics log: %w", err)
+	}
+
+	r.logger.Info("statistics recovered", "dir", r.config.Dir, "replayed", replayed)
+	return nil
+}
+
+func (r *StatisticsRepository) path(name string) string {
+	return filepath.Join(r.config.Dir, name)
+}
+
+// writeFileAtomic replaces path with data so readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync %s: %w", tmp, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+
+	// Persist the rename itself
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package inmemory
+
+import (
+	"context"
+	"hash/maphash"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+)
+
+// ShardedStatisticsRepository spreads queries over independently locked
+// StatisticsRepository shards, picked by key
+// Concurrent updates of different queries rarely contend; reads merge every
+// shard, so they cost a little more than with a single repository. The hits
+// of a client are spread over shards, one query in a single shard.
+type ShardedStatisticsRepository struct {
+	seed   maphash.Seed
+	shards []*StatisticsRepository
+}
+
+// NewShardedStatisticsRepository creates a repository with the given number of shards (at least 1)
+func NewShardedStatisticsRepository(shards int, opts ...Option) *ShardedStatisticsRepository {
+	r := &ShardedStatisticsRepository{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*StatisticsRepository, max(shards, 1)),
+	}
+
+	// Same options, so every shard reads the same clock and windows line up;
+	// WithMaxClients bounds the clients of each shard
+	for i := range r.shards {
+		r.shards[i] = NewStatisticsRepository(opts...)
+	}
+	return r
+}
+
+func (r *ShardedStatisticsRepository) shardFor(key string) *StatisticsRepository {
+	return r.shards[maphash.String(r.seed, key)%uint64(len(r.shards))]
+}
+
+func (r *ShardedStatisticsRepository) clock() clock.Clock {
+	return r.shards[0].clock
+}
+
+// Ping always succeeds: the statistics live in the process
+func (r *ShardedStatisticsRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// UpdateStats increments the count for a query pattern, locking only its shard
+func (r *ShardedStatisticsRepository) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	r.RecordHits(query, caller, r.clock().Now(), 1)
+	return nil
+}
+
+// RecordHits adds hits of a caller for a query at the given time
+func (r *ShardedStatisticsRepository) RecordHits(query entity.FizzBuzzQuery, caller entity.Caller, at time.Time, hits int64) {
+	key := query.Key()
+	shard := r.shardFor(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.recordLocked(key, query, caller, at, hits)
+}
+
+// UpdateStatsBatch counts many hits, locking each shard once
+func (r *ShardedStatisticsRepository) UpdateStatsBatch(ctx context.Context, deltas []application.HitDelta) error {
+	at := r.clock().Now()
+
+	type keyedDelta struct {
+		key   string
+		delta application.HitDelta
+	}
+	byShard := make(map[*StatisticsRepository][]keyedDelta)
+	for _, delta := range deltas {
+		key := delta.Query.Key()
+		shard := r.shardFor(key)
+		byShard[shard] = append(byShard[shard], keyedDelta{key, delta})
+	}
+
+	for shard, deltas := range byShard {
+		shard.mu.Lock()
+		for _, d := range deltas {
+			shard.recordLocked(d.key, d.delta.Query, d.delta.Caller, at, d.delta.Hits)
+		}
+		shard.mu.Unlock()
+	}
+	return nil
+}
+
+// GetMostFrequent returns the query with the highest hit count
+// Ties are broken as in the ranking: most recent hit, then key
+func (r *ShardedStatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
+	var maxEntry *countEntry
+	for _, shard := range r.shards {
+		shard.mu.RLock()
+		if entry := mostFrequent(shard.stats); entry != nil {
+			if maxEntry == nil || entity.RanksBefore(entry.cursor(), maxEntry.cursor()) {
+				copied := *entry
+				maxEntry = &copied
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	// Return null for most_frequent_request when no requests have been made
+	if maxEntry == nil {
+		return &entity.StatisticsSummary{}, nil
+	}
+	return &entity.StatisticsSummary{
+		MostFrequentQuery: maxEntry.query.ToResponse(),
+		HitCount:          maxEntry.hitCount,
+	}, nil
+}
+
+// GetTop returns a page of the ranking, see application.StatisticsRepository
+func (r *ShardedStatisticsRepository) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	var entries []*countEntry
+	for _, shard := range r.shards {
+		shard.mu.RLock()
+		for _, entry := range shard.entriesLocked(query) {
+			// Copied: the shard keeps updating its entries once unlocked
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+		shard.mu.RUnlock()
+	}
+	return rank(entries, query), nil
+}
+
+// GetClients returns a page of the ranking of clients, see
+// application.StatisticsRepository
+// A client's total is the sum over shards; its most frequent query is the
+// best of the shards, since each query is counted in a single shard.
+func (r *ShardedStatisticsRepository) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	merged := make(map[string]*clientSummary)
+	for _, shard := range r.shards {
+		shard.mu.RLock()
+		for _, partial := range shard.clientsLocked() {
+			// Copied: the shard keeps updating its entries once unlocked
+			top := *partial.top
+			client, exists := merged[partial.id]
+			if !exists {
+				partial.top = &top
+				merged[partial.id] = partial
+				continue
+			}
+			client.hitCount += partial.hitCount
+			if partial.lastHitAt.After(client.lastHitAt) {
+				client.lastHitAt = partial.lastHitAt
+			}
+			if entity.RanksBefore(top.cursor(), client.top.cursor()) {
+				client.top = &top
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	clients := make([]*clientSummary, 0, len(merged))
+	for _, client := range merged {
+		clients = append(clients, client)
+	}
+	return rankClients(clients, query), nil
+}
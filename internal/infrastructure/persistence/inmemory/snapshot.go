@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"container/list"
+	"slices"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+)
+
+// Snapshot is a serializable copy of the repository state
+type Snapshot struct {
+	Entries []SnapshotEntry  `json:"entries"`
+	Recent  []SnapshotBucket `json:"recent"`
+	Clients []SnapshotClient `json:"clients,omitempty"`
+}
+
+// SnapshotEntry is the all-time count of one query
+type SnapshotEntry struct {
+	Query     entity.FizzBuzzQuery `json:"query"`
+	Hits      int64                `json:"hits"`
+	LastHitAt time.Time            `json:"last_hit_at"`
+}
+
+// SnapshotClient holds the all-time counts of the queries of one client
+type SnapshotClient struct {
+	Client  string          `json:"client"`
+	Entries []SnapshotEntry `json:"entries"`
+}
+
+// SnapshotBucket holds the hits per query of one time bucket
+type SnapshotBucket struct {
+	Resolution time.Duration `json:"resolution"`
+	Start      time.Time     `json:"start"`
+	// Hits maps indexes into Snapshot.Entries to their hits, so buckets
+	// follow their queries whatever key those are stored under
+	Hits map[int]int64 `json:"hits"`
+}
+
+// Snapshot copies the current state
+func (r *StatisticsRepository) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snap := Snapshot{Entries: make([]SnapshotEntry, 0, len(r.stats))}
+	index := make(map[string]int, len(r.stats))
+	for key, entry := range r.stats {
+		index[key] = len(snap.Entries)
+		snap.Entries = append(snap.Entries, snapshotEntry(entry))
+	}
+	for _, client := range r.clients {
+		snap.Clients = append(snap.Clients, SnapshotClient{
+			Client:  client.id,
+			Entries: snapshotEntries(client.queries),
+		})
+	}
+
+	for _, counter := range []*windowCounter{r.minutes, r.hours} {
+		for _, bucket := range counter.buckets {
+			if bucket.counts == nil {
+				continue
+			}
+			hits := make(map[int]int64, len(bucket.counts))
+			for key, n := range bucket.counts {
+				if i, ok := index[key]; ok {
+					hits[i] = n
+				}
+			}
+			snap.Recent = append(snap.Recent, SnapshotBucket{
+				Resolution: counter.resolution,
+				Start:      bucket.start,
+				Hits:       hits,
+			})
+		}
+	}
+
+	return snap
+}
+
+func snapshotEntries(entries map[string]*countEntry) []SnapshotEntry {
+	snap := make([]SnapshotEntry, 0, len(entries))
+	for _, entry := range entries {
+		snap = append(snap, snapshotEntry(entry))
+	}
+	return snap
+}
+
+func snapshotEntry(entry *countEntry) SnapshotEntry {
+	return SnapshotEntry{
+		Query:     entry.query,
+		Hits:      entry.hitCount,
+		LastHitAt: entry.lastHitAt,
+	}
+}
+
+// Restore replaces the current state with a snapshot
+func (r *StatisticsRepository) Restore(snap Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats = make(map[string]*countEntry, len(snap.Entries))
+	r.clients = make(map[string]*clientEntry, len(snap.Clients))
+	r.recentClients = list.New()
+	r.minutes = newWindowCounter(time.Minute, 61)
+	r.hours = newWindowCounter(time.Hour, 25)
+
+	for _, e := range snap.Entries {
+		addHits(r.stats, e.Query.Key(), e.Query, e.LastHitAt, e.Hits)
+	}
+
+	// Clients are restored from the least to the most recently hit, so the
+	// same ones are kept as before when over maxClients
+	clients := slices.Clone(snap.Clients)
+	slices.SortStableFunc(clients, func(a, b SnapshotClient) int {
+		return lastHit(a.Entries).Compare(lastHit(b.Entries))
+	})
+	for _, c := range clients {
+		client := r.clientLocked(c.Client)
+		for _, e := range c.Entries {
+			addHits(client.queries, e.Query.Key(), e.Query, e.LastHitAt, e.Hits)
+			client.hitCount += e.Hits
+			if e.LastHitAt.After(client.lastHitAt) {
+				client.lastHitAt = e.LastHitAt
+			}
+		}
+	}
+
+	for _, bucket := range snap.Recent {
+		for _, counter := range []*windowCounter{r.minutes, r.hours} {
+			if counter.resolution != bucket.Resolution {
+				continue
+			}
+			// Keyed like the entries, as of today's Key()
+			for i, n := range bucket.Hits {
+				if i < 0 || i >= len(snap.Entries) {
+					continue
+				}
+				counter.add(snap.Entries[i].Query.Key(), bucket.Start, n)
+			}
+		}
+	}
+}
+
+// lastHit returns the most recent hit of entries
+func lastHit(entries []SnapshotEntry) time.Time {
+	var last time.Time
+	for _, e := range entries {
+		if e.LastHitAt.After(last) {
+			last = e.LastHitAt
+		}
+	}
+	return last
+}
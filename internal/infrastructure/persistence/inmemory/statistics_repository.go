@@ -1,56 +1,201 @@
 package inmemory
 
 import (
+	"container/list"
 	"context"
+	"sort"
 	"sync"
 	"time"
 
+	"fizzbuzz-service/internal/application"
 	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
 )
 
+// DefaultMaxClients is the number of clients whose hits are kept by default
+const DefaultMaxClients = 10000
+
 // StatisticsRepository implements both StatisticsUpdater and StatisticsRepository interfaces
 type StatisticsRepository struct {
 	mu    sync.RWMutex
 	stats map[string]*countEntry
+	// clients counts the all-time hits of each known caller, per query
+	clients map[string]*clientEntry
+	// recentClients orders clients from the most to the least recently hit;
+	// beyond maxClients, the least recent is forgotten
+	recentClients *list.List
+	maxClients    int
+	// Recent hits for windowed queries: per minute for the last hour,
+	// per hour for the last day
+	minutes *windowCounter
+	hours   *windowCounter
+	clock   clock.Clock
+}
+
+// Option customizes the repository
+type Option func(*StatisticsRepository)
+
+// WithClock sets the time source (defaults to the wall clock)
+func WithClock(c clock.Clock) Option {
+	return func(r *StatisticsRepository) {
+		r.clock = c
+	}
+}
+
+// WithMaxClients keeps the hits of at most n clients, forgetting the least
+// recently seen ones first
+// Defaults to DefaultMaxClients, also used when n is not positive; global
+// counts are not affected.
+func WithMaxClients(n int) Option {
+	return func(r *StatisticsRepository) {
+		if n > 0 {
+			r.maxClients = n
+		}
+	}
 }
 
 type countEntry struct {
+	key       string
 	query     entity.FizzBuzzQuery
 	hitCount  int64
 	lastHitAt time.Time
 }
 
+func (e *countEntry) cursor() entity.RankCursor {
+	return entity.RankCursor{HitCount: e.hitCount, LastHitAt: e.lastHitAt, Key: e.key}
+}
+
+type clientEntry struct {
+	id        string
+	hitCount  int64
+	lastHitAt time.Time
+	queries   map[string]*countEntry
+	// recent is the element of the client in recentClients
+	recent *list.Element
+}
+
+// clientSummary is the total of a client, with its most frequent query
+type clientSummary struct {
+	id        string
+	hitCount  int64
+	lastHitAt time.Time
+	top       *countEntry
+}
+
+func (c *clientSummary) cursor() entity.RankCursor {
+	return entity.RankCursor{HitCount: c.hitCount, LastHitAt: c.lastHitAt, Key: c.id}
+}
+
 // NewStatisticsRepository creates a thread-safe in-memory repository
-func NewStatisticsRepository() *StatisticsRepository {
-	return &StatisticsRepository{
-		stats: make(map[string]*countEntry),
+func NewStatisticsRepository(opts ...Option) *StatisticsRepository {
+	r := &StatisticsRepository{
+		stats:         make(map[string]*countEntry),
+		clients:       make(map[string]*clientEntry),
+		recentClients: list.New(),
+		maxClients:    DefaultMaxClients,
+		minutes:       newWindowCounter(time.Minute, 61),
+		hours:         newWindowCounter(time.Hour, 25),
+		clock:         clock.System{},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
+}
+
+// Ping always succeeds: the statistics live in the process
+func (r *StatisticsRepository) Ping(ctx context.Context) error {
+	return nil
 }
 
 // UpdateStats increments the count for a query pattern
 // The key includes ALL parameters (including limit) to correctly track unique requests
-func (r *StatisticsRepository) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery) error {
+func (r *StatisticsRepository) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	r.RecordHits(query, caller, r.clock.Now(), 1)
+	return nil
+}
+
+// UpdateStatsBatch counts many hits under a single lock
+func (r *StatisticsRepository) UpdateStatsBatch(ctx context.Context, deltas []application.HitDelta) error {
+	at := r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delta := range deltas {
+		r.recordLocked(delta.Query.Key(), delta.Query, delta.Caller, at, delta.Hits)
+	}
+	return nil
+}
+
+// RecordHits adds hits of a caller for a query at the given time
+// Lets other adapters replay or aggregate hits with their original timestamps.
+func (r *StatisticsRepository) RecordHits(query entity.FizzBuzzQuery, caller entity.Caller, at time.Time, hits int64) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// Use the entity's Key() method for consistent key generation
-	key := query.Key()
-
-	if entry, exists := r.stats[key]; exists {
-		entry.hitCount++
-		entry.lastHitAt = time.Now()
-	} else {
-		r.stats[key] = &countEntry{
-			query:     query,
-			hitCount:  1,
-			lastHitAt: time.Now(),
-		}
+	r.recordLocked(query.Key(), query, caller, at, hits)
+}
+
+// recordLocked adds hits for a query identified by key
+// Must be called with the write lock held
+func (r *StatisticsRepository) recordLocked(key string, query entity.FizzBuzzQuery, caller entity.Caller, at time.Time, hits int64) {
+	addHits(r.stats, key, query, at, hits)
+	r.minutes.add(key, at, hits)
+	r.hours.add(key, at, hits)
+
+	if caller.ID == "" {
+		return
+	}
+	client := r.clientLocked(caller.ID)
+	client.hitCount += hits
+	if at.After(client.lastHitAt) {
+		client.lastHitAt = at
+	}
+	addHits(client.queries, key, query, at, hits)
+}
+
+// clientLocked returns the entry of the client id, created if missing, and
+// marks it as the most recent one
+// Creating an entry beyond maxClients forgets the least recent client.
+// Must be called with the write lock held
+func (r *StatisticsRepository) clientLocked(id string) *clientEntry {
+	if client, exists := r.clients[id]; exists {
+		r.recentClients.MoveToFront(client.recent)
+		return client
 	}
 
-	return nil
+	client := &clientEntry{id: id, queries: make(map[string]*countEntry)}
+	client.recent = r.recentClients.PushFront(client)
+	r.clients[id] = client
+
+	for len(r.clients) > r.maxClients {
+		oldest := r.recentClients.Remove(r.recentClients.Back()).(*clientEntry)
+		delete(r.clients, oldest.id)
+	}
+	return client
+}
+
+// addHits adds hits to the entry of key, created if missing
+func addHits(entries map[string]*countEntry, key string, query entity.FizzBuzzQuery, at time.Time, hits int64) {
+	if entry, exists := entries[key]; exists {
+		entry.hitCount += hits
+		if at.After(entry.lastHitAt) {
+			entry.lastHitAt = at
+		}
+		return
+	}
+	entries[key] = &countEntry{
+		key:       key,
+		query:     query,
+		hitCount:  hits,
+		lastHitAt: at,
+	}
 }
 
 // GetMostFrequent returns the query with the highest hit count
+// Ties are broken as in the ranking: most recent hit, then key
 func (r *StatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -63,12 +208,7 @@ func (r *StatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.Sta
 		}, nil
 	}
 
-	var maxEntry *countEntry
-	for _, entry := range r.stats {
-		if maxEntry == nil || entry.hitCount > maxEntry.hitCount {
-			maxEntry = entry
-		}
-	}
+	maxEntry := mostFrequent(r.stats)
 
 	return &entity.StatisticsSummary{
 		MostFrequentQuery: maxEntry.query.ToResponse(),
@@ -76,6 +216,155 @@ func (r *StatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.Sta
 	}, nil
 }
 
+// mostFrequent returns the top entry, nil when empty
+// Must be called with at least a read lock held on the owner of entries
+func mostFrequent(entries map[string]*countEntry) *countEntry {
+	var maxEntry *countEntry
+	for _, entry := range entries {
+		if maxEntry == nil || entity.RanksBefore(entry.cursor(), maxEntry.cursor()) {
+			maxEntry = entry
+		}
+	}
+	return maxEntry
+}
+
+// GetTop returns a page of the ranking, see application.StatisticsRepository
+// Windowed counts have a granularity of one minute up to an hour, one hour beyond.
+func (r *StatisticsRepository) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return rank(r.entriesLocked(query), query), nil
+}
+
+// entriesLocked returns the unsorted entries counted over the window of
+// query, or of its client
+// Must be called with at least a read lock held
+func (r *StatisticsRepository) entriesLocked(query entity.TopQuery) []*countEntry {
+	window := query.Window
+	if query.Client != "" || window <= 0 {
+		all := r.stats
+		if query.Client != "" {
+			client, exists := r.clients[query.Client]
+			if !exists {
+				return nil
+			}
+			all = client.queries
+		}
+		entries := make([]*countEntry, 0, len(all))
+		for _, entry := range all {
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	counter := r.minutes
+	if window > counter.span() {
+		counter = r.hours
+	}
+
+	recent := counter.sum(r.clock.Now(), window)
+	entries := make([]*countEntry, 0, len(recent))
+	for key, hits := range recent {
+		all, ok := r.stats[key]
+		if !ok {
+			continue
+		}
+		entry := *all
+		entry.hitCount = hits
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+// rank sorts entries and returns the requested page
+// Must be called with at least a read lock held
+func rank(ranked []*countEntry, query entity.TopQuery) []entity.StatisticsEntry {
+	sort.Slice(ranked, func(i, j int) bool {
+		return entity.RanksBefore(ranked[i].cursor(), ranked[j].cursor())
+	})
+
+	start := 0
+	if query.After != nil {
+		start = sort.Search(len(ranked), func(i int) bool {
+			return entity.RanksBefore(*query.After, ranked[i].cursor())
+		})
+	}
+
+	end := min(start+query.Limit, len(ranked))
+	if start >= end {
+		return nil
+	}
+
+	entries := make([]entity.StatisticsEntry, 0, end-start)
+	for i := start; i < end; i++ {
+		entries = append(entries, entity.StatisticsEntry{
+			Rank:      i + 1,
+			Query:     ranked[i].query.ToResponse(),
+			HitCount:  ranked[i].hitCount,
+			LastHitAt: ranked[i].lastHitAt,
+			Key:       ranked[i].key,
+		})
+	}
+	return entries
+}
+
+// GetClients returns a page of the ranking of clients, see
+// application.StatisticsRepository
+func (r *StatisticsRepository) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return rankClients(r.clientsLocked(), query), nil
+}
+
+// clientsLocked summarizes every client
+// Must be called with at least a read lock held
+func (r *StatisticsRepository) clientsLocked() []*clientSummary {
+	clients := make([]*clientSummary, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, &clientSummary{
+			id:        client.id,
+			hitCount:  client.hitCount,
+			lastHitAt: client.lastHitAt,
+			top:       mostFrequent(client.queries),
+		})
+	}
+	return clients
+}
+
+// rankClients sorts clients and returns the requested page
+func rankClients(ranked []*clientSummary, query entity.ClientQuery) []entity.ClientEntry {
+	sort.Slice(ranked, func(i, j int) bool {
+		return entity.RanksBefore(ranked[i].cursor(), ranked[j].cursor())
+	})
+
+	start := 0
+	if query.After != nil {
+		start = sort.Search(len(ranked), func(i int) bool {
+			return entity.RanksBefore(*query.After, ranked[i].cursor())
+		})
+	}
+
+	end := min(start+query.Limit, len(ranked))
+	if start >= end {
+		return nil
+	}
+
+	entries := make([]entity.ClientEntry, 0, end-start)
+	for i := start; i < end; i++ {
+		entries = append(entries, entity.ClientEntry{
+			Rank:              i + 1,
+			Client:            ranked[i].id,
+			HitCount:          ranked[i].hitCount,
+			LastHitAt:         ranked[i].lastHitAt,
+			MostFrequentQuery: ranked[i].top.query.ToResponse(),
+			MostFrequentHits:  ranked[i].top.hitCount,
+		})
+	}
+	return entries
+}
+
 // GetStats returns all statistics (useful for debugging/testing)
 func (r *StatisticsRepository) GetStats() map[string]int64 {
 	r.mu.RLock()
@@ -93,4 +382,8 @@ func (r *StatisticsRepository) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.stats = make(map[string]*countEntry)
+	r.clients = make(map[string]*clientEntry)
+	r.recentClients = list.New()
+	r.minutes = newWindowCounter(time.Minute, 61)
+	r.hours = newWindowCounter(time.Hour, 25)
 }
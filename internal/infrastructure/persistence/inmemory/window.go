@@ -0,0 +1,70 @@
+package inmemory
+
+import "time"
+
+// windowCounter counts hits per key in a ring of fixed-duration buckets
+// Only the buckets of the last len(buckets) periods are kept, so memory is
+// bounded by the activity of that span, whatever the uptime.
+type windowCounter struct {
+	resolution time.Duration
+	buckets    []timeBucket
+}
+
+type timeBucket struct {
+	start  time.Time
+	counts map[string]int64
+}
+
+func newWindowCounter(resolution time.Duration, size int) *windowCounter {
+	return &windowCounter{
+		resolution: resolution,
+		buckets:    make([]timeBucket, size),
+	}
+}
+
+// span is the longest window this counter can answer
+func (w *windowCounter) span() time.Duration {
+	return w.resolution * time.Duration(len(w.buckets)-1)
+}
+
+func (w *windowCounter) bucketFor(at time.Time) *timeBucket {
+	start := at.Truncate(w.resolution)
+	idx := (start.UnixNano() / int64(w.resolution)) % int64(len(w.buckets))
+	return &w.buckets[idx]
+}
+
+// add counts n hits for key at the given time
+// Hits older than the ring are ignored.
+func (w *windowCounter) add(key string, at time.Time, n int64) {
+	start := at.Truncate(w.resolution)
+	bucket := w.bucketFor(at)
+
+	switch {
+	case bucket.start.Equal(start):
+	case bucket.start.After(start):
+		return
+	default:
+		bucket.start = start
+		bucket.counts = make(map[string]int64)
+	}
+
+	bucket.counts[key] += n
+}
+
+// sum returns the hits per key of the buckets overlapping [now-window, now]
+// The window is rounded up to the resolution.
+func (w *windowCounter) sum(now time.Time, window time.Duration) map[string]int64 {
+	from := now.Add(-window).Truncate(w.resolution)
+	to := now.Truncate(w.resolution)
+
+	totals := make(map[string]int64)
+	for _, bucket := range w.buckets {
+		if bucket.counts == nil || bucket.start.Before(from) || bucket.start.After(to) {
+			continue
+		}
+		for key, n := range bucket.counts {
+			totals[key] += n
+		}
+	}
+	return totals
+}
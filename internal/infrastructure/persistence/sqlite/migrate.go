@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrations are applied in the order of their numeric prefix
+// A migration must never change once released; add a new file instead.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// codeMigrations are the migrations SQL alone cannot express, numbered
+// along with the files
+var codeMigrations = []migration{
+	{version: 3, name: "0003_quote_keys", run: quoteKeys},
+}
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+	// run, when set, applies the migration instead of sql
+	run func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrate applies the migrations not yet recorded in schema_migrations
+// Each one runs in its own transaction with its bookkeeping row.
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT    NOT NULL,
+			applied_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+		)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	pending, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(ctx, db, m); err != nil {
+			return fmt.Errorf("apply migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.run != nil {
+		if err := m.run(ctx, tx); err != nil {
+			return err
+		}
+	} else if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	list := make([]migration, 0, len(entries)+len(codeMigrations))
+	list = append(list, codeMigrations...)
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, _, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s: name must start with a version number", name)
+		}
+
+		content, err := migrations.ReadFile("migrations/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		list = append(list, migration{version: version, name: name, sql: string(content)})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	for i := 1; i < len(list); i++ {
+		if list[i].version == list[i-1].version {
+			return nil, fmt.Errorf("migrations %s and %s share a version", list[i-1].name, list[i].name)
+		}
+	}
+	return list, nil
+}
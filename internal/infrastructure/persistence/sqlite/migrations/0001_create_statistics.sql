@@ -0,0 +1,31 @@
+-- One row per distinct query, identified by entity.FizzBuzzQuery.Key()
+CREATE TABLE queries (
+    key         TEXT    PRIMARY KEY,
+    upper_limit INTEGER NOT NULL,
+    hits        INTEGER NOT NULL,
+    -- Unix time in nanoseconds, so cursors round-trip exactly
+    last_hit_at INTEGER NOT NULL
+);
+
+CREATE INDEX queries_ranking ON queries (hits DESC, last_hit_at DESC, key);
+
+-- The divisor/word rules of each query, in evaluation order
+-- The int1/str1, int2/str2 form is stored as two rules.
+CREATE TABLE query_rules (
+    query_key TEXT    NOT NULL REFERENCES queries (key) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    divisor   INTEGER NOT NULL,
+    word      TEXT    NOT NULL,
+    PRIMARY KEY (query_key, position)
+);
+
+-- Hits per query and minute, for time-windowed statistics
+CREATE TABLE query_hits_per_minute (
+    query_key TEXT    NOT NULL REFERENCES queries (key) ON DELETE CASCADE,
+    -- Unix time in seconds of the start of the minute
+    minute    INTEGER NOT NULL,
+    hits      INTEGER NOT NULL,
+    PRIMARY KEY (query_key, minute)
+);
+
+CREATE INDEX query_hits_per_minute_minute ON query_hits_per_minute (minute);
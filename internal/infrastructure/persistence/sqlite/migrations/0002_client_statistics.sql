@@ -0,0 +1,13 @@
+-- All-time hits per client and query, for per-client statistics
+-- Anonymous hits are only counted in queries.
+CREATE TABLE client_query_hits (
+    -- entity.Caller.ID, e.g. api_key:ci or ip:203.0.113.7
+    client      TEXT    NOT NULL,
+    query_key   TEXT    NOT NULL REFERENCES queries (key) ON DELETE CASCADE,
+    hits        INTEGER NOT NULL,
+    -- Unix time in nanoseconds, as queries.last_hit_at
+    last_hit_at INTEGER NOT NULL,
+    PRIMARY KEY (client, query_key)
+);
+
+CREATE INDEX client_query_hits_ranking ON client_query_hits (client, hits DESC, last_hit_at DESC, query_key);
@@ -0,0 +1,15 @@
+-- One row per client of client_query_hits, so the least recently seen
+-- clients can be found without scanning their hits
+CREATE TABLE clients (
+    -- entity.Caller.ID, as client_query_hits.client
+    client      TEXT    PRIMARY KEY,
+    hits        INTEGER NOT NULL,
+    -- Unix time in nanoseconds, as queries.last_hit_at
+    last_hit_at INTEGER NOT NULL
+);
+
+CREATE INDEX clients_recency ON clients (last_hit_at DESC, client);
+
+INSERT INTO clients (client, hits, last_hit_at)
+SELECT client, SUM(hits), MAX(last_hit_at) FROM client_query_hits
+GROUP BY client;
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"fizzbuzz-service/internal/domain/entity"
+)
+
+// quoteKeys is migration 3: it moves the rows of queries stored under the
+// unquoted two-rule keys of earlier versions to the key
+// entity.FizzBuzzQuery.Key() gives today
+// Keys are recomputed from the stored limit and rules.
+func quoteKeys(ctx context.Context, tx *sql.Tx) error {
+	moves, err := outdatedKeys(ctx, tx)
+	if err != nil || len(moves) == 0 {
+		return err
+	}
+
+	// Parent and child keys change one after the other; references are
+	// checked once all of them have moved
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return fmt.Errorf("rekey statistics: %w", err)
+	}
+	for old, key := range moves {
+		for _, stmt := range []string{
+			`UPDATE queries SET key = ? WHERE key = ?`,
+			`UPDATE query_rules SET query_key = ? WHERE query_key = ?`,
+			`UPDATE query_hits_per_minute SET query_key = ? WHERE query_key = ?`,
+			`UPDATE client_query_hits SET query_key = ? WHERE query_key = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, key, old); err != nil {
+				return fmt.Errorf("rekey statistics: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// outdatedKeys maps the stored keys that differ from the current ones to
+// the current ones
+func outdatedKeys(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT q.key, q.upper_limit, r.divisor, r.word
+		FROM queries q JOIN query_rules r ON r.query_key = q.key
+		ORDER BY q.key, r.position`)
+	if err != nil {
+		return nil, fmt.Errorf("read statistics keys: %w", err)
+	}
+	defer rows.Close()
+
+	queries := make(map[string]*entity.FizzBuzzQuery)
+	for rows.Next() {
+		var (
+			key   string
+			limit int
+			rule  entity.Rule
+		)
+		if err := rows.Scan(&key, &limit, &rule.Divisor, &rule.Word); err != nil {
+			return nil, fmt.Errorf("read statistics keys: %w", err)
+		}
+		query, ok := queries[key]
+		if !ok {
+			query = &entity.FizzBuzzQuery{UpperLimit: limit}
+			queries[key] = query
+		}
+		query.Rules = append(query.Rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read statistics keys: %w", err)
+	}
+
+	moves := make(map[string]string)
+	for key, query := range queries {
+		if current := query.Key(); current != key {
+			moves[key] = current
+		}
+	}
+	return moves, nil
+}
@@ -0,0 +1,468 @@
+// Package sqlite stores statistics in a SQLite database for queryable history.
+//
+// Queries are normalized into columns (the limit, then one row per rule) so
+// the history can be explored with plain SQL. Hits are counted with upserts,
+// per query and per minute for time-windowed statistics, and per client and
+// query for per-client statistics, keeping the most recently seen clients
+// only. The schema is created and upgraded by migrations embedded in the
+// binary.
+//
+// The driver is pure Go (modernc.org/sqlite), so no cgo is required.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+
+	_ "modernc.org/sqlite"
+)
+
+// retention is how long per-minute hits are kept, a bit more than the
+// longest window served (application.MaxWindow)
+const retention = 25 * time.Hour
+
+// DefaultMaxClients is the number of clients whose hits are kept by default
+const DefaultMaxClients = 10000
+
+// StatisticsRepository implements both StatisticsUpdater and StatisticsRepository interfaces
+type StatisticsRepository struct {
+	db         *sql.DB
+	clock      clock.Clock
+	maxClients int
+
+	pruneMu    sync.Mutex
+	lastPruned time.Time
+}
+
+// Option customizes the repository
+type Option func(*StatisticsRepository)
+
+// WithClock sets the time source (defaults to the wall clock)
+func WithClock(c clock.Clock) Option {
+	return func(r *StatisticsRepository) {
+		r.clock = c
+	}
+}
+
+// WithMaxClients keeps the hits of at most n clients, deleting the least
+// recently seen ones first
+// Defaults to DefaultMaxClients, also used when n is not positive; global
+// counts are not affected.
+func WithMaxClients(n int) Option {
+	return func(r *StatisticsRepository) {
+		if n > 0 {
+			r.maxClients = n
+		}
+	}
+}
+
+// Open opens (or creates) the database at path and migrates its schema
+func Open(path string, opts ...Option) (*StatisticsRepository, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	pragmas.Add("_pragma", "synchronous(NORMAL)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open statistics database: %w", err)
+	}
+	// SQLite has a single writer; one connection avoids SQLITE_BUSY on
+	// concurrent upserts and is plenty for this workload
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	r := &StatisticsRepository{db: db, clock: clock.System{}, maxClients: DefaultMaxClients}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Close releases the database
+func (r *StatisticsRepository) Close(ctx context.Context) error {
+	return r.db.Close()
+}
+
+// Ping checks that the database is reachable
+func (r *StatisticsRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// UpdateStats increments the count for a query pattern
+func (r *StatisticsRepository) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	return r.RecordHits(ctx, query, caller, r.clock.Now(), 1)
+}
+
+// RecordHits adds hits of a caller for a query at the given time
+func (r *StatisticsRepository) RecordHits(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller, at time.Time, hits int64) error {
+	return r.update(ctx, func(tx *sql.Tx) error {
+		return r.recordHits(ctx, tx, query, caller, at, hits)
+	})
+}
+
+// UpdateStatsBatch counts many hits in a single transaction
+func (r *StatisticsRepository) UpdateStatsBatch(ctx context.Context, deltas []application.HitDelta) error {
+	at := r.clock.Now()
+	return r.update(ctx, func(tx *sql.Tx) error {
+		for _, delta := range deltas {
+			if err := r.recordHits(ctx, tx, delta.Query, delta.Caller, at, delta.Hits); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// update runs fn in a transaction, then prunes old windowed hits
+func (r *StatisticsRepository) update(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin statistics update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit statistics update: %w", err)
+	}
+
+	r.prune(ctx)
+	return nil
+}
+
+func (r *StatisticsRepository) recordHits(ctx context.Context, tx *sql.Tx, query entity.FizzBuzzQuery, caller entity.Caller, at time.Time, hits int64) error {
+	key := query.Key()
+
+	// hits only grows, so the total equals the increment only for a new row
+	var total int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO queries (key, upper_limit, hits, last_hit_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			hits = hits + excluded.hits,
+			last_hit_at = max(last_hit_at, excluded.last_hit_at)
+		RETURNING hits`,
+		key, query.UpperLimit, hits, at.UnixNano(),
+	).Scan(&total); err != nil {
+		return fmt.Errorf("count statistics hit: %w", err)
+	}
+
+	if total == hits {
+		for i, rule := range query.RuleSet() {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO query_rules (query_key, position, divisor, word) VALUES (?, ?, ?, ?)`,
+				key, i, rule.Divisor, rule.Word,
+			); err != nil {
+				return fmt.Errorf("store query rules: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO query_hits_per_minute (query_key, minute, hits) VALUES (?, ?, ?)
+		ON CONFLICT (query_key, minute) DO UPDATE SET hits = hits + excluded.hits`,
+		key, at.Truncate(time.Minute).Unix(), hits,
+	); err != nil {
+		return fmt.Errorf("count windowed statistics hit: %w", err)
+	}
+
+	if caller.ID == "" {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO client_query_hits (client, query_key, hits, last_hit_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (client, query_key) DO UPDATE SET
+			hits = hits + excluded.hits,
+			last_hit_at = max(last_hit_at, excluded.last_hit_at)`,
+		caller.ID, key, hits, at.UnixNano(),
+	); err != nil {
+		return fmt.Errorf("count client statistics hit: %w", err)
+	}
+
+	var clientHits int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO clients (client, hits, last_hit_at) VALUES (?, ?, ?)
+		ON CONFLICT (client) DO UPDATE SET
+			hits = hits + excluded.hits,
+			last_hit_at = max(last_hit_at, excluded.last_hit_at)
+		RETURNING hits`,
+		caller.ID, hits, at.UnixNano(),
+	).Scan(&clientHits); err != nil {
+		return fmt.Errorf("count client hit: %w", err)
+	}
+	if clientHits == hits {
+		return r.evictClients(ctx, tx)
+	}
+	return nil
+}
+
+// evictClients deletes the hits of the least recently seen clients beyond
+// maxClients
+// Only a new client can exceed the bound, so it runs only for those.
+func (r *StatisticsRepository) evictClients(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM clients WHERE client IN (
+			SELECT client FROM clients
+			ORDER BY last_hit_at DESC, client
+			LIMIT -1 OFFSET ?
+		)
+		RETURNING client`, r.maxClients)
+	if err != nil {
+		return fmt.Errorf("evict clients: %w", err)
+	}
+	var evicted []string
+	for rows.Next() {
+		var client string
+		if err := rows.Scan(&client); err != nil {
+			rows.Close()
+			return fmt.Errorf("evict clients: %w", err)
+		}
+		evicted = append(evicted, client)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("evict clients: %w", err)
+	}
+
+	for _, client := range evicted {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM client_query_hits WHERE client = ?`, client); err != nil {
+			return fmt.Errorf("evict client statistics: %w", err)
+		}
+	}
+	return nil
+}
+
+// prune deletes per-minute hits older than any window, at most once a minute
+func (r *StatisticsRepository) prune(ctx context.Context) {
+	now := r.clock.Now()
+
+	r.pruneMu.Lock()
+	if now.Sub(r.lastPruned) < time.Minute {
+		r.pruneMu.Unlock()
+		return
+	}
+	r.lastPruned = now
+	r.pruneMu.Unlock()
+
+	// Best effort: stale rows are outside every window anyway
+	r.db.ExecContext(ctx, `DELETE FROM query_hits_per_minute WHERE minute < ?`,
+		now.Add(-retention).Unix())
+}
+
+// GetMostFrequent returns the query with the highest hit count
+// Ties are broken as in the ranking: most recent hit, then key
+func (r *StatisticsRepository) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
+	entries, err := r.GetTop(ctx, entity.TopQuery{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	// Return null for most_frequent_request when no requests have been made
+	if len(entries) == 0 {
+		return &entity.StatisticsSummary{}, nil
+	}
+	return &entity.StatisticsSummary{
+		MostFrequentQuery: entries[0].Query,
+		HitCount:          entries[0].HitCount,
+	}, nil
+}
+
+// GetTop returns a page of the ranking, see application.StatisticsRepository
+// Windowed counts have a granularity of one minute up to an hour, one hour beyond.
+func (r *StatisticsRepository) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	// Counts without their own last hit use the global one of the query
+	counts := `SELECT key, hits, last_hit_at FROM queries`
+	var args []any
+
+	switch {
+	case query.Client != "":
+		counts = `
+			SELECT query_key AS key, hits, last_hit_at FROM client_query_hits
+			WHERE client = ?`
+		args = append(args, query.Client)
+	case query.Window > 0:
+		// Same rounding as the in-memory repository
+		resolution := time.Minute
+		if query.Window > time.Hour {
+			resolution = time.Hour
+		}
+		now := r.clock.Now()
+		from := now.Add(-query.Window).Truncate(resolution)
+		to := now.Truncate(resolution).Add(resolution)
+
+		counts = `
+			SELECT query_key AS key, SUM(hits) AS hits, NULL AS last_hit_at FROM query_hits_per_minute
+			WHERE minute >= ? AND minute < ?
+			GROUP BY query_key`
+		args = append(args, from.Unix(), to.Unix())
+	}
+
+	after := "1"
+	if c := query.After; c != nil {
+		after = `(hits < ? OR (hits = ? AND (last_hit_at < ? OR (last_hit_at = ? AND key > ?))))`
+		at := c.LastHitAt.UnixNano()
+		args = append(args, c.HitCount, c.HitCount, at, at, c.Key)
+	}
+	args = append(args, query.Limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT q.key, c.hits, COALESCE(c.last_hit_at, q.last_hit_at) AS last_hit_at, q.upper_limit,
+				ROW_NUMBER() OVER (ORDER BY c.hits DESC, COALESCE(c.last_hit_at, q.last_hit_at) DESC, q.key) AS rank
+			FROM (`+counts+`) AS c
+			JOIN queries AS q ON q.key = c.key
+		)
+		SELECT key, hits, last_hit_at, upper_limit, rank FROM ranked
+		WHERE `+after+`
+		ORDER BY rank
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query statistics ranking: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		entries []entity.StatisticsEntry
+		queries []entity.FizzBuzzQuery
+	)
+	for rows.Next() {
+		var (
+			entry     entity.StatisticsEntry
+			lastHitAt int64
+			limit     int
+		)
+		if err := rows.Scan(&entry.Key, &entry.HitCount, &lastHitAt, &limit, &entry.Rank); err != nil {
+			return nil, fmt.Errorf("read statistics ranking: %w", err)
+		}
+		entry.LastHitAt = time.Unix(0, lastHitAt)
+		entries = append(entries, entry)
+		queries = append(queries, entity.FizzBuzzQuery{UpperLimit: limit})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read statistics ranking: %w", err)
+	}
+	rows.Close()
+
+	if err := r.loadRules(ctx, entries, queries); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Query = queries[i].ToResponse()
+	}
+	return entries, nil
+}
+
+// GetClients returns a page of the ranking of clients, see
+// application.StatisticsRepository
+func (r *StatisticsRepository) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	var args []any
+	after := "1"
+	if c := query.After; c != nil {
+		after = `(hits < ? OR (hits = ? AND (last_hit_at < ? OR (last_hit_at = ? AND client > ?))))`
+		at := c.LastHitAt.UnixNano()
+		args = append(args, c.HitCount, c.HitCount, at, at, c.Key)
+	}
+	args = append(args, query.Limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT client, SUM(hits) AS hits, MAX(last_hit_at) AS last_hit_at,
+				ROW_NUMBER() OVER (ORDER BY SUM(hits) DESC, MAX(last_hit_at) DESC, client) AS rank
+			FROM client_query_hits
+			GROUP BY client
+		)
+		SELECT client, hits, last_hit_at, rank FROM ranked
+		WHERE `+after+`
+		ORDER BY rank
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query client statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entity.ClientEntry
+	for rows.Next() {
+		var (
+			entry     entity.ClientEntry
+			lastHitAt int64
+		)
+		if err := rows.Scan(&entry.Client, &entry.HitCount, &lastHitAt, &entry.Rank); err != nil {
+			return nil, fmt.Errorf("read client statistics: %w", err)
+		}
+		entry.LastHitAt = time.Unix(0, lastHitAt)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read client statistics: %w", err)
+	}
+	rows.Close()
+
+	// One indexed lookup per client of the page, which is bounded
+	for i := range entries {
+		top, err := r.GetTop(ctx, entity.TopQuery{Limit: 1, Client: entries[i].Client})
+		if err != nil {
+			return nil, err
+		}
+		if len(top) > 0 {
+			entries[i].MostFrequentQuery = top[0].Query
+			entries[i].MostFrequentHits = top[0].HitCount
+		}
+	}
+	return entries, nil
+}
+
+// loadRules fills the rules of the queries of a page
+func (r *StatisticsRepository) loadRules(ctx context.Context, entries []entity.StatisticsEntry, queries []entity.FizzBuzzQuery) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(entries))
+	args := make([]any, len(entries))
+	for i, entry := range entries {
+		index[entry.Key] = i
+		args[i] = entry.Key
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT query_key, divisor, word FROM query_rules
+		WHERE query_key IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		ORDER BY query_key, position`, args...)
+	if err != nil {
+		return fmt.Errorf("query statistics rules: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key  string
+			rule entity.Rule
+		)
+		if err := rows.Scan(&key, &rule.Divisor, &rule.Word); err != nil {
+			return fmt.Errorf("read statistics rules: %w", err)
+		}
+		i := index[key]
+		queries[i].Rules = append(queries[i].Rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read statistics rules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,856 @@
+// Package statstest provides a conformance suite for statistics adapters.
+//
+// Every implementation of application.StatisticsUpdater and
+// application.StatisticsRepository should pass RunRepositorySuite:
+//
+//	func TestRepository(t *testing.T) {
+//		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+//			return inmemory.NewStatisticsRepository(inmemory.WithClock(c), inmemory.WithMaxClients(maxClients))
+//		})
+//	}
+package statstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+)
+
+// Repository is what every statistics adapter implements
+type Repository interface {
+	application.StatisticsUpdater
+	application.StatisticsRepository
+}
+
+// Factory returns an empty repository reading the time from c and keeping
+// the hits of at most maxClients clients (0 for the adapter's default)
+// It is called once per test; release resources with t.Cleanup.
+type Factory func(t *testing.T, c clock.Clock, maxClients int) Repository
+
+// RunRepositorySuite checks that the repositories built by factory honour
+// the contract of the statistics ports
+func RunRepositorySuite(t *testing.T, factory Factory) {
+	t.Run("UpdateStats", func(t *testing.T) { testUpdateStats(t, factory) })
+	t.Run("GetMostFrequent", func(t *testing.T) { testGetMostFrequent(t, factory) })
+	t.Run("EmptyState", func(t *testing.T) { testEmptyState(t, factory) })
+	t.Run("GetTop", func(t *testing.T) { testGetTop(t, factory) })
+	t.Run("Ties", func(t *testing.T) { testTies(t, factory) })
+	t.Run("Windows", func(t *testing.T) { testWindows(t, factory) })
+	t.Run("Rules", func(t *testing.T) { testRules(t, factory) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, factory) })
+	t.Run("ContextCancellation", func(t *testing.T) { testContextCancellation(t, factory) })
+	t.Run("UpdateStatsBatch", func(t *testing.T) { testUpdateStatsBatch(t, factory) })
+	t.Run("Clients", func(t *testing.T) { testClients(t, factory) })
+	t.Run("MaxClients", func(t *testing.T) { testMaxClients(t, factory) })
+}
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func queryWithLimit(limit int) entity.FizzBuzzQuery {
+	return entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: limit,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+}
+
+func testUpdateStats(t *testing.T, factory Factory) {
+	t.Run("increments count for same query", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query := entity.FizzBuzzQuery{
+			FirstDivisor:  3,
+			SecondDivisor: 5,
+			UpperLimit:    15,
+			FirstString:   "fizz",
+			SecondString:  "buzz",
+		}
+
+		// Update 5 times
+		for i := 0; i < 5; i++ {
+			err := repo.UpdateStats(ctx, query, entity.Caller{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		stats, err := repo.GetMostFrequent(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats.HitCount != 5 {
+			t.Errorf("expected 5 hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("tracks different queries separately", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query1 := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		query2 := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 100, // Different limit
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		// Query1: 3 times
+		for i := 0; i < 3; i++ {
+			repo.UpdateStats(ctx, query1, entity.Caller{})
+		}
+
+		// Query2: 5 times
+		for i := 0; i < 5; i++ {
+			repo.UpdateStats(ctx, query2, entity.Caller{})
+		}
+
+		stats, _ := repo.GetMostFrequent(ctx)
+
+		if stats.HitCount != 5 {
+			t.Errorf("expected most frequent to have 5 hits, got %d", stats.HitCount)
+		}
+
+		if stats.MostFrequentQuery.Limit != 100 {
+			t.Errorf("expected most frequent query limit to be 100, got %d", stats.MostFrequentQuery.Limit)
+		}
+	})
+
+	t.Run("different strings are tracked separately", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query1 := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		query2 := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "foo", SecondString: "bar", // Different strings
+		}
+
+		repo.UpdateStats(ctx, query1, entity.Caller{})
+		repo.UpdateStats(ctx, query2, entity.Caller{})
+		repo.UpdateStats(ctx, query2, entity.Caller{})
+
+		stats, _ := repo.GetMostFrequent(ctx)
+
+		if stats.MostFrequentQuery.Str1 != "foo" {
+			t.Errorf("expected most frequent str1 to be 'foo', got %q", stats.MostFrequentQuery.Str1)
+		}
+	})
+}
+
+func testGetMostFrequent(t *testing.T, factory Factory) {
+	t.Run("returns nil query when empty", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+
+		stats, err := repo.GetMostFrequent(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats.MostFrequentQuery != nil {
+			t.Errorf("expected nil query, got %+v", stats.MostFrequentQuery)
+		}
+
+		if stats.HitCount != 0 {
+			t.Errorf("expected 0 hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("returns correct JSON format", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query := entity.FizzBuzzQuery{
+			FirstDivisor:  3,
+			SecondDivisor: 5,
+			UpperLimit:    15,
+			FirstString:   "fizz",
+			SecondString:  "buzz",
+		}
+
+		repo.UpdateStats(ctx, query, entity.Caller{})
+
+		stats, _ := repo.GetMostFrequent(ctx)
+
+		// Verify the response format uses API field names
+		if stats.MostFrequentQuery.Int1 != 3 {
+			t.Errorf("expected Int1=3, got %d", stats.MostFrequentQuery.Int1)
+		}
+		if stats.MostFrequentQuery.Int2 != 5 {
+			t.Errorf("expected Int2=5, got %d", stats.MostFrequentQuery.Int2)
+		}
+		if stats.MostFrequentQuery.Limit != 15 {
+			t.Errorf("expected Limit=15, got %d", stats.MostFrequentQuery.Limit)
+		}
+	})
+}
+
+func testGetTop(t *testing.T, factory Factory) {
+	t.Run("ranks by hits then most recent hit", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		// limit=10: 3 hits, limit=20: 2 hits, limit=30: 2 hits (hit last)
+		for _, limit := range []int{10, 10, 20, 10, 30, 20} {
+			repo.UpdateStats(ctx, queryWithLimit(limit), entity.Caller{})
+			time.Sleep(time.Millisecond)
+		}
+		repo.UpdateStats(ctx, queryWithLimit(30), entity.Caller{})
+
+		entries, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []int{10, 30, 20}
+		if len(entries) != len(expected) {
+			t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+		}
+		for i, limit := range expected {
+			if entries[i].Query.Limit != limit {
+				t.Errorf("rank %d: expected limit %d, got %d", i+1, limit, entries[i].Query.Limit)
+			}
+			if entries[i].Rank != i+1 {
+				t.Errorf("expected rank %d, got %d", i+1, entries[i].Rank)
+			}
+			if entries[i].LastHitAt.IsZero() {
+				t.Errorf("rank %d: expected last hit time", i+1)
+			}
+		}
+	})
+
+	t.Run("most frequent agrees with the ranking under ties", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		for i := 1; i <= 20; i++ {
+			repo.UpdateStats(ctx, queryWithLimit(i), entity.Caller{})
+		}
+
+		for i := 0; i < 10; i++ {
+			top, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 1})
+			stats, _ := repo.GetMostFrequent(ctx)
+
+			if stats.MostFrequentQuery.Limit != top[0].Query.Limit {
+				t.Fatalf("expected most frequent limit %d, got %d", top[0].Query.Limit, stats.MostFrequentQuery.Limit)
+			}
+		}
+	})
+
+	t.Run("pages follow the cursor without gaps or duplicates", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		for i := 1; i <= 25; i++ {
+			for j := 0; j < i%4; j++ {
+				repo.UpdateStats(ctx, queryWithLimit(i), entity.Caller{})
+			}
+			repo.UpdateStats(ctx, queryWithLimit(i), entity.Caller{})
+		}
+
+		seen := make(map[string]bool)
+		var after *entity.RankCursor
+		for page := 0; ; page++ {
+			entries, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10, After: after})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) == 0 {
+				break
+			}
+			for i, entry := range entries {
+				key := fmt.Sprint(entry.Query.Limit)
+				if seen[key] {
+					t.Errorf("page %d: duplicate entry %s", page, key)
+				}
+				seen[key] = true
+				if entry.Rank != page*10+i+1 {
+					t.Errorf("page %d: expected rank %d, got %d", page, page*10+i+1, entry.Rank)
+				}
+			}
+			cursor := entity.CursorOf(entries[len(entries)-1])
+			after = &cursor
+		}
+
+		if len(seen) != 25 {
+			t.Errorf("expected 25 distinct entries, got %d", len(seen))
+		}
+	})
+}
+
+func testWindows(t *testing.T, factory Factory) {
+	old := entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 100,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+	recent := entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+
+	setup := func(t *testing.T) (Repository, *fakeClock) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		repo := factory(t, clock, 0)
+		ctx := context.Background()
+
+		// 10 hits three hours ago, 3 hits 30 minutes ago, 2 hits now
+		for i := 0; i < 10; i++ {
+			repo.UpdateStats(ctx, old, entity.Caller{})
+		}
+		clock.Advance(150 * time.Minute)
+		for i := 0; i < 3; i++ {
+			repo.UpdateStats(ctx, recent, entity.Caller{})
+		}
+		clock.Advance(30 * time.Minute)
+		for i := 0; i < 2; i++ {
+			repo.UpdateStats(ctx, recent, entity.Caller{})
+		}
+
+		return repo, clock
+	}
+
+	tests := []struct {
+		name          string
+		window        time.Duration
+		expectedLimit int
+		expectedHits  int64
+		expectedCount int
+	}{
+		{name: "all time", window: 0, expectedLimit: 100, expectedHits: 10, expectedCount: 2},
+		{name: "last 5 minutes", window: 5 * time.Minute, expectedLimit: 15, expectedHits: 2, expectedCount: 1},
+		{name: "last hour", window: time.Hour, expectedLimit: 15, expectedHits: 5, expectedCount: 1},
+		{name: "last day", window: 24 * time.Hour, expectedLimit: 100, expectedHits: 10, expectedCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := setup(t)
+
+			entries, err := repo.GetTop(context.Background(), entity.TopQuery{Limit: 10, Window: tt.window})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(entries) != tt.expectedCount {
+				t.Fatalf("expected %d entries, got %d", tt.expectedCount, len(entries))
+			}
+			if entries[0].Query.Limit != tt.expectedLimit || entries[0].HitCount != tt.expectedHits {
+				t.Errorf("expected limit %d with %d hits, got limit %d with %d hits",
+					tt.expectedLimit, tt.expectedHits, entries[0].Query.Limit, entries[0].HitCount)
+			}
+		})
+	}
+
+	t.Run("hits expire as time passes", func(t *testing.T) {
+		repo, clock := setup(t)
+
+		clock.Advance(25 * time.Hour)
+
+		entries, _ := repo.GetTop(context.Background(), entity.TopQuery{Limit: 10, Window: 24 * time.Hour})
+		if len(entries) != 0 {
+			t.Errorf("expected no entries after a day, got %d", len(entries))
+		}
+
+		// All-time counts are unaffected
+		stats, _ := repo.GetMostFrequent(context.Background())
+		if stats.HitCount != 10 {
+			t.Errorf("expected 10 all-time hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("buckets are reused after a full rotation", func(t *testing.T) {
+		repo, clock := setup(t)
+
+		clock.Advance(61 * time.Minute)
+		repo.UpdateStats(context.Background(), old, entity.Caller{})
+
+		entries, _ := repo.GetTop(context.Background(), entity.TopQuery{Limit: 10, Window: 5 * time.Minute})
+		if len(entries) != 1 || entries[0].HitCount != 1 {
+			t.Errorf("expected only the new hit, got %+v", entries)
+		}
+	})
+}
+
+func testRules(t *testing.T, factory Factory) {
+	t.Run("rules query is reported with its rules", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query := entity.FizzBuzzQuery{
+			UpperLimit: 105,
+			Rules: []entity.Rule{
+				{Divisor: 3, Word: "fizz"},
+				{Divisor: 5, Word: "buzz"},
+				{Divisor: 7, Word: "bazz"},
+			},
+		}
+
+		repo.UpdateStats(ctx, query, entity.Caller{})
+
+		stats, _ := repo.GetMostFrequent(ctx)
+
+		if len(stats.MostFrequentQuery.Rules) != 3 {
+			t.Fatalf("expected 3 rules, got %+v", stats.MostFrequentQuery.Rules)
+		}
+		if stats.MostFrequentQuery.Rules[2].Word != "bazz" {
+			t.Errorf("expected third rule word 'bazz', got %q", stats.MostFrequentQuery.Rules[2].Word)
+		}
+		if stats.MostFrequentQuery.Int1 != 0 {
+			t.Errorf("expected int1 to be omitted for a three-rule query, got %d", stats.MostFrequentQuery.Int1)
+		}
+	})
+
+	t.Run("pair and two-rule forms are counted together", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		pair := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+		rules := entity.FizzBuzzQuery{
+			UpperLimit: 15,
+			Rules: []entity.Rule{
+				{Divisor: 3, Word: "fizz"},
+				{Divisor: 5, Word: "buzz"},
+			},
+		}
+
+		repo.UpdateStats(ctx, pair, entity.Caller{})
+		repo.UpdateStats(ctx, rules, entity.Caller{})
+
+		stats, _ := repo.GetMostFrequent(ctx)
+
+		if stats.HitCount != 2 {
+			t.Errorf("expected 2 hits, got %d", stats.HitCount)
+		}
+		if stats.MostFrequentQuery.Int1 != 3 {
+			t.Errorf("expected Int1=3, got %d", stats.MostFrequentQuery.Int1)
+		}
+	})
+}
+
+func testConcurrency(t *testing.T, factory Factory) {
+	t.Run("handles concurrent updates safely", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		const numGoroutines = 100
+		const updatesPerGoroutine = 100
+
+		var wg sync.WaitGroup
+		wg.Add(numGoroutines)
+
+		for i := 0; i < numGoroutines; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < updatesPerGoroutine; j++ {
+					repo.UpdateStats(ctx, query, entity.Caller{})
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		stats, err := repo.GetMostFrequent(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := int64(numGoroutines * updatesPerGoroutine)
+		if stats.HitCount != expected {
+			t.Errorf("expected %d hits, got %d", expected, stats.HitCount)
+		}
+	})
+
+	t.Run("handles concurrent reads and writes", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		query := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		// Pre-populate
+		repo.UpdateStats(ctx, query, entity.Caller{})
+
+		const numGoroutines = 50
+		var wg sync.WaitGroup
+		wg.Add(numGoroutines * 2) // Half writers, half readers
+
+		// Writers
+		for i := 0; i < numGoroutines; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					repo.UpdateStats(ctx, query, entity.Caller{})
+				}
+			}()
+		}
+
+		// Readers
+		for i := 0; i < numGoroutines; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					stats, err := repo.GetMostFrequent(ctx)
+					if err != nil {
+						t.Errorf("read error: %v", err)
+					}
+					if stats.HitCount < 1 {
+						t.Errorf("expected at least 1 hit")
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+}
+
+func testEmptyState(t *testing.T, factory Factory) {
+	repo := factory(t, clock.System{}, 0)
+	ctx := context.Background()
+
+	entries, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+
+	entries, err = repo.GetTop(ctx, entity.TopQuery{Limit: 10, Window: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no windowed entries, got %d", len(entries))
+	}
+}
+
+func testTies(t *testing.T, factory Factory) {
+	t.Run("equal hits at the same time are ordered by key", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		repo := factory(t, clock, 0)
+		ctx := context.Background()
+
+		for _, limit := range []int{30, 10, 20} {
+			repo.UpdateStats(ctx, queryWithLimit(limit), entity.Caller{})
+		}
+
+		entries, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 3 {
+			t.Fatalf("expected 3 entries, got %d", len(entries))
+		}
+		for i := 1; i < len(entries); i++ {
+			if entries[i-1].Key >= entries[i].Key {
+				t.Errorf("expected keys in ascending order, got %q before %q", entries[i-1].Key, entries[i].Key)
+			}
+		}
+
+		stats, _ := repo.GetMostFrequent(ctx)
+		if stats.MostFrequentQuery.Limit != entries[0].Query.Limit {
+			t.Errorf("expected most frequent limit %d, got %d", entries[0].Query.Limit, stats.MostFrequentQuery.Limit)
+		}
+	})
+
+	t.Run("the most recent hit wins among equal counts", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		repo := factory(t, clock, 0)
+		ctx := context.Background()
+
+		repo.UpdateStats(ctx, queryWithLimit(10), entity.Caller{})
+		clock.Advance(time.Second)
+		repo.UpdateStats(ctx, queryWithLimit(20), entity.Caller{})
+
+		stats, _ := repo.GetMostFrequent(ctx)
+		if stats.MostFrequentQuery.Limit != 20 {
+			t.Errorf("expected most frequent limit 20, got %d", stats.MostFrequentQuery.Limit)
+		}
+	})
+
+	t.Run("a cursor on a tie resumes after it", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		repo := factory(t, clock, 0)
+		ctx := context.Background()
+
+		for i := 1; i <= 5; i++ {
+			repo.UpdateStats(ctx, queryWithLimit(i), entity.Caller{})
+		}
+
+		first, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 2})
+		cursor := entity.CursorOf(first[1])
+		rest, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10, After: &cursor})
+
+		if len(rest) != 3 {
+			t.Fatalf("expected 3 remaining entries, got %d", len(rest))
+		}
+		if rest[0].Rank != 3 {
+			t.Errorf("expected rank 3, got %d", rest[0].Rank)
+		}
+	})
+}
+
+// testContextCancellation checks that a cancelled context never corrupts the
+// counts: an update either fails with context.Canceled and is not counted, or
+// succeeds and is counted. Reads either fail the same way or are consistent.
+func testContextCancellation(t *testing.T, factory Factory) {
+	repo := factory(t, clock.System{}, 0)
+	query := queryWithLimit(15)
+
+	repo.UpdateStats(context.Background(), query, entity.Caller{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expected := int64(1)
+	switch err := repo.UpdateStats(ctx, query, entity.Caller{}); {
+	case err == nil:
+		expected++
+	case !errors.Is(err, context.Canceled):
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if stats, err := repo.GetMostFrequent(ctx); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	} else if stats.HitCount != expected {
+		t.Errorf("expected %d hits, got %d", expected, stats.HitCount)
+	}
+
+	if _, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10}); err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// The repository remains usable
+	if err := repo.UpdateStats(context.Background(), query, entity.Caller{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, _ := repo.GetMostFrequent(context.Background())
+	if stats.HitCount != expected+1 {
+		t.Errorf("expected %d hits, got %d", expected+1, stats.HitCount)
+	}
+}
+
+// testUpdateStatsBatch applies to repositories implementing application.BatchStatisticsUpdater
+func testUpdateStatsBatch(t *testing.T, factory Factory) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := factory(t, clock, 0)
+	ctx := context.Background()
+
+	batch, ok := repo.(application.BatchStatisticsUpdater)
+	if !ok {
+		t.Skip("repository does not implement application.BatchStatisticsUpdater")
+	}
+
+	repo.UpdateStats(ctx, queryWithLimit(10), entity.Caller{})
+	clock.Advance(time.Minute)
+
+	err := batch.UpdateStatsBatch(ctx, []application.HitDelta{
+		{Query: queryWithLimit(10), Hits: 2},
+		{Query: queryWithLimit(20), Hits: 5},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Query.Limit != 20 || entries[0].HitCount != 5 {
+		t.Errorf("expected limit 20 with 5 hits first, got limit %d with %d hits",
+			entries[0].Query.Limit, entries[0].HitCount)
+	}
+	if entries[1].HitCount != 3 || !entries[1].LastHitAt.Equal(clock.Now()) {
+		t.Errorf("expected 3 hits last recorded at %v, got %d at %v",
+			clock.Now(), entries[1].HitCount, entries[1].LastHitAt)
+	}
+
+	recent, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10, Window: time.Minute})
+	if len(recent) != 2 || recent[1].HitCount != 3 {
+		t.Errorf("expected batched hits in the last minute, got %+v", recent)
+	}
+}
+
+func testClients(t *testing.T, factory Factory) {
+	alice := entity.Caller{ID: "api_key:alice"}
+	bob := entity.Caller{ID: "jwt:bob"}
+
+	t.Run("hits are counted per client and globally", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		for range 3 {
+			repo.UpdateStats(ctx, queryWithLimit(10), alice)
+		}
+		repo.UpdateStats(ctx, queryWithLimit(20), alice)
+		repo.UpdateStats(ctx, queryWithLimit(20), bob)
+		repo.UpdateStats(ctx, queryWithLimit(20), bob)
+		repo.UpdateStats(ctx, queryWithLimit(30), entity.Caller{})
+
+		mine, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10, Client: alice.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mine) != 2 || mine[0].Query.Limit != 10 || mine[0].HitCount != 3 || mine[1].HitCount != 1 {
+			t.Errorf("expected limit 10 (3 hits) then limit 20 (1 hit) for alice, got %+v", mine)
+		}
+		if mine[0].Rank != 1 || mine[1].Rank != 2 || mine[0].LastHitAt.IsZero() {
+			t.Errorf("expected ranked entries with last hit times, got %+v", mine)
+		}
+
+		global, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10})
+		if len(global) != 3 || global[0].Query.Limit != 20 || global[0].HitCount != 3 {
+			t.Errorf("expected limit 20 first with 3 hits globally, got %+v", global)
+		}
+
+		unknown, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10, Client: "ip:192.0.2.1"})
+		if len(unknown) != 0 {
+			t.Errorf("expected no entries for an unknown client, got %+v", unknown)
+		}
+	})
+
+	t.Run("clients are ranked with their most frequent request", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		for range 3 {
+			repo.UpdateStats(ctx, queryWithLimit(10), alice)
+		}
+		repo.UpdateStats(ctx, queryWithLimit(20), alice)
+		repo.UpdateStats(ctx, queryWithLimit(20), bob)
+		repo.UpdateStats(ctx, queryWithLimit(30), entity.Caller{})
+
+		clients, err := repo.GetClients(ctx, entity.ClientQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(clients) != 2 {
+			t.Fatalf("expected 2 clients (anonymous hits excluded), got %+v", clients)
+		}
+
+		first := clients[0]
+		if first.Client != alice.ID || first.HitCount != 4 || first.Rank != 1 || first.LastHitAt.IsZero() {
+			t.Errorf("expected alice first with 4 hits, got %+v", first)
+		}
+		if first.MostFrequentQuery == nil || first.MostFrequentQuery.Limit != 10 || first.MostFrequentHits != 3 {
+			t.Errorf("expected alice's most frequent request to be limit 10 with 3 hits, got %+v", first)
+		}
+		if clients[1].Client != bob.ID || clients[1].HitCount != 1 || clients[1].Rank != 2 {
+			t.Errorf("expected bob second with 1 hit, got %+v", clients[1])
+		}
+	})
+
+	t.Run("client pages follow the cursor", func(t *testing.T) {
+		repo := factory(t, clock.System{}, 0)
+		ctx := context.Background()
+
+		for i := 1; i <= 7; i++ {
+			caller := entity.Caller{ID: fmt.Sprintf("ip:192.0.2.%d", i)}
+			for range i % 3 {
+				repo.UpdateStats(ctx, queryWithLimit(i), caller)
+			}
+			repo.UpdateStats(ctx, queryWithLimit(i), caller)
+		}
+
+		seen := make(map[string]bool)
+		var after *entity.RankCursor
+		for page := 0; ; page++ {
+			clients, err := repo.GetClients(ctx, entity.ClientQuery{Limit: 3, After: after})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(clients) == 0 {
+				break
+			}
+			for i, client := range clients {
+				if seen[client.Client] {
+					t.Errorf("page %d: duplicate client %s", page, client.Client)
+				}
+				seen[client.Client] = true
+				if client.Rank != page*3+i+1 {
+					t.Errorf("page %d: expected rank %d, got %d", page, page*3+i+1, client.Rank)
+				}
+			}
+			cursor := clients[len(clients)-1].Cursor()
+			after = &cursor
+		}
+
+		if len(seen) != 7 {
+			t.Errorf("expected 7 distinct clients, got %d", len(seen))
+		}
+	})
+}
+
+func testMaxClients(t *testing.T, factory Factory) {
+	c := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := factory(t, c, 2)
+	ctx := context.Background()
+
+	// A new hit makes .1 the most recent client, so .2 is forgotten for .3
+	for _, id := range []string{"ip:192.0.2.1", "ip:192.0.2.2", "ip:192.0.2.1", "ip:192.0.2.3"} {
+		if err := repo.UpdateStats(ctx, queryWithLimit(15), entity.Caller{ID: id}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.Advance(time.Second)
+	}
+
+	clients, err := repo.GetClients(ctx, entity.ClientQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clients) != 2 || clients[0].Client != "ip:192.0.2.1" || clients[1].Client != "ip:192.0.2.3" {
+		t.Errorf("expected clients .1 and .3, got %+v", clients)
+	}
+
+	forgotten, _ := repo.GetTop(ctx, entity.TopQuery{Limit: 10, Client: "ip:192.0.2.2"})
+	if len(forgotten) != 0 {
+		t.Errorf("expected no entries for a forgotten client, got %+v", forgotten)
+	}
+	if stats, _ := repo.GetMostFrequent(ctx); stats.HitCount != 4 {
+		t.Errorf("expected forgotten clients to stay in global counts, got %d hits", stats.HitCount)
+	}
+}
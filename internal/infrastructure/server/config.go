@@ -4,14 +4,27 @@ import "time"
 
 // Config holds only what we actually need
 type Config struct {
-	Port        string
-	StopTimeout time.Duration
+	Port string
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound each
+	// connection to prevent resource exhaustion (see http.Server)
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	StopTimeout       time.Duration
+	// DrainDelay is how long the server keeps serving once shutdown starts,
+	// after the OnDrain hooks, so load balancers see it unready and move away
+	DrainDelay time.Duration
 }
 
 // Default returns sensible defaults
 func Default() Config {
 	return Config{
-		Port:        "8080",
-		StopTimeout: 10 * time.Second,
+		Port:              "8080",
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		StopTimeout:       10 * time.Second,
 	}
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,32 +14,93 @@ import (
 
 // Server manages HTTP server lifecycle
 type Server struct {
-	config Config
-	server *http.Server
-	logger *slog.Logger
+	config     Config
+	server     *http.Server
+	admin      *http.Server
+	logger     *slog.Logger
+	onDrain    []func()
+	onReload   []func()
+	onShutdown []func(context.Context) error
 }
 
-// New creates a server instance with production-ready timeouts
+// New creates a server instance with the timeouts of config
 func New(config Config, handler http.Handler, logger *slog.Logger) *Server {
 	return &Server{
 		config: config,
 		server: &http.Server{
-			Addr:    ":" + config.Port,
-			Handler: handler,
-			// Production timeouts to prevent resource exhaustion
-			ReadTimeout:       5 * time.Second,
-			ReadHeaderTimeout: 2 * time.Second,
-			WriteTimeout:      10 * time.Second,
-			IdleTimeout:       120 * time.Second,
+			Addr:              ":" + config.Port,
+			Handler:           handler,
+			ReadTimeout:       config.ReadTimeout,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
 		},
 		logger: logger,
 	}
 }
 
+// ServeAdmin also serves handler on a separate port (e.g. metrics), kept off
+// the public port; it stays up until the main server has drained
+func (s *Server) ServeAdmin(port string, handler http.Handler) {
+	s.admin = &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+	}
+}
+
+// OnDrain registers a hook run as soon as shutdown starts, while requests are
+// still served (e.g. failing the readiness probe), before DrainDelay elapses
+func (s *Server) OnDrain(hook func()) {
+	s.onDrain = append(s.onDrain, hook)
+}
+
+// OnReload registers a hook run on SIGHUP (e.g. reloading the configuration)
+// Without hooks, SIGHUP keeps its default behavior.
+func (s *Server) OnReload(hook func()) {
+	s.onReload = append(s.onReload, hook)
+}
+
+// OnShutdown registers a hook run during graceful shutdown, once in-flight
+// requests are drained (e.g. flushing buffered statistics)
+// Hooks run in registration order and share the shutdown timeout. They run
+// even when requests outlive it, with a timeout of their own.
+func (s *Server) OnShutdown(hook func(ctx context.Context) error) {
+	s.onShutdown = append(s.onShutdown, hook)
+}
+
 // Run starts the server and blocks until shutdown signal
 func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(s.onReload) > 0 {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		go func() {
+			for {
+				select {
+				case <-hup:
+					s.logger.Info("reload signal received")
+					for _, hook := range s.onReload {
+						hook()
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	return s.RunContext(ctx)
+}
+
+// RunContext starts the server and shuts it down gracefully once ctx is done
+func (s *Server) RunContext(ctx context.Context) error {
 	// Channel for server errors
-	serverErr := make(chan error, 1)
+	serverErr := make(chan error, 2)
 
 	// Start server in goroutine
 	go func() {
@@ -48,15 +110,30 @@ func (s *Server) Run() error {
 		}
 	}()
 
-	// Wait for shutdown signal or server error
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	if s.admin != nil {
+		go func() {
+			s.logger.Info("admin server starting", "addr", s.admin.Addr)
+			if err := s.admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErr <- fmt.Errorf("admin: %w", err)
+			}
+		}()
+	}
 
+	// Wait for shutdown signal or server error
 	select {
 	case err := <-serverErr:
 		return fmt.Errorf("server failed: %w", err)
-	case sig := <-stop:
-		s.logger.Info("shutdown signal received", "signal", sig.String())
+	case <-ctx.Done():
+		s.logger.Info("shutdown signal received", "cause", context.Cause(ctx).Error())
+	}
+
+	// Keep serving while load balancers notice we are no longer ready
+	for _, hook := range s.onDrain {
+		hook()
+	}
+	if s.config.DrainDelay > 0 {
+		s.logger.Info("draining", "delay", s.config.DrainDelay)
+		time.Sleep(s.config.DrainDelay)
 	}
 
 	// Graceful shutdown
@@ -65,8 +142,33 @@ func (s *Server) Run() error {
 
 	s.logger.Info("shutting down server", "timeout", s.config.StopTimeout)
 
+	var shutdownErr error
 	if err := s.server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("shutdown failed: %w", err)
+		shutdownErr = fmt.Errorf("shutdown failed: %w", err)
+		// Cut the requests still running (e.g. long streams), then give the
+		// hooks a timeout of their own so what was recorded is still flushed
+		s.logger.Warn("requests outlived the shutdown timeout", "error", err)
+		s.server.Close()
+		ctx, cancel = context.WithTimeout(context.Background(), s.config.StopTimeout)
+		defer cancel()
+	}
+
+	var hookErrs []error
+	for _, hook := range s.onShutdown {
+		if err := hook(ctx); err != nil {
+			hookErrs = append(hookErrs, err)
+		}
+	}
+	if s.admin != nil {
+		if err := s.admin.Shutdown(ctx); err != nil {
+			hookErrs = append(hookErrs, fmt.Errorf("admin shutdown: %w", err))
+		}
+	}
+	if err := errors.Join(hookErrs...); err != nil {
+		shutdownErr = errors.Join(shutdownErr, fmt.Errorf("shutdown hooks failed: %w", err))
+	}
+	if shutdownErr != nil {
+		return shutdownErr
 	}
 
 	s.logger.Info("server stopped gracefully")
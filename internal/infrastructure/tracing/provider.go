@@ -0,0 +1,119 @@
+// Package tracing sets up OpenTelemetry tracing for the service.
+//
+// Spans are exported to stdout, to a file (one JSON span per line) or to an
+// OpenTelemetry collector over OTLP/HTTP. Incoming requests continue the trace
+// of their W3C traceparent header (see the HTTP tracing middleware).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where spans are exported
+type Config struct {
+	// Exporter is "none", "stdout", "file" or "otlp"
+	Exporter string
+	// File is the path spans are appended to with the "file" exporter
+	File string
+	// Endpoint is the OTLP/HTTP traces URL of the "otlp" exporter
+	Endpoint string
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1
+	// Requests continuing a trace follow the decision of their parent.
+	SampleRatio float64
+	// ServiceName identifies the service in the exported spans
+	ServiceName string
+}
+
+// Provider creates tracers and exports their spans
+type Provider struct {
+	trace.TracerProvider
+	shutdown func(context.Context) error
+}
+
+// Enabled reports whether spans are exported
+func (p *Provider) Enabled() bool {
+	_, disabled := p.TracerProvider.(noop.TracerProvider)
+	return !disabled
+}
+
+// Shutdown exports the remaining spans and releases the exporter
+// Safe to call more than once.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+// NewProvider creates the provider of the configured exporter
+// With ExporterNone, tracing is a no-op.
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch config.Exporter {
+	case ExporterNone, "":
+		return &Provider{
+			TracerProvider: noop.NewTracerProvider(),
+			shutdown:       func(context.Context) error { return nil },
+		}, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open traces file: %w", err)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.Endpoint))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q (expected %s, %s, %s or %s)",
+			config.Exporter, ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP)
+	}
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, fmt.Errorf("create %s trace exporter: %w", config.Exporter, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+	return &Provider{
+		TracerProvider: tp,
+		shutdown: func(ctx context.Context) error {
+			err := tp.Shutdown(ctx)
+			if closer != nil {
+				if closeErr := closer.Close(); closeErr != nil && err == nil {
+					err = closeErr
+				}
+				closer = nil
+			}
+			return err
+		},
+	}, nil
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of this package
+const tracerName = "fizzbuzz-service/internal/infrastructure/tracing"
+
+// StatisticsStore is a statistics backend, read and written
+type StatisticsStore interface {
+	application.StatisticsUpdater
+	application.StatisticsRepository
+}
+
+// TraceStatistics decorates store so each call of the statistics ports is a span
+// The result implements application.BatchStatisticsUpdater when store does,
+// so batching still goes through a single call.
+func TraceStatistics(store StatisticsStore, tp trace.TracerProvider) StatisticsStore {
+	traced := &tracedStatistics{store: store, tracer: tp.Tracer(tracerName)}
+	if batch, ok := store.(application.BatchStatisticsUpdater); ok {
+		return &tracedBatchStatistics{tracedStatistics: traced, batch: batch}
+	}
+	return traced
+}
+
+type tracedStatistics struct {
+	store  StatisticsStore
+	tracer trace.Tracer
+}
+
+func (s *tracedStatistics) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	ctx, span := s.tracer.Start(ctx, "StatisticsUpdater.UpdateStats")
+	defer span.End()
+
+	return recordError(span, s.store.UpdateStats(ctx, query, caller))
+}
+
+func (s *tracedStatistics) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
+	ctx, span := s.tracer.Start(ctx, "StatisticsRepository.GetMostFrequent")
+	defer span.End()
+
+	summary, err := s.store.GetMostFrequent(ctx)
+	return summary, recordError(span, err)
+}
+
+func (s *tracedStatistics) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	ctx, span := s.tracer.Start(ctx, "StatisticsRepository.GetTop", trace.WithAttributes(
+		attribute.Int("statistics.limit", query.Limit),
+		attribute.String("statistics.window", query.Window.String()),
+	))
+	defer span.End()
+
+	entries, err := s.store.GetTop(ctx, query)
+	span.SetAttributes(attribute.Int("statistics.entries", len(entries)))
+	return entries, recordError(span, err)
+}
+
+func (s *tracedStatistics) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	ctx, span := s.tracer.Start(ctx, "StatisticsRepository.GetClients", trace.WithAttributes(
+		attribute.Int("statistics.limit", query.Limit),
+	))
+	defer span.End()
+
+	entries, err := s.store.GetClients(ctx, query)
+	span.SetAttributes(attribute.Int("statistics.entries", len(entries)))
+	return entries, recordError(span, err)
+}
+
+type tracedBatchStatistics struct {
+	*tracedStatistics
+	batch application.BatchStatisticsUpdater
+}
+
+func (s *tracedBatchStatistics) UpdateStatsBatch(ctx context.Context, deltas []application.HitDelta) error {
+	ctx, span := s.tracer.Start(ctx, "StatisticsUpdater.UpdateStatsBatch", trace.WithAttributes(
+		attribute.Int("statistics.queries", len(deltas)),
+	))
+	defer span.End()
+
+	return recordError(span, s.batch.UpdateStatsBatch(ctx, deltas))
+}
+
+// recordError marks span as failed when err is not nil, and returns err
+func recordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
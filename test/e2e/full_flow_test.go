@@ -27,7 +27,9 @@ func setupTestServer(t *testing.T) (string, func()) {
 	generator := service.NewFizzBuzzGenerator()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	generateUseCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 10000, logger)
+	generateUseCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 10000, logger,
+		application.WithStreamMaxLimit(1<<40),
+	)
 	getStatsUseCase := application.NewGetStatisticsUseCase(statsRepo)
 
 	fizzHandler := handler.NewFizzBuzzHandler(generateUseCase, logger)
@@ -128,14 +130,61 @@ func TestE2E_FizzBuzzEndpoint(t *testing.T) {
 		var result map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&result)
 
-		details, ok := result["details"].([]interface{})
+		if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("expected application/problem+json, got %q", ct)
+		}
+
+		params, ok := result["invalid_params"].([]interface{})
 		if !ok {
-			t.Fatal("expected 'details' array")
+			t.Fatal("expected 'invalid_params' array")
+		}
+
+		if len(params) < 4 {
+			t.Errorf("expected at least 4 validation errors, got %d", len(params))
+		}
+	})
+}
+
+func TestE2E_StreamEndpoint(t *testing.T) {
+	addr, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	t.Run("client disconnect stops an endless stream", func(t *testing.T) {
+		body := map[string]interface{}{
+			"int1": 3, "int2": 5, "limit": 1 << 40,
+			"str1": "fizz", "str2": "buzz",
 		}
 
-		if len(details) < 4 {
-			t.Errorf("expected at least 4 validation errors, got %d", len(details))
+		resp, err := http.Post(
+			fmt.Sprintf("http://%s/fizzbuzz/stream", addr),
+			"application/json",
+			bytes.NewBuffer(mustMarshal(body)),
+		)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+
+		// Results arrive before the sequence is complete
+		head := make([]byte, 64)
+		if _, err := io.ReadFull(resp.Body, head); err != nil {
+			t.Fatalf("failed to read stream head: %v", err)
+		}
+		if !bytes.HasPrefix(head, []byte(`{"result":["1","2","fizz"`)) {
+			t.Errorf("unexpected stream head %q", head)
+		}
+
+		resp.Body.Close()
+
+		// The server keeps serving other requests
+		health, err := http.Get(fmt.Sprintf("http://%s/health", addr))
+		if err != nil {
+			t.Fatalf("health request failed: %v", err)
 		}
+		health.Body.Close()
 	})
 }
 
@@ -0,0 +1,167 @@
+package e2e_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/health"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/server"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// freePort returns a port nothing listens on right now
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func TestE2E_GracefulShutdownDrainsReadiness(t *testing.T) {
+	readiness := health.NewReadiness(0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r := chi.NewRouter()
+	handler.NewHealthHandler(handler.WithReadiness(readiness)).RegisterRoutes(r)
+
+	cfg := server.Default()
+	cfg.Port = freePort(t)
+	cfg.DrainDelay = 300 * time.Millisecond
+	srv := server.New(cfg, r, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	srv.OnDrain(readiness.Drain)
+	srv.OnDrain(func() { record("drain") })
+	srv.OnShutdown(func(context.Context) error {
+		record("shutdown")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.RunContext(ctx) }()
+
+	readyz := fmt.Sprintf("http://127.0.0.1:%s/readyz", cfg.Port)
+	waitForStatus(t, readyz, http.StatusOK)
+
+	cancel()
+
+	// Still serving, but reported unready before the server shuts down
+	waitForStatus(t, readyz, http.StatusServiceUnavailable)
+	mu.Lock()
+	if len(events) != 1 || events[0] != "drain" {
+		t.Errorf("expected only the drain hook before shutdown, got %v", events)
+	}
+	mu.Unlock()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	if len(events) != 2 || events[1] != "shutdown" {
+		t.Errorf("expected drain then shutdown, got %v", events)
+	}
+	if _, err := http.Get(readyz); err == nil {
+		t.Error("expected the server to stop accepting connections")
+	}
+}
+
+func TestE2E_ShutdownTimeoutStillRunsHooks(t *testing.T) {
+	started := make(chan struct{})
+	r := chi.NewRouter()
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	cfg := server.Default()
+	cfg.Port = freePort(t)
+	cfg.DrainDelay = 0
+	cfg.StopTimeout = 100 * time.Millisecond
+	srv := server.New(cfg, r, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var flushed error
+	srv.OnShutdown(func(ctx context.Context) error {
+		// The hook gets a live context even though the shutdown timed out
+		flushed = ctx.Err()
+		return errors.New("flush failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.RunContext(ctx) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%s/slow", cfg.Port)
+	go func() {
+		for {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			select {
+			case <-started:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the shutdown timeout to be reported, got %v", err)
+		}
+		if err == nil || !strings.Contains(err.Error(), "flush failed") {
+			t.Errorf("expected the hook error to be reported, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	if flushed != nil {
+		t.Errorf("expected the hook to run with a live context, got %v", flushed)
+	}
+}
+
+// waitForStatus polls url until it answers status
+func waitForStatus(t *testing.T, url string, status int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == status {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s never answered %d", url, status)
+}
@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/infrastructure/auth"
+	"fizzbuzz-service/internal/infrastructure/config"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+type stubReloader struct {
+	changes []config.Change
+	err     error
+}
+
+func (s stubReloader) Reload() ([]config.Change, error) { return s.changes, s.err }
+
+func TestAdminReload(t *testing.T) {
+	keys := auth.NewKeyStore([]auth.APIKey{
+		{ID: "ops", Hash: auth.HashKey("ops-secret"), Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})
+	reload := func(reloader handler.ConfigReloader) *httptest.ResponseRecorder {
+		router := newTestRouter(t,
+			infrahttp.WithAuthentication(keys),
+			infrahttp.WithAdmin(handler.NewAdminHandler(reloader, newTestLogger())),
+		)
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+		req.Header.Set("X-API-Key", "ops-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("not served without authentication", func(t *testing.T) {
+		router := newTestRouter(t, infrahttp.WithAdmin(handler.NewAdminHandler(stubReloader{}, newTestLogger())))
+		for method, target := range map[string]string{http.MethodPost: "/admin/reload", http.MethodGet: "/admin/statistics/clients"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s %s: expected 404, got %d", method, target, w.Code)
+			}
+		}
+	})
+
+	t.Run("lists changes", func(t *testing.T) {
+		w := reload(stubReloader{changes: []config.Change{
+			{Setting: "fizzbuzz.max_limit", Old: "100", New: "200", Applied: true},
+			{Setting: "server.port", Old: "8080", New: "9000", Applied: false},
+		}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+
+		var body struct {
+			Changes []struct {
+				Setting string `json:"setting"`
+				Old     string `json:"old"`
+				New     string `json:"new"`
+				Applied bool   `json:"applied"`
+			} `json:"changes"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Changes) != 2 || body.Changes[0].Setting != "fizzbuzz.max_limit" || !body.Changes[0].Applied ||
+			body.Changes[1].New != "9000" || body.Changes[1].Applied {
+			t.Errorf("unexpected changes %+v", body.Changes)
+		}
+	})
+
+	t.Run("no change is an empty list", func(t *testing.T) {
+		w := reload(stubReloader{})
+		if w.Code != http.StatusOK || w.Body.String() != "{\"changes\":[]}\n" {
+			t.Errorf("unexpected response %d %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("errors map like on other routes", func(t *testing.T) {
+		w := reload(stubReloader{err: domain.NotFoundError{Resource: "configuration file config.yaml"}})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d: %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		w := reload(stubReloader{err: &config.Error{Problems: []string{"fizzbuzz.max_limit (env MAX_LIMIT): \"abc\" is not an integer"}}})
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422, got %d", w.Code)
+		}
+
+		var body problem.Problem
+		json.NewDecoder(w.Body).Decode(&body)
+		if body.Type != "/problems/invalid-configuration" || len(body.InvalidParams) != 1 {
+			t.Errorf("unexpected body %+v", body)
+		}
+		if body.InvalidParams[0].Name != "fizzbuzz.max_limit" {
+			t.Errorf("expected the setting as parameter name, got %q", body.InvalidParams[0].Name)
+		}
+	})
+}
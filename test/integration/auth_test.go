@@ -0,0 +1,80 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	custommw "fizzbuzz-service/internal/infrastructure/http/middleware"
+)
+
+func TestAuthentication(t *testing.T) {
+	keys := auth.NewKeyStore([]auth.APIKey{
+		{ID: "generator", Hash: auth.HashKey("gen-secret"), Scopes: []auth.Scope{auth.ScopeGenerate}},
+		{ID: "reporting", Hash: auth.HashKey("stats-secret"), Scopes: []auth.Scope{auth.ScopeStatsRead}},
+		{ID: "ops", Hash: auth.HashKey("ops-secret"), Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})
+	router := newTestRouter(t,
+		infrahttp.WithAuthentication(keys),
+		infrahttp.WithAdmin(handler.NewAdminHandler(stubReloader{}, newTestLogger())),
+		infrahttp.WithRateLimits(infrahttp.RateLimiters{
+			Statistics: custommw.NewRateLimiter(custommw.RateLimit{Rate: 0.001, Burst: 1}),
+		}),
+	)
+
+	send := func(method, target string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const generate = "/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz"
+
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		headers   []string
+		status    int
+		challenge string
+	}{
+		{"anonymous", http.MethodGet, generate, nil, http.StatusUnauthorized, `Bearer realm="fizzbuzz-service"`},
+		{"unknown key", http.MethodGet, generate, []string{"X-API-Key", "guess"}, http.StatusUnauthorized, `error="invalid_token"`},
+		{"insufficient scope", http.MethodGet, generate, []string{"X-API-Key", "stats-secret"}, http.StatusForbidden, `error="insufficient_scope", scope="fizzbuzz:generate"`},
+		{"api key header", http.MethodGet, generate, []string{"X-API-Key", "gen-secret"}, http.StatusOK, ""},
+		{"bearer token", http.MethodGet, generate, []string{"Authorization", "Bearer gen-secret"}, http.StatusOK, ""},
+		{"other schemes are ignored", http.MethodGet, generate, []string{"Authorization", "Basic Z2VuLXNlY3JldA=="}, http.StatusUnauthorized, `Bearer realm="fizzbuzz-service"`},
+		{"admin requires admin", http.MethodPost, "/admin/reload", []string{"X-API-Key", "gen-secret"}, http.StatusForbidden, `scope="admin"`},
+		{"admin", http.MethodPost, "/admin/reload", []string{"X-API-Key", "ops-secret"}, http.StatusOK, ""},
+		{"health stays anonymous", http.MethodGet, "/readyz", nil, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.method, tt.target, tt.headers...)
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			got := w.Header().Get("WWW-Authenticate")
+			if tt.challenge == "" && got != "" || !strings.Contains(got, tt.challenge) {
+				t.Errorf("expected challenge containing %q, got %q", tt.challenge, got)
+			}
+		})
+	}
+
+	t.Run("principals have their own bucket", func(t *testing.T) {
+		send(http.MethodGet, "/statistics", "X-API-Key", "gen-secret")
+		if w := send(http.MethodGet, "/statistics", "X-API-Key", "stats-secret"); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/statistics", "X-API-Key", "stats-secret"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected the principal to be limited, got %d", w.Code)
+		}
+	})
+}
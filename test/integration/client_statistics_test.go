@@ -0,0 +1,110 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/domain/service"
+	"fizzbuzz-service/internal/infrastructure/auth"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+)
+
+func TestClientStatistics(t *testing.T) {
+	logger := newTestLogger()
+	statsRepo := inmemory.NewStatisticsRepository()
+	keys := auth.NewKeyStore([]auth.APIKey{
+		{ID: "alice", Hash: auth.HashKey("alice-secret"), Scopes: []auth.Scope{auth.ScopeGenerate, auth.ScopeStatsRead}},
+		{ID: "bob", Hash: auth.HashKey("bob-secret"), Scopes: []auth.Scope{auth.ScopeGenerate, auth.ScopeStatsRead}},
+		{ID: "ops", Hash: auth.HashKey("ops-secret"), Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})
+	// Hits are recorded synchronously, so they are visible to the next request
+	useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), statsRepo, 10000, logger)
+	router := infrahttp.NewRouter(
+		handler.NewFizzBuzzHandler(useCase, logger),
+		handler.NewStatisticsHandler(application.NewGetStatisticsUseCase(statsRepo), logger),
+		handler.NewHealthHandler(),
+		logger,
+		infrahttp.WithAuthentication(keys),
+		infrahttp.WithAdmin(handler.NewAdminHandler(stubReloader{}, logger)),
+	)
+
+	send := func(target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// alice asks for limit 15 once; bob, the noisy client, for limit 30 three times
+	send("/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz", "alice-secret")
+	for range 3 {
+		send("/fizzbuzz?int1=3&int2=5&limit=30&str1=fizz&str2=buzz", "bob-secret")
+	}
+
+	t.Run("global view counts every client", func(t *testing.T) {
+		var stats entity.StatisticsSummary
+		json.Unmarshal(send("/statistics", "alice-secret").Body.Bytes(), &stats)
+		if stats.HitCount != 3 || stats.MostFrequentQuery.Limit != 30 {
+			t.Errorf("expected limit 30 with 3 hits, got %+v", stats)
+		}
+	})
+
+	t.Run("client=me only counts the caller", func(t *testing.T) {
+		w := send("/statistics?client=me", "alice-secret")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var stats entity.StatisticsSummary
+		json.Unmarshal(w.Body.Bytes(), &stats)
+		if stats.HitCount != 1 || stats.MostFrequentQuery.Limit != 15 {
+			t.Errorf("expected limit 15 with 1 hit, got %+v", stats)
+		}
+
+		var page entity.TopStatistics
+		json.Unmarshal(send("/statistics/top?client=me", "bob-secret").Body.Bytes(), &page)
+		if len(page.Entries) != 1 || page.Entries[0].HitCount != 3 {
+			t.Errorf("expected bob's single request with 3 hits, got %+v", page.Entries)
+		}
+	})
+
+	t.Run("other clients and windows are rejected", func(t *testing.T) {
+		for _, target := range []string{"/statistics?client=api_key:bob", "/statistics?client=me&window=1h", "/statistics/top?client=me&window=1h"} {
+			if w := send(target, "alice-secret"); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", target, w.Code)
+			}
+		}
+	})
+
+	t.Run("admins see every client", func(t *testing.T) {
+		if w := send("/admin/statistics/clients", "alice-secret"); w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 without the admin scope, got %d", w.Code)
+		}
+
+		w := send("/admin/statistics/clients", "ops-secret")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var clients entity.ClientStatistics
+		json.Unmarshal(w.Body.Bytes(), &clients)
+		if len(clients.Entries) != 2 || clients.Entries[0].Client != "api_key:bob" || clients.Entries[0].HitCount != 3 {
+			t.Errorf("expected bob first with 3 hits, got %+v", clients.Entries)
+		}
+
+		var page entity.TopStatistics
+		json.Unmarshal(send("/admin/statistics/clients/api_key%3Aalice", "ops-secret").Body.Bytes(), &page)
+		if len(page.Entries) != 1 || page.Entries[0].Query.Limit != 15 {
+			t.Errorf("expected alice's request, got %+v", page.Entries)
+		}
+
+		if w := send("/admin/statistics/clients/ip%3A192.0.2.1", "ops-secret"); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for a client without hits, got %d", w.Code)
+		}
+	})
+}
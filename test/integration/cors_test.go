@@ -0,0 +1,103 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/service"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	custommw "fizzbuzz-service/internal/infrastructure/http/middleware"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+)
+
+func newTestRouter(t *testing.T, opts ...infrahttp.RouterOption) http.Handler {
+	logger := newTestLogger()
+	statsRepo := inmemory.NewStatisticsRepository()
+	dispatcher := application.NewStatisticsDispatcher(statsRepo, application.DefaultDispatcherConfig(), logger)
+	t.Cleanup(func() { dispatcher.Close(t.Context()) })
+
+	useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), dispatcher, 10000, logger)
+	return infrahttp.NewRouter(
+		handler.NewFizzBuzzHandler(useCase, logger),
+		handler.NewStatisticsHandler(application.NewGetStatisticsUseCase(statsRepo), logger),
+		handler.NewHealthHandler(),
+		logger,
+		opts...,
+	)
+}
+
+func TestCORS(t *testing.T) {
+	preflight := func(router http.Handler, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/fizzbuzz", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("any origin by default", func(t *testing.T) {
+		w := preflight(newTestRouter(t), "https://anywhere.example")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("expected *, got %q", got)
+		}
+		if got := w.Header().Get("Access-Control-Max-Age"); got != "3600" {
+			t.Errorf("expected max age 3600, got %q", got)
+		}
+	})
+
+	t.Run("credential headers are allowed by default", func(t *testing.T) {
+		w := preflight(newTestRouter(t), "https://anywhere.example")
+		allowed := strings.Split(w.Header().Get("Access-Control-Allow-Headers"), ", ")
+		for _, header := range []string{"Authorization", "X-API-Key"} {
+			if !slices.Contains(allowed, header) {
+				t.Errorf("expected %s in %v", header, allowed)
+			}
+		}
+	})
+
+	cors := custommw.NewCORS(custommw.CORSConfig{
+		AllowedOrigins: []string{"https://app.example"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         10 * time.Minute,
+	})
+	router := newTestRouter(t, infrahttp.WithCORS(cors))
+
+	t.Run("allowed origin is echoed", func(t *testing.T) {
+		w := preflight(router, "https://app.example")
+		h := w.Header()
+		if h.Get("Access-Control-Allow-Origin") != "https://app.example" || h.Get("Vary") != "Origin" {
+			t.Errorf("unexpected headers %v", h)
+		}
+		if h.Get("Access-Control-Allow-Methods") != "GET, POST" || h.Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("unexpected headers %v", h)
+		}
+		if h.Get("Access-Control-Allow-Credentials") != "" {
+			t.Errorf("credentials should not be allowed, got %v", h)
+		}
+	})
+
+	t.Run("other origins are not allowed", func(t *testing.T) {
+		w := preflight(router, "https://evil.example")
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no allowed origin, got %q", got)
+		}
+	})
+
+	t.Run("policy can be updated", func(t *testing.T) {
+		cors.Update(custommw.CORSConfig{AllowedOrigins: []string{"https://evil.example"}})
+		w := preflight(router, "https://evil.example")
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://evil.example" {
+			t.Errorf("expected updated origin, got %q", got)
+		}
+	})
+}
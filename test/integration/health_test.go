@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/infrastructure/health"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type readinessBody struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status     string   `json:"status"`
+		Error      string   `json:"error"`
+		DurationMs *float64 `json:"duration_ms"`
+	} `json:"checks"`
+}
+
+func TestHealthHandler_Integration(t *testing.T) {
+	var (
+		dbErr error
+		logs  bytes.Buffer
+	)
+	readiness := health.NewReadiness(0, slog.New(slog.NewTextHandler(&logs, nil)))
+	readiness.Register("statistics", health.CheckerFunc(func(context.Context) error { return dbErr }))
+
+	r := chi.NewRouter()
+	handler.NewHealthHandler(handler.WithReadiness(readiness)).RegisterRoutes(r)
+
+	get := func(path string) (*httptest.ResponseRecorder, readinessBody) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body readinessBody
+		json.NewDecoder(w.Body).Decode(&body)
+		return w, body
+	}
+
+	t.Run("liveness ignores dependencies", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		defer func() { dbErr = nil }()
+
+		w, body := get("/livez")
+		if w.Code != http.StatusOK || body.Status != "alive" {
+			t.Errorf("expected 200 alive, got %d %q", w.Code, body.Status)
+		}
+	})
+
+	t.Run("ready with per-check detail", func(t *testing.T) {
+		w, body := get("/readyz")
+		if w.Code != http.StatusOK || body.Status != "ready" {
+			t.Fatalf("expected 200 ready, got %d %q", w.Code, body.Status)
+		}
+		check := body.Checks["statistics"]
+		if check.Status != "ok" || check.Error != "" || check.DurationMs == nil {
+			t.Errorf("unexpected check %+v", check)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("expected Cache-Control no-store, got %q", cc)
+		}
+	})
+
+	t.Run("not ready when a check fails, without exposing why", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		defer func() { dbErr = nil }()
+
+		w, body := get("/readyz")
+		if w.Code != http.StatusServiceUnavailable || body.Status != "not_ready" {
+			t.Fatalf("expected 503 not_ready, got %d %q", w.Code, body.Status)
+		}
+		if check := body.Checks["statistics"]; check.Status != "failing" || check.Error != "" {
+			t.Errorf("expected a failing check without its error, got %+v", check)
+		}
+		if !strings.Contains(logs.String(), "connection refused") {
+			t.Errorf("expected the error to be logged, got %q", logs.String())
+		}
+	})
+
+	t.Run("legacy health endpoint is unchanged", func(t *testing.T) {
+		w, body := get("/health")
+		if w.Code != http.StatusOK || body.Status != "healthy" {
+			t.Errorf("expected 200 healthy, got %d %q", w.Code, body.Status)
+		}
+	})
+
+	t.Run("draining is not ready", func(t *testing.T) {
+		readiness.Drain()
+
+		w, body := get("/readyz")
+		if w.Code != http.StatusServiceUnavailable || body.Status != "draining" {
+			t.Errorf("expected 503 draining, got %d %q", w.Code, body.Status)
+		}
+		if w, _ := get("/livez"); w.Code != http.StatusOK {
+			t.Errorf("expected liveness to hold while draining, got %d", w.Code)
+		}
+	})
+}
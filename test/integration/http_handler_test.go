@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
@@ -11,8 +12,11 @@ import (
 	"testing"
 
 	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/domain/entity"
 	"fizzbuzz-service/internal/domain/service"
 	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
 	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
 
 	"github.com/go-chi/chi/v5"
@@ -68,6 +72,41 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "rules list returns joined words",
+			method: http.MethodPost,
+			body: map[string]interface{}{
+				"limit": 105,
+				"rules": []map[string]interface{}{
+					{"divisor": 3, "word": "fizz"},
+					{"divisor": 5, "word": "buzz"},
+					{"divisor": 7, "word": "bazz"},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var resp map[string][]string
+				json.Unmarshal(body, &resp)
+
+				result := resp["result"]
+				if len(result) != 105 {
+					t.Fatalf("expected 105 items, got %d", len(result))
+				}
+
+				if result[104] != "fizzbuzzbazz" {
+					t.Errorf("expected 'fizzbuzzbazz' at position 105, got %v", result[104])
+				}
+			},
+		},
+		{
+			name:   "rules mixed with int1 returns 400",
+			method: http.MethodPost,
+			body: map[string]interface{}{
+				"int1": 3, "limit": 15,
+				"rules": []map[string]interface{}{{"divisor": 5, "word": "buzz"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "invalid JSON returns 400",
 			method:         http.MethodPost,
@@ -77,8 +116,8 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 				var resp map[string]interface{}
 				json.Unmarshal(body, &resp)
 
-				if _, ok := resp["error"]; !ok {
-					t.Error("expected 'error' field in response")
+				if resp["type"] != "/problems/malformed-request" || resp["status"] != float64(400) {
+					t.Errorf("expected a malformed request problem, got %v", resp)
 				}
 			},
 		},
@@ -94,13 +133,13 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 				var resp map[string]interface{}
 				json.Unmarshal(body, &resp)
 
-				details, ok := resp["details"].([]interface{})
+				params, ok := resp["invalid_params"].([]interface{})
 				if !ok {
-					t.Fatal("expected 'details' array in response")
+					t.Fatal("expected 'invalid_params' array in response")
 				}
 
-				if len(details) != 5 {
-					t.Errorf("expected 5 validation errors, got %d", len(details))
+				if len(params) != 5 {
+					t.Errorf("expected 5 validation errors, got %d", len(params))
 				}
 			},
 		},
@@ -116,10 +155,10 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 				var resp map[string]interface{}
 				json.Unmarshal(body, &resp)
 
-				details := resp["details"].([]interface{})
+				params := resp["invalid_params"].([]interface{})
 				found := false
-				for _, d := range details {
-					if str, ok := d.(string); ok && strings.Contains(str, "limit") {
+				for _, p := range params {
+					if param, ok := p.(map[string]interface{}); ok && param["name"] == "limit" {
 						found = true
 						break
 					}
@@ -130,10 +169,10 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 			},
 		},
 		{
-			name:           "GET method not allowed",
+			name:           "GET without parameters returns validation error",
 			method:         http.MethodGet,
 			body:           nil,
-			expectedStatus: http.StatusMethodNotAllowed,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:           "PUT method not allowed",
@@ -173,6 +212,601 @@ func TestFizzBuzzHandler_Integration(t *testing.T) {
 	}
 }
 
+func TestFizzBuzzHandler_GenerateFromQuery(t *testing.T) {
+	statsRepo := inmemory.NewStatisticsRepository()
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 10000, logger)
+	fizzHandler := handler.NewFizzBuzzHandler(useCase, logger)
+
+	r := chi.NewRouter()
+	fizzHandler.RegisterRoutes(r)
+
+	get := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// invalidParams returns the name and reason of each invalid parameter
+	invalidParams := func(t *testing.T, w *httptest.ResponseRecorder) (names, reasons []string) {
+		t.Helper()
+		var resp problem.Problem
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		for _, p := range resp.InvalidParams {
+			names = append(names, p.Name)
+			reasons = append(reasons, p.Reason)
+		}
+		return names, reasons
+	}
+
+	t.Run("valid query returns the sequence", func(t *testing.T) {
+		w := get("/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz", nil)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp map[string][]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp["result"]) != 15 || resp["result"][14] != "fizzbuzz" {
+			t.Errorf("unexpected result %v", resp["result"])
+		}
+		if w.Header().Get("ETag") == "" {
+			t.Error("expected an ETag header")
+		}
+		if !strings.Contains(w.Header().Get("Cache-Control"), "max-age") {
+			t.Errorf("expected a cacheable response, got Cache-Control %q", w.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("repeated rule parameters build a rules list", func(t *testing.T) {
+		w := get("/fizzbuzz?limit=21&rule=3:fizz&rule=7:ba:zz", nil)
+
+		var resp map[string][]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp["result"]) != 21 || resp["result"][20] != "fizzba:zz" {
+			t.Errorf("unexpected result %v", resp["result"])
+		}
+	})
+
+	t.Run("validation details match the JSON body", func(t *testing.T) {
+		fromQuery := get("/fizzbuzz?int1=0&int2=-1&limit=20000&str1=&str2=buzz", nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz",
+			strings.NewReader(`{"int1": 0, "int2": -1, "limit": 20000, "str1": "", "str2": "buzz"}`))
+		req.Header.Set("Content-Type", "application/json")
+		fromBody := httptest.NewRecorder()
+		r.ServeHTTP(fromBody, req)
+
+		if fromQuery.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", fromQuery.Code)
+		}
+
+		_, queryDetails := invalidParams(t, fromQuery)
+		_, bodyDetails := invalidParams(t, fromBody)
+		if len(queryDetails) != 4 || len(queryDetails) != len(bodyDetails) {
+			t.Fatalf("expected 4 identical details, got %v and %v", queryDetails, bodyDetails)
+		}
+		for i := range queryDetails {
+			if queryDetails[i] != bodyDetails[i] {
+				t.Errorf("detail %d: %v != %v", i, queryDetails[i], bodyDetails[i])
+			}
+		}
+	})
+
+	t.Run("strict parsing rejects malformed parameters", func(t *testing.T) {
+		w := get("/fizzbuzz?int1=three&int2=5&int2=6&limit=15&str1=fizz&str2=buzz&extra=1", nil)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+
+		names, d := invalidParams(t, w)
+		expected := []string{
+			`unknown parameter "extra"`,
+			"int1 must be an integer",
+			"int2 must be given only once",
+		}
+		if len(d) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, d)
+		}
+		for i := range expected {
+			if d[i] != expected[i] {
+				t.Errorf("detail %d: expected %q, got %v", i, expected[i], d[i])
+			}
+		}
+		if strings.Join(names, ",") != "extra,int1,int2" {
+			t.Errorf("expected parameters extra, int1 and int2, got %v", names)
+		}
+	})
+
+	t.Run("invalid parameters carry a code and the constraint", func(t *testing.T) {
+		w := get("/fizzbuzz?int1=0&int2=5&limit=20000&str1=fizz&str2=buzz&rule=x", nil)
+
+		var resp struct {
+			InvalidParams []struct {
+				Name   string         `json:"name"`
+				Code   string         `json:"code"`
+				Params map[string]any `json:"params"`
+			} `json:"invalid_params"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid problem: %v", err)
+		}
+
+		if len(resp.InvalidParams) != 1 || resp.InvalidParams[0].Name != "rules[0]" ||
+			resp.InvalidParams[0].Code != domain.CodeInvalidFormat {
+			t.Errorf("expected rules[0] to be malformed, got %+v", resp.InvalidParams)
+		}
+
+		w = get("/fizzbuzz?int1=0&int2=5&limit=20000&str1=fizz&str2=buzz", nil)
+		resp.InvalidParams = nil
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.InvalidParams) != 2 {
+			t.Fatalf("expected int1 and limit, got %+v", resp.InvalidParams)
+		}
+		if p := resp.InvalidParams[0]; p.Name != "int1" || p.Code != domain.CodeMustBePositive || p.Params != nil {
+			t.Errorf("expected int1 to be positive, got %+v", p)
+		}
+		if p := resp.InvalidParams[1]; p.Name != "limit" || p.Code != domain.CodeExceedsMax || p.Params["max"] != float64(10000) {
+			t.Errorf("expected limit to exceed a max of 10000, got %+v", p)
+		}
+	})
+
+	t.Run("If-None-Match with current ETag returns 304", func(t *testing.T) {
+		target := "/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz"
+		etag := get(target, nil).Header().Get("ETag")
+
+		w := get(target, map[string]string{"If-None-Match": etag})
+
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("expected 304, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %q", w.Body.String())
+		}
+		if w.Header().Get("ETag") != etag {
+			t.Errorf("expected ETag %s, got %s", etag, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("ETag is deterministic and depends on query and format", func(t *testing.T) {
+		target := "/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz"
+		reordered := "/fizzbuzz?str2=buzz&str1=fizz&limit=15&int2=5&int1=3"
+
+		etag := get(target, nil).Header().Get("ETag")
+
+		if other := get(reordered, nil).Header().Get("ETag"); other != etag {
+			t.Errorf("expected same ETag for reordered parameters, got %s and %s", etag, other)
+		}
+		if other := get("/fizzbuzz?int1=3&int2=5&limit=16&str1=fizz&str2=buzz", nil).Header().Get("ETag"); other == etag {
+			t.Error("expected a different ETag for a different limit")
+		}
+		if other := get(target, map[string]string{"Accept": "text/csv"}).Header().Get("ETag"); other == etag {
+			t.Error("expected a different ETag for a different format")
+		}
+		if w := get(target, map[string]string{"If-None-Match": `"stale"`}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for a stale ETag, got %d", w.Code)
+		}
+	})
+
+	t.Run("separators inside words do not share an ETag", func(t *testing.T) {
+		first := get("/fizzbuzz?int1=3&int2=5&limit=6&str1=fizz%3Ax&str2=buzz", nil)
+		etag := first.Header().Get("ETag")
+
+		w := get("/fizzbuzz?int1=3&int2=5&limit=6&str1=fizz&str2=x%3Abuzz", map[string]string{"If-None-Match": etag})
+		if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+			t.Errorf("expected a fresh 200 with its own ETag, got %d with %s", w.Code, w.Header().Get("ETag"))
+		}
+	})
+}
+
+func TestFizzBuzzHandler_ContentNegotiation(t *testing.T) {
+	statsRepo := inmemory.NewStatisticsRepository()
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 10000, logger)
+	fizzHandler := handler.NewFizzBuzzHandler(useCase, logger)
+
+	r := chi.NewRouter()
+	fizzHandler.RegisterRoutes(r)
+
+	body := `{"int1": 3, "int2": 5, "limit": 5, "str1": "fizz", "str2": "buzz"}`
+
+	tests := []struct {
+		name           string
+		accept         string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "csv",
+			accept:         "text/csv",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedBody:   "index,value\r\n1,1\r\n2,2\r\n3,fizz\r\n4,4\r\n5,buzz\r\n",
+		},
+		{
+			name:           "plain text",
+			accept:         "text/plain",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   "1\n2\nfizz\n4\nbuzz\n",
+		},
+		{
+			name:           "ndjson",
+			accept:         "application/x-ndjson",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/x-ndjson",
+			expectedBody:   "\"1\"\n\"2\"\n\"fizz\"\n\"4\"\n\"buzz\"\n",
+		},
+		{
+			name:           "unsupported type returns 406",
+			accept:         "application/xml",
+			expectedStatus: http.StatusNotAcceptable,
+			expectedType:   "application/problem+json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/fizzbuzz", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.expectedType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedType, ct)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFizzBuzzHandler_Stream(t *testing.T) {
+	statsRepo := inmemory.NewStatisticsRepository()
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 100, logger,
+		application.WithStreamMaxLimit(100000),
+	)
+	fizzHandler := handler.NewFizzBuzzHandler(useCase, logger)
+
+	r := chi.NewRouter()
+	fizzHandler.RegisterStreamRoutes(r)
+
+	body := `{"int1": 3, "int2": 5, "limit": 100000, "str1": "fi\"zz", "str2": "buzz"}`
+
+	t.Run("streams a JSON document beyond the regular limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz/stream", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json, got %q", ct)
+		}
+
+		var resp map[string][]string
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(resp["result"]) != 100000 {
+			t.Fatalf("expected 100000 items, got %d", len(resp["result"]))
+		}
+		if resp["result"][14] != `fi"zzbuzz` {
+			t.Errorf("expected escaped word to round-trip, got %q", resp["result"][14])
+		}
+	})
+
+	t.Run("streams NDJSON when requested", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz/stream", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/x-ndjson")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("expected application/x-ndjson, got %q", ct)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		if len(lines) != 100000 {
+			t.Fatalf("expected 100000 lines, got %d", len(lines))
+		}
+		if lines[2] != `"fi\"zz"` {
+			t.Errorf("unexpected third line %q", lines[2])
+		}
+	})
+
+	t.Run("rejects limits above the stream maximum", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz/stream",
+			strings.NewReader(`{"int1": 3, "int2": 5, "limit": 100001, "str1": "fizz", "str2": "buzz"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestFizzBuzzHandler_Pages(t *testing.T) {
+	statsRepo := inmemory.NewStatisticsRepository()
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 100, logger)
+	fizzHandler := handler.NewFizzBuzzHandler(useCase, logger)
+
+	r := chi.NewRouter()
+	fizzHandler.RegisterRoutes(r)
+	fizzHandler.RegisterStreamRoutes(r)
+
+	send := func(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	const query = "/fizzbuzz?int1=3&int2=5&limit=100&str1=fizz&str2=buzz"
+
+	t.Run("POST returns the page with links to its neighbours", func(t *testing.T) {
+		w := send(http.MethodPost, "/fizzbuzz",
+			`{"int1":3,"int2":5,"limit":100,"str1":"fizz","str2":"buzz","offset":20,"count":10}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+
+		var resp map[string][]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if got := strings.Join(resp["result"], ","); got != "fizz,22,23,fizz,buzz,26,fizz,28,29,fizzbuzz" {
+			t.Errorf("unexpected page %s", got)
+		}
+
+		link := w.Header().Get("Link")
+		for _, expected := range []string{
+			`</fizzbuzz?count=10&int1=3&int2=5&limit=100&offset=0&str1=fizz&str2=buzz>; rel="first"`,
+			`</fizzbuzz?count=10&int1=3&int2=5&limit=100&offset=10&str1=fizz&str2=buzz>; rel="prev"`,
+			`</fizzbuzz?count=10&int1=3&int2=5&limit=100&offset=30&str1=fizz&str2=buzz>; rel="next"`,
+			`</fizzbuzz?count=10&int1=3&int2=5&limit=100&offset=90&str1=fizz&str2=buzz>; rel="last"`,
+		} {
+			if !strings.Contains(link, expected) {
+				t.Errorf("expected %s in Link %s", expected, link)
+			}
+		}
+	})
+
+	t.Run("GET follows the links down to the truncated last page", func(t *testing.T) {
+		w := send(http.MethodGet, query+"&offset=95&count=10", "", "Accept", "text/csv")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+		// CSV rows keep the numbers of the whole sequence
+		if got := w.Body.String(); got != "index,value\r\n96,fizz\r\n97,97\r\n98,98\r\n99,fizz\r\n100,buzz\r\n" {
+			t.Errorf("unexpected body %q", got)
+		}
+		if link := w.Header().Get("Link"); strings.Contains(link, `rel="next"`) {
+			t.Errorf("expected no next page, got %s", link)
+		}
+	})
+
+	t.Run("pages of a query have their own ETag", func(t *testing.T) {
+		whole := send(http.MethodGet, query, "")
+		first := send(http.MethodGet, query+"&offset=0&count=10", "")
+		second := send(http.MethodGet, query+"&offset=10&count=10", "")
+
+		etags := map[string]bool{
+			whole.Header().Get("ETag"):  true,
+			first.Header().Get("ETag"):  true,
+			second.Header().Get("ETag"): true,
+		}
+		if len(etags) != 3 {
+			t.Errorf("expected 3 distinct ETags, got %v", etags)
+		}
+		if whole.Header().Get("Link") != "" {
+			t.Error("expected no Link header without a page")
+		}
+	})
+
+	t.Run("offset alone returns the rest of the sequence", func(t *testing.T) {
+		w := send(http.MethodGet, query+"&offset=90", "")
+
+		var resp map[string][]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp["result"]) != 10 || resp["result"][9] != "buzz" {
+			t.Errorf("expected the 10 last values, got %v", resp["result"])
+		}
+	})
+
+	t.Run("statistics count the underlying query", func(t *testing.T) {
+		summary, err := statsRepo.GetMostFrequent(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// One POST and 5 GET, 4 of them pages
+		if summary.HitCount != 6 || summary.MostFrequentQuery.Limit != 100 {
+			t.Errorf("expected 6 hits of the limit 100 query, got %+v", summary)
+		}
+	})
+
+	t.Run("rejects pages outside the limit", func(t *testing.T) {
+		w := send(http.MethodGet, query+"&offset=100&count=0", "")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+
+		var p problem.Problem
+		json.Unmarshal(w.Body.Bytes(), &p)
+		if len(p.InvalidParams) != 2 ||
+			p.InvalidParams[0].Name != "offset" || p.InvalidParams[0].Code != domain.CodeOutOfRange ||
+			p.InvalidParams[1].Name != "count" || p.InvalidParams[1].Code != domain.CodeOutOfRange {
+			t.Errorf("expected offset and count out of range, got %+v", p.InvalidParams)
+		}
+	})
+
+	t.Run("streams do not support pages", func(t *testing.T) {
+		w := send(http.MethodPost, "/fizzbuzz/stream",
+			`{"int1":3,"int2":5,"limit":100,"str1":"fizz","str2":"buzz","offset":10}`)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+
+		var p problem.Problem
+		json.Unmarshal(w.Body.Bytes(), &p)
+		if len(p.InvalidParams) != 1 || p.InvalidParams[0].Code != domain.CodeUnsupported {
+			t.Errorf("expected offset unsupported, got %+v", p.InvalidParams)
+		}
+	})
+}
+
+func TestFizzBuzzHandler_StrictBody(t *testing.T) {
+	statsRepo := inmemory.NewStatisticsRepository()
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, statsRepo, 10000, logger)
+	fizzHandler := handler.NewFizzBuzzHandler(useCase, logger, handler.WithMaxBodyBytes(256))
+
+	r := chi.NewRouter()
+	fizzHandler.RegisterRoutes(r)
+	fizzHandler.RegisterStreamRoutes(r)
+
+	post := func(target, contentType, body string) (*httptest.ResponseRecorder, problem.Problem) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var p problem.Problem
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w, p
+	}
+
+	t.Run("accepts a charset parameter", func(t *testing.T) {
+		w, _ := post("/fizzbuzz", "application/json; charset=utf-8",
+			`{"int1": 3, "int2": 5, "limit": 15, "str1": "fizz", "str2": "buzz"}`+"\n")
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("other content types are unsupported", func(t *testing.T) {
+		for _, target := range []string{"/fizzbuzz", "/fizzbuzz/stream"} {
+			for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+				w, p := post(target, contentType, `{"int1": 3}`)
+				if w.Code != http.StatusUnsupportedMediaType || p.Type != problem.UnsupportedMediaType.URI {
+					t.Errorf("%s with %q: expected 415, got %d %s", target, contentType, w.Code, p.Type)
+				}
+			}
+		}
+	})
+
+	t.Run("bodies over the maximum are too large", func(t *testing.T) {
+		body := `{"int1": 3, "int2": 5, "limit": 15, "str1": "` + strings.Repeat("a", 256) + `", "str2": "buzz"}`
+		w, p := post("/fizzbuzz", "application/json", body)
+		if w.Code != http.StatusRequestEntityTooLarge || p.Detail != "request body exceeds 256 bytes" {
+			t.Errorf("expected 413, got %d %+v", w.Code, p)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		body   string
+		param  string
+		code   string
+		detail string
+	}{
+		{
+			name:   "unknown field",
+			body:   `{"int1": 3, "int2": 5, "limit": 15, "str1": "fizz", "str2": "buzz", "extra": 1}`,
+			param:  "extra",
+			code:   domain.CodeUnknown,
+			detail: `unknown field "extra" at line 1, column 69`,
+		},
+		{
+			name:   "wrong type",
+			body:   "{\n  \"int1\": \"3\"\n}",
+			param:  "int1",
+			code:   "invalid_type",
+			detail: "int1 must be a number, got string at line 2, column 11",
+		},
+		{
+			name:   "wrong type in a rule",
+			body:   `{"limit": 15, "rules": [{"divisor": 3, "word": "fizz"}, {"divisor": true, "word": "buzz"}]}`,
+			param:  "rules[1].divisor",
+			code:   "invalid_type",
+			detail: "rules[1].divisor must be a number, got bool at line 1, column 69",
+		},
+		{
+			name:   "unknown field in a rule",
+			body:   `{"limit": 15, "rules": [{"divisor": 3, "word": "fizz", "weight": 2}]}`,
+			param:  "rules[0].weight",
+			code:   domain.CodeUnknown,
+			detail: `unknown field "weight" at line 1, column 56`,
+		},
+		{
+			name:   "trailing data",
+			body:   `{"int1": 3, "int2": 5, "limit": 15, "str1": "fizz", "str2": "buzz"} {}`,
+			code:   "trailing_data",
+			detail: "unexpected data after the JSON value at line 1, column 69",
+		},
+		{
+			name:   "syntax error",
+			body:   `{"int1" 3}`,
+			code:   "syntax",
+			detail: "invalid character '3' after object key at line 1, column 9",
+		},
+		{
+			name:   "truncated",
+			body:   `{"int1": 3,`,
+			code:   "syntax",
+			detail: "unexpected end of JSON at line 1, column 12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, p := post("/fizzbuzz", "application/json", tt.body)
+
+			if w.Code != http.StatusBadRequest || p.Type != problem.MalformedRequest.URI {
+				t.Fatalf("expected a malformed request, got %d: %s", w.Code, w.Body.String())
+			}
+			if p.Detail != tt.detail {
+				t.Errorf("expected detail %q, got %q", tt.detail, p.Detail)
+			}
+			if len(p.InvalidParams) != 1 || p.InvalidParams[0].Name != tt.param || p.InvalidParams[0].Code != tt.code {
+				t.Errorf("expected %q with code %s, got %+v", tt.param, tt.code, p.InvalidParams)
+			}
+		})
+	}
+}
+
 func TestStatisticsHandler_Integration(t *testing.T) {
 	statsRepo := inmemory.NewStatisticsRepository()
 	logger := newTestLogger()
@@ -206,6 +840,95 @@ func TestStatisticsHandler_Integration(t *testing.T) {
 		}
 	})
 
+	t.Run("top returns ranked pages", func(t *testing.T) {
+		ctx := context.Background()
+		for i := 1; i <= 3; i++ {
+			for j := 0; j < i; j++ {
+				statsRepo.UpdateStats(ctx, entity.FizzBuzzQuery{
+					FirstDivisor: 3, SecondDivisor: 5, UpperLimit: i,
+					FirstString: "fizz", SecondString: "buzz",
+				}, entity.Caller{})
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/statistics/top?n=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var page struct {
+			Entries []struct {
+				Rank      int                    `json:"rank"`
+				Request   map[string]interface{} `json:"request"`
+				Hits      int64                  `json:"hits"`
+				LastHitAt string                 `json:"last_hit_at"`
+			} `json:"entries"`
+			NextCursor string `json:"next_cursor"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &page)
+
+		if len(page.Entries) != 2 || page.Entries[0].Hits != 3 || page.Entries[1].Hits != 2 {
+			t.Fatalf("unexpected first page: %s", w.Body.String())
+		}
+		if page.Entries[0].LastHitAt == "" {
+			t.Error("expected last_hit_at")
+		}
+		if page.NextCursor == "" {
+			t.Fatal("expected next_cursor")
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/statistics/top?n=2&cursor="+page.NextCursor, nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		page.NextCursor = ""
+		page.Entries = nil
+		json.Unmarshal(w.Body.Bytes(), &page)
+
+		if len(page.Entries) != 1 || page.Entries[0].Rank != 3 || page.Entries[0].Hits != 1 {
+			t.Errorf("unexpected second page: %s", w.Body.String())
+		}
+		if page.NextCursor != "" {
+			t.Errorf("expected no next_cursor on the last page")
+		}
+	})
+
+	t.Run("window parameter counts recent hits", func(t *testing.T) {
+		for _, target := range []string{"/statistics?window=5m", "/statistics/top?window=1h&n=1"} {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("%s: expected 200, got %d: %s", target, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"hits":3`) {
+				t.Errorf("%s: expected the 3-hit request, got %s", target, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("top rejects invalid parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/statistics/top?n=abc",
+			"/statistics/top?n=1000",
+			"/statistics/top?cursor=%21",
+			"/statistics/top?window=forever",
+			"/statistics?window=48h",
+		} {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", target, w.Code)
+			}
+		}
+	})
+
 	t.Run("POST method not allowed", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/statistics", nil)
 		w := httptest.NewRecorder()
@@ -0,0 +1,78 @@
+package integration_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTAuthentication(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	keys, err := auth.ParseJWKS([]byte(`{"keys": [{"kty": "oct", "kid": "gw-1", "k": "` + base64.RawURLEncoding.EncodeToString(secret) + `"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtAuthn := auth.NewJWTAuthenticator(keys, auth.JWTConfig{Issuer: "https://gateway", Audience: "fizzbuzz"})
+	apiKeys := auth.NewKeyStore([]auth.APIKey{
+		{ID: "ci", Hash: auth.HashKey("ci-secret"), Scopes: []auth.Scope{auth.ScopeGenerate}},
+	})
+	router := newTestRouter(t, infrahttp.WithAuthentication(auth.Dispatcher{APIKeys: apiKeys, JWT: jwtAuthn}))
+
+	token := func(scope string, exp time.Time) string {
+		t.Helper()
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   "gateway-client",
+			"iss":   "https://gateway",
+			"aud":   "fizzbuzz",
+			"exp":   exp.Unix(),
+			"scope": scope,
+		})
+		tok.Header["kid"] = "gw-1"
+		signed, err := tok.SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	const generate = "/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz"
+	valid := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		target    string
+		token     string
+		status    int
+		challenge string
+	}{
+		{"valid token", generate, token("fizzbuzz:generate", valid), http.StatusOK, ""},
+		{"api keys still accepted", generate, "ci-secret", http.StatusOK, ""},
+		{"insufficient scope", "/statistics", token("fizzbuzz:generate", valid), http.StatusForbidden,
+			`Bearer realm="fizzbuzz-service", error="insufficient_scope", scope="stats:read"`},
+		{"expired token", generate, token("fizzbuzz:generate", time.Now().Add(-time.Hour)), http.StatusUnauthorized,
+			`Bearer realm="fizzbuzz-service", error="invalid_token", error_description="token is expired"`},
+		{"bad signature", generate, token("fizzbuzz:generate", valid) + "x", http.StatusUnauthorized,
+			`Bearer realm="fizzbuzz-service", error="invalid_token", error_description="invalid signature"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("expected challenge %q, got %q", tt.challenge, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package integration_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/service"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/metrics"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	logger := newTestLogger()
+	statsRepo := inmemory.NewStatisticsRepository()
+	dispatcher := application.NewStatisticsDispatcher(statsRepo, application.DefaultDispatcherConfig(), logger)
+	defer dispatcher.Close(t.Context())
+
+	registry := metrics.NewRegistry()
+	registry.RegisterRuntimeMetrics()
+	registry.RegisterStatisticsDispatcher(dispatcher)
+	httpMetrics := metrics.NewHTTPMetrics(registry)
+
+	useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), dispatcher, 10000, logger)
+	router := infrahttp.NewRouter(
+		handler.NewFizzBuzzHandler(useCase, logger, handler.WithSequenceObserver(httpMetrics)),
+		handler.NewStatisticsHandler(application.NewGetStatisticsUseCase(statsRepo), logger),
+		handler.NewHealthHandler(),
+		logger,
+		infrahttp.WithMetrics(httpMetrics),
+		infrahttp.WithHandler("/metrics", registry.Handler()),
+		infrahttp.WithHandler("/panic", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})),
+	)
+
+	send := func(method, target, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send(http.MethodPost, "/fizzbuzz", `{"int1":3,"int2":5,"limit":15,"str1":"fizz","str2":"buzz"}`)
+	send(http.MethodGet, "/fizzbuzz?int1=3&int2=5&limit=150&str1=fizz&str2=buzz", "")
+	send(http.MethodPost, "/fizzbuzz", `{"int1":0}`)
+	send(http.MethodGet, "/no/such/route", "")
+	send(http.MethodGet, "/panic", "")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("expected Content-Type %q, got %q", metrics.ContentType, ct)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	exposition := string(body)
+
+	for _, expected := range []string{
+		`http_requests_total{method="POST",route="/fizzbuzz",status="200"} 1`,
+		`http_requests_total{method="POST",route="/fizzbuzz",status="400"} 1`,
+		`http_requests_total{method="GET",route="/fizzbuzz",status="200"} 1`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/fizzbuzz",status="200"} 1`,
+		`http_requests_total{method="GET",route="/panic",status="500"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/panic",status="500"} 1`,
+		`http_panics_recovered_total 1`,
+		`fizzbuzz_sequence_length_bucket{le="100"} 1`,
+		`fizzbuzz_sequence_length_count 2`,
+		`fizzbuzz_sequence_length_sum 165`,
+		`stats_queue_capacity 1024`,
+		`stats_hits_dropped_total 0`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(exposition, expected) {
+			t.Errorf("missing %q in:\n%s", expected, exposition)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+)
+
+func TestProblemResponses(t *testing.T) {
+	router := newTestRouter(t,
+		infrahttp.WithHandler("/panic", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		})),
+		infrahttp.WithHandler("/panic/started", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Write([]byte("\"1\"\n"))
+			panic("boom")
+		})),
+		infrahttp.WithHandler("/panic/abort", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		})),
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		typ    string
+	}{
+		{"unknown route", http.MethodGet, "/nowhere", http.StatusNotFound, "/problems/not-found"},
+		{"wrong method", http.MethodDelete, "/fizzbuzz", http.StatusMethodNotAllowed, "/problems/method-not-allowed"},
+		{"invalid parameters", http.MethodGet, "/statistics/top?n=0", http.StatusBadRequest, "/problems/invalid-parameters"},
+		{"panic", http.MethodGet, "/panic", http.StatusInternalServerError, "/problems/internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Errorf("expected %s, got %q", problem.ContentType, ct)
+			}
+
+			var p problem.Problem
+			json.NewDecoder(w.Body).Decode(&p)
+			if p.Type != tt.typ || p.Status != tt.status || p.Title == "" {
+				t.Errorf("unexpected problem %+v", p)
+			}
+			if p.Instance != strings.Split(tt.target, "?")[0] || p.RequestID == "" {
+				t.Errorf("expected the request path and ID, got %q and %q", p.Instance, p.RequestID)
+			}
+		})
+	}
+
+	t.Run("panics after the response started leave it unchanged", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic/started", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "\"1\"\n" {
+			t.Errorf("expected the partial stream alone, got %d %q", w.Code, w.Body)
+		}
+	})
+
+	t.Run("aborts are left to net/http", func(t *testing.T) {
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Errorf("expected http.ErrAbortHandler to propagate, got %v", rec)
+			}
+		}()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic/abort", nil))
+	})
+
+	t.Run("405 lists the allowed methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/fizzbuzz", nil))
+		if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+			t.Errorf("expected Allow: GET, POST, got %q", allow)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	custommw "fizzbuzz-service/internal/infrastructure/http/middleware"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiters := infrahttp.RateLimiters{
+		Generate:   custommw.NewRateLimiter(custommw.RateLimit{Rate: 0.001, Burst: 10, CostUnit: 100}),
+		Statistics: custommw.NewRateLimiter(custommw.RateLimit{Rate: 0.001, Burst: 1}),
+	}
+	router := newTestRouter(t, infrahttp.WithRateLimits(limiters))
+
+	send := func(method, target, body, client string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-Forwarded-For", client)
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("cost grows with the limit", func(t *testing.T) {
+		// 1 + 750/100 = 8 tokens, the body is still decoded by the handler
+		w := send(http.MethodPost, "/fizzbuzz", `{"int1":3,"int2":5,"limit":750,"str1":"fizz","str2":"buzz"}`, "192.0.2.1")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "fizzbuzz") {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "2" {
+			t.Errorf("expected 2 tokens remaining, got %q", got)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "10" {
+			t.Errorf("expected limit 10, got %q", got)
+		}
+
+		// 1 + 250/100 = 3 tokens
+		w = send(http.MethodGet, "/fizzbuzz?int1=3&int2=5&limit=250&str1=fizz&str2=buzz", "", "192.0.2.1")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Reset") == "" {
+			t.Errorf("missing retry headers: %v", w.Header())
+		}
+		if !strings.Contains(w.Body.String(), "/problems/rate-limited") {
+			t.Errorf("unexpected body %s", w.Body)
+		}
+
+		// A cheaper request still fits
+		w = send(http.MethodGet, "/fizzbuzz?int1=3&int2=5&limit=15&str1=fizz&str2=buzz", "", "192.0.2.1")
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("pages cost the numbers they hold", func(t *testing.T) {
+		// 50 numbers are left after offset 700: 1 + 50/100 = 1 token
+		w := send(http.MethodPost, "/fizzbuzz", `{"int1":3,"int2":5,"limit":750,"str1":"fizz","str2":"buzz","offset":700,"count":100}`, "192.0.2.4")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "9" {
+			t.Errorf("expected 9 tokens remaining, got %q", got)
+		}
+
+		// 1 + 300/100 = 4 tokens
+		w = send(http.MethodGet, "/fizzbuzz?int1=3&int2=5&limit=750&str1=fizz&str2=buzz&offset=0&count=300", "", "192.0.2.4")
+		if got := w.Header().Get("RateLimit-Remaining"); w.Code != http.StatusOK || got != "5" {
+			t.Errorf("expected 200 with 5 tokens remaining, got %d and %q", w.Code, got)
+		}
+	})
+
+	t.Run("keyed by client IP", func(t *testing.T) {
+		if w := send(http.MethodGet, "/statistics", "", "192.0.2.2"); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/statistics", "", "192.0.2.2"); w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/statistics", "", "192.0.2.3"); w.Code != http.StatusOK {
+			t.Errorf("another IP should not be limited, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/statistics", "", "192.0.2.2", "X-API-Key", "made-up"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("unverified keys must not get their own bucket, got %d", w.Code)
+		}
+	})
+
+	t.Run("unlimited routes", func(t *testing.T) {
+		for range 5 {
+			if w := send(http.MethodGet, "/health", "", "192.0.2.2"); w.Code != http.StatusOK {
+				t.Fatalf("health must not be limited, got %d", w.Code)
+			}
+			if w := send(http.MethodPost, "/fizzbuzz/stream", `{"int1":3,"int2":5,"limit":15,"str1":"a","str2":"b"}`, "192.0.2.2"); w.Code != http.StatusOK {
+				t.Fatalf("stream has no limiter here, got %d", w.Code)
+			}
+		}
+	})
+}
+
+func TestRateLimit_PostCostFromBody(t *testing.T) {
+	limit := custommw.RateLimit{Rate: 0.001, Burst: 10, CostUnit: 100}
+	limiters := infrahttp.RateLimiters{
+		Generate: custommw.NewRateLimiter(limit),
+		Stream:   custommw.NewRateLimiter(limit),
+	}
+	router := newTestRouter(t, infrahttp.WithRateLimits(limiters))
+
+	post := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The handlers only read the body: a smaller limit in the query string
+	// must not lower the cost of 1 + 750/100 = 8 tokens
+	for _, target := range []string{"/fizzbuzz?limit=1", "/fizzbuzz/stream?limit=1&offset=0&count=1"} {
+		t.Run(target, func(t *testing.T) {
+			w := post(target, `{"int1":3,"int2":5,"limit":750,"str1":"fizz","str2":"buzz"}`)
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "fizzbuzz") {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("RateLimit-Remaining"); got != "2" {
+				t.Errorf("expected 2 tokens remaining, got %q", got)
+			}
+		})
+	}
+}
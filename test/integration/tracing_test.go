@@ -0,0 +1,154 @@
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/service"
+	infrahttp "fizzbuzz-service/internal/infrastructure/http"
+	"fizzbuzz-service/internal/infrastructure/http/handler"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// newTracedRouter wires the service as main does, recording every span
+func newTracedRouter(t *testing.T) (http.Handler, *application.StatisticsDispatcher, *tracetest.SpanRecorder) {
+	t.Helper()
+	logger := newTestLogger()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+
+	statsRepo := tracing.TraceStatistics(inmemory.NewStatisticsRepository(), tp)
+	dispatcher := application.NewStatisticsDispatcher(statsRepo, application.DefaultDispatcherConfig(), logger,
+		application.WithDispatcherTracerProvider(tp),
+	)
+	t.Cleanup(func() { dispatcher.Close(context.Background()) })
+
+	useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), dispatcher, 10000, logger,
+		application.WithTracerProvider(tp),
+	)
+	router := infrahttp.NewRouter(
+		handler.NewFizzBuzzHandler(useCase, logger, handler.WithTracerProvider(tp)),
+		handler.NewStatisticsHandler(application.NewGetStatisticsUseCase(statsRepo), logger),
+		handler.NewHealthHandler(),
+		logger,
+		infrahttp.WithTracing(tp),
+	)
+	return router, dispatcher, recorder
+}
+
+func TestTracing_Integration(t *testing.T) {
+	body := `{"int1":3,"int2":5,"limit":15,"str1":"fizz","str2":"buzz"}`
+
+	t.Run("request continues the incoming trace", func(t *testing.T) {
+		router, dispatcher, recorder := newTracedRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("traceparent", "00-"+remoteTraceID+"-"+remoteSpanID+"-01")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		dispatcher.Close(context.Background())
+
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+
+		server, ok := spans["POST /fizzbuzz"]
+		if !ok {
+			t.Fatalf("expected a server span named after the route, got %v", spanNames(recorder))
+		}
+		if server.SpanKind() != trace.SpanKindServer {
+			t.Errorf("expected a server span, got %v", server.SpanKind())
+		}
+		if !server.Parent().IsRemote() || server.Parent().SpanID().String() != remoteSpanID {
+			t.Errorf("expected the remote parent %s, got %v", remoteSpanID, server.Parent())
+		}
+
+		// Each span is the child of the previous one, all in the incoming trace
+		chain := []string{"POST /fizzbuzz", "FizzBuzzHandler.Generate", "GenerateFizzBuzzUseCase.Generate", "FizzBuzzGenerator.Generate"}
+		for i, name := range chain {
+			span, ok := spans[name]
+			if !ok {
+				t.Fatalf("missing span %q in %v", name, spanNames(recorder))
+			}
+			if span.SpanContext().TraceID().String() != remoteTraceID {
+				t.Errorf("%s: expected trace %s, got %s", name, remoteTraceID, span.SpanContext().TraceID())
+			}
+			if i > 0 && span.Parent().SpanID() != spans[chain[i-1]].SpanContext().SpanID() {
+				t.Errorf("%s: expected parent %s", name, chain[i-1])
+			}
+		}
+
+		// The background update has its own trace, linked to the request
+		record := spans["StatisticsDispatcher.record"]
+		if record == nil {
+			t.Fatalf("missing background span in %v", spanNames(recorder))
+		}
+		if record.SpanContext().TraceID().String() == remoteTraceID {
+			t.Error("expected the background span in a new trace")
+		}
+		links := record.Links()
+		if len(links) != 1 || links[0].SpanContext.SpanID() != spans["GenerateFizzBuzzUseCase.Generate"].SpanContext().SpanID() {
+			t.Errorf("expected a link to the use case span, got %v", links)
+		}
+		if update := spans["StatisticsUpdater.UpdateStats"]; update == nil || update.Parent().SpanID() != record.SpanContext().SpanID() {
+			t.Error("expected the statistics port span under the background span")
+		}
+	})
+
+	t.Run("unsampled incoming traces are not recorded", func(t *testing.T) {
+		router, dispatcher, recorder := newTracedRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/fizzbuzz", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("traceparent", "00-"+remoteTraceID+"-"+remoteSpanID+"-00")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		dispatcher.Close(context.Background())
+
+		for _, span := range recorder.Ended() {
+			if span.SpanContext().TraceID().String() == remoteTraceID {
+				t.Errorf("span %q of an unsampled trace was recorded", span.Name())
+			}
+		}
+	})
+
+	t.Run("unmatched routes get a bounded span name", func(t *testing.T) {
+		router, _, recorder := newTracedRouter(t)
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/route", nil))
+
+		spans := recorder.Ended()
+		if len(spans) != 1 || spans[0].Name() != "GET unmatched" {
+			t.Errorf("expected one span for the unmatched route, got %v", spanNames(recorder))
+		}
+	})
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
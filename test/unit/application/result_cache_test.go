@@ -0,0 +1,196 @@
+package application_test
+
+import (
+	"context"
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/domain/service"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sequenceOf returns a generator of one value of n bytes, counting its calls
+func sequenceOf(n int, calls *atomic.Int64) func() []string {
+	return func() []string {
+		calls.Add(1)
+		return []string{strings.Repeat("x", n)}
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	t.Run("serves repeated keys from cache", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 10)
+		var calls atomic.Int64
+
+		first, hit := cache.Get("a", sequenceOf(10, &calls))
+		if hit {
+			t.Error("expected the first get to miss")
+		}
+		second, hit := cache.Get("a", sequenceOf(10, &calls))
+		if !hit || &second[0] != &first[0] {
+			t.Error("expected the second get to return the cached sequence")
+		}
+
+		stats := cache.Stats()
+		if calls.Load() != 1 || stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+			t.Errorf("expected 1 generation, hit and miss, got %d calls and %+v", calls.Load(), stats)
+		}
+	})
+
+	t.Run("evicts the least recently used beyond the byte budget", func(t *testing.T) {
+		// Each entry holds 1 key byte, 24 of slice and 16+100 of string
+		cache := application.NewResultCache(3 * 141)
+		var calls atomic.Int64
+
+		for _, key := range []string{"a", "b", "c"} {
+			cache.Get(key, sequenceOf(100, &calls))
+		}
+		cache.Get("a", sequenceOf(100, &calls)) // b becomes the least recently used
+		cache.Get("d", sequenceOf(100, &calls))
+
+		stats := cache.Stats()
+		if stats.Entries != 3 || stats.Bytes != 3*141 || stats.Evictions != 1 {
+			t.Errorf("expected 3 entries after 1 eviction, got %+v", stats)
+		}
+		if _, hit := cache.Get("a", sequenceOf(100, &calls)); !hit {
+			t.Error("expected a to be kept")
+		}
+		if _, hit := cache.Get("b", sequenceOf(100, &calls)); hit {
+			t.Error("expected b to be evicted")
+		}
+	})
+
+	t.Run("does not cache sequences larger than the budget", func(t *testing.T) {
+		cache := application.NewResultCache(100)
+		var calls atomic.Int64
+
+		cache.Get("a", sequenceOf(1000, &calls))
+		if _, hit := cache.Get("a", sequenceOf(1000, &calls)); hit {
+			t.Error("expected an oversized sequence to be regenerated")
+		}
+		if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+			t.Errorf("expected an empty cache, got %+v", stats)
+		}
+	})
+
+	t.Run("concurrent misses share one generation", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 10)
+		var calls atomic.Int64
+		release := make(chan struct{})
+		generate := func() []string {
+			calls.Add(1)
+			<-release
+			return []string{"1"}
+		}
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() { cache.Get("a", generate) })
+		}
+		// Let every goroutine join the pending generation
+		for cache.Stats().Misses < 10 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("expected a single generation, got %d", calls.Load())
+		}
+		if stats := cache.Stats(); stats.Shared == 0 || stats.Shared > 9 {
+			t.Errorf("expected up to 9 shared misses, got %+v", stats)
+		}
+	})
+
+	t.Run("warming counts neither hits nor misses", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 10)
+		var calls atomic.Int64
+
+		cache.Warm("a", sequenceOf(10, &calls))
+		cache.Warm("a", sequenceOf(10, &calls))
+
+		stats := cache.Stats()
+		if calls.Load() != 1 || stats.Entries != 1 || stats.Hits != 0 || stats.Misses != 0 {
+			t.Errorf("expected one silent generation, got %d calls and %+v", calls.Load(), stats)
+		}
+	})
+}
+
+func TestGenerateFizzBuzzUseCase_ResultCache(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	query := entity.FizzBuzzQuery{FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15, FirstString: "fizz", SecondString: "buzz"}
+
+	t.Run("repeated queries are cached and still counted", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 20)
+		updater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, updater, 100, newTestLogger(),
+			application.WithResultCache(cache),
+		)
+
+		for range 3 {
+			result, err := useCase.Generate(context.Background(), query)
+			if err != nil || len(result) != 15 || result[14] != "fizzbuzz" {
+				t.Fatalf("unexpected result %v, %v", result, err)
+			}
+		}
+
+		if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+			t.Errorf("expected 2 hits and 1 miss, got %+v", stats)
+		}
+		if len(updater.getCalls()) != 3 {
+			t.Errorf("expected every request counted in statistics, got %d", len(updater.getCalls()))
+		}
+	})
+
+	t.Run("separators inside words do not share an entry", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 20)
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, &mockStatsUpdater{}, 100, newTestLogger(),
+			application.WithResultCache(cache),
+		)
+		first := entity.FizzBuzzQuery{FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 6, FirstString: "fizz:x", SecondString: "buzz"}
+		second := entity.FizzBuzzQuery{FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 6, FirstString: "fizz", SecondString: "x:buzz"}
+
+		useCase.Generate(context.Background(), first)
+		result, err := useCase.Generate(context.Background(), second)
+		if err != nil || strings.Join(result, ",") != "1,2,fizz,4,x:buzz,fizz" {
+			t.Errorf("expected the sequence of the second query, got %v, %v", result, err)
+		}
+		if stats := cache.Stats(); stats.Misses != 2 || stats.Entries != 2 {
+			t.Errorf("expected 2 distinct entries, got %+v", stats)
+		}
+	})
+
+	t.Run("prewarm caches valid queries without counting hits", func(t *testing.T) {
+		cache := application.NewResultCache(1 << 20)
+		updater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, updater, 100, newTestLogger(),
+			application.WithResultCache(cache),
+		)
+		tooLong := query
+		tooLong.UpperLimit = 1000
+
+		warmed := useCase.Prewarm(context.Background(), []entity.FizzBuzzQuery{query, tooLong})
+		if warmed != 1 {
+			t.Errorf("expected 1 query warmed, got %d", warmed)
+		}
+		if len(updater.getCalls()) != 0 {
+			t.Errorf("expected no statistics recorded, got %d", len(updater.getCalls()))
+		}
+
+		useCase.Generate(context.Background(), query)
+		if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+			t.Errorf("expected the warmed query to hit, got %+v", stats)
+		}
+	})
+
+	t.Run("prewarm without cache does nothing", func(t *testing.T) {
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, &mockStatsUpdater{}, 100, newTestLogger())
+		if warmed := useCase.Prewarm(context.Background(), []entity.FizzBuzzQuery{query}); warmed != 0 {
+			t.Errorf("expected nothing warmed, got %d", warmed)
+		}
+	})
+}
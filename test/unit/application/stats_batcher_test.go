@@ -0,0 +1,200 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockBatchUpdater records batches for verification
+type mockBatchUpdater struct {
+	mockStatsUpdater
+	batchMu sync.Mutex
+	batches [][]application.HitDelta
+	flushed chan struct{}
+}
+
+func (m *mockBatchUpdater) UpdateStatsBatch(ctx context.Context, deltas []application.HitDelta) error {
+	m.batchMu.Lock()
+	m.batches = append(m.batches, deltas)
+	m.batchMu.Unlock()
+
+	if m.flushed != nil {
+		m.flushed <- struct{}{}
+	}
+	return nil
+}
+
+func (m *mockBatchUpdater) hitsByLimit() map[int]int64 {
+	m.batchMu.Lock()
+	defer m.batchMu.Unlock()
+
+	hits := make(map[int]int64)
+	for _, batch := range m.batches {
+		for _, delta := range batch {
+			hits[delta.Query.UpperLimit] += delta.Hits
+		}
+	}
+	return hits
+}
+
+func newTestBatcher(updater application.StatisticsUpdater, config application.BatcherConfig) *application.StatisticsBatcher {
+	if config.FlushInterval == 0 {
+		config.FlushInterval = time.Hour
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second
+	}
+	return application.NewStatisticsBatcher(updater, config, newTestLogger())
+}
+
+func TestStatisticsBatcher(t *testing.T) {
+	t.Run("aggregates hits per query", func(t *testing.T) {
+		updater := &mockBatchUpdater{}
+		batcher := newTestBatcher(updater, application.BatcherConfig{Shards: 4, MaxPending: 1000})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 30; j++ {
+					batcher.UpdateStats(context.Background(), queryWithLimit(10+j%3), entity.Caller{})
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err := batcher.Close(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(updater.batches) != 1 || len(updater.batches[0]) != 3 {
+			t.Fatalf("expected one batch of 3 deltas, got %+v", updater.batches)
+		}
+		for limit, hits := range updater.hitsByLimit() {
+			if hits != 100 {
+				t.Errorf("limit %d: expected 100 hits, got %d", limit, hits)
+			}
+		}
+	})
+
+	t.Run("flushes a full buffer early", func(t *testing.T) {
+		updater := &mockBatchUpdater{flushed: make(chan struct{}, 1)}
+		batcher := newTestBatcher(updater, application.BatcherConfig{Shards: 1, MaxPending: 5})
+		defer batcher.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			batcher.UpdateStats(context.Background(), queryWithLimit(10), entity.Caller{})
+		}
+
+		select {
+		case <-updater.flushed:
+		case <-time.After(time.Second):
+			t.Fatal("expected a flush once the buffer is full")
+		}
+		if hits := updater.hitsByLimit()[10]; hits != 5 {
+			t.Errorf("expected 5 hits, got %d", hits)
+		}
+	})
+
+	t.Run("flushes periodically", func(t *testing.T) {
+		updater := &mockBatchUpdater{flushed: make(chan struct{}, 10)}
+		batcher := newTestBatcher(updater, application.BatcherConfig{
+			Shards:        2,
+			MaxPending:    1000,
+			FlushInterval: 10 * time.Millisecond,
+		})
+		defer batcher.Close(context.Background())
+
+		batcher.UpdateStats(context.Background(), queryWithLimit(10), entity.Caller{})
+
+		select {
+		case <-updater.flushed:
+		case <-time.After(time.Second):
+			t.Fatal("expected a periodic flush")
+		}
+	})
+
+	t.Run("keeps hits of different callers apart", func(t *testing.T) {
+		updater := &mockBatchUpdater{}
+		batcher := newTestBatcher(updater, application.BatcherConfig{Shards: 2, MaxPending: 1000})
+
+		alice := entity.Caller{ID: "api_key:alice"}
+		for i := 0; i < 3; i++ {
+			batcher.UpdateStats(context.Background(), queryWithLimit(10), alice)
+		}
+		batcher.UpdateStats(context.Background(), queryWithLimit(10), entity.Caller{ID: "ip:192.0.2.1"})
+		batcher.Close(context.Background())
+
+		hits := make(map[string]int64)
+		for _, batch := range updater.batches {
+			for _, delta := range batch {
+				hits[delta.Caller.ID] += delta.Hits
+			}
+		}
+		if hits[alice.ID] != 3 || hits["ip:192.0.2.1"] != 1 || len(hits) != 2 {
+			t.Errorf("expected 3 hits of alice and 1 of 192.0.2.1, got %v", hits)
+		}
+	})
+
+	t.Run("replays deltas on updaters without batch support", func(t *testing.T) {
+		updater := &mockStatsUpdater{}
+		batcher := newTestBatcher(updater, application.BatcherConfig{Shards: 2, MaxPending: 1000})
+
+		for i := 0; i < 3; i++ {
+			batcher.UpdateStats(context.Background(), queryWithLimit(10), entity.Caller{})
+		}
+		batcher.UpdateStats(context.Background(), queryWithLimit(20), entity.Caller{})
+		batcher.Close(context.Background())
+
+		if calls := updater.getCalls(); len(calls) != 4 {
+			t.Errorf("expected 4 UpdateStats calls, got %d", len(calls))
+		}
+	})
+
+	t.Run("records every hit accepted concurrently with close", func(t *testing.T) {
+		for range 20 {
+			updater := &mockBatchUpdater{}
+			batcher := newTestBatcher(updater, application.BatcherConfig{Shards: 4, MaxPending: 1000})
+
+			var (
+				wg       sync.WaitGroup
+				accepted atomic.Int64
+			)
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					// Adds until rejected, so some race with the final flush
+					for batcher.UpdateStats(context.Background(), queryWithLimit(10), entity.Caller{}) == nil {
+						accepted.Add(1)
+					}
+				}()
+			}
+			if err := batcher.Close(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wg.Wait()
+
+			if hits := updater.hitsByLimit()[10]; hits != accepted.Load() {
+				t.Fatalf("expected the %d accepted hits to be recorded, got %d", accepted.Load(), hits)
+			}
+		}
+	})
+
+	t.Run("rejects hits after close", func(t *testing.T) {
+		batcher := newTestBatcher(&mockBatchUpdater{}, application.BatcherConfig{})
+		batcher.Close(context.Background())
+
+		err := batcher.UpdateStats(context.Background(), entity.FizzBuzzQuery{}, entity.Caller{})
+		if !errors.Is(err, application.ErrBatcherClosed) {
+			t.Errorf("expected ErrBatcherClosed, got %v", err)
+		}
+	})
+}
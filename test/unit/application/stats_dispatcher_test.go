@@ -0,0 +1,271 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedStatsUpdater blocks every update until release is closed
+type gatedStatsUpdater struct {
+	mockStatsUpdater
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGatedStatsUpdater() *gatedStatsUpdater {
+	return &gatedStatsUpdater{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *gatedStatsUpdater) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
+	g.started <- struct{}{}
+	<-g.release
+	return g.mockStatsUpdater.UpdateStats(ctx, query, caller)
+}
+
+func (g *gatedStatsUpdater) open() {
+	g.once.Do(func() { close(g.release) })
+}
+
+func queryWithLimit(limit int) entity.FizzBuzzQuery {
+	return entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: limit,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+}
+
+// newSaturatedDispatcher returns a dispatcher whose single worker is busy with
+// limit=1 and whose queue of one holds limit=2
+func newSaturatedDispatcher(t *testing.T, overflow application.OverflowPolicy) (*application.StatisticsDispatcher, *gatedStatsUpdater) {
+	t.Helper()
+
+	updater := newGatedStatsUpdater()
+	dispatcher := application.NewStatisticsDispatcher(updater, application.DispatcherConfig{
+		QueueSize: 1,
+		Workers:   1,
+		Overflow:  overflow,
+		Timeout:   time.Second,
+	}, newTestLogger())
+	t.Cleanup(func() {
+		updater.open()
+		dispatcher.Close(context.Background())
+	})
+
+	ctx := context.Background()
+	dispatcher.UpdateStats(ctx, queryWithLimit(1), entity.Caller{})
+	<-updater.started
+	if err := dispatcher.UpdateStats(ctx, queryWithLimit(2), entity.Caller{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return dispatcher, updater
+}
+
+func limitsOf(calls []entity.FizzBuzzQuery) []int {
+	limits := make([]int, len(calls))
+	for i, call := range calls {
+		limits[i] = call.UpperLimit
+	}
+	return limits
+}
+
+func TestStatisticsDispatcher(t *testing.T) {
+	t.Run("records hits in the background", func(t *testing.T) {
+		updater := &mockStatsUpdater{}
+		dispatcher := application.NewStatisticsDispatcher(updater, application.DefaultDispatcherConfig(), newTestLogger())
+
+		for i := 1; i <= 50; i++ {
+			if err := dispatcher.UpdateStats(context.Background(), queryWithLimit(i), entity.Caller{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		if err := dispatcher.Close(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(updater.getCalls()) != 50 {
+			t.Errorf("expected 50 recorded hits, got %d", len(updater.getCalls()))
+		}
+		if stats := dispatcher.Stats(); stats.Processed != 50 || stats.QueueDepth != 0 {
+			t.Errorf("expected 50 processed and an empty queue, got %+v", stats)
+		}
+	})
+
+	t.Run("drop discards the new hit", func(t *testing.T) {
+		dispatcher, updater := newSaturatedDispatcher(t, application.OverflowDrop)
+
+		err := dispatcher.UpdateStats(context.Background(), queryWithLimit(3), entity.Caller{})
+		if !errors.Is(err, application.ErrStatisticsDropped) {
+			t.Fatalf("expected ErrStatisticsDropped, got %v", err)
+		}
+
+		stats := dispatcher.Stats()
+		if stats.Dropped != 1 || stats.QueueDepth != 1 || stats.QueueCapacity != 1 {
+			t.Errorf("expected 1 dropped with a full queue of 1, got %+v", stats)
+		}
+
+		updater.open()
+		dispatcher.Close(context.Background())
+		if limits := limitsOf(updater.getCalls()); len(limits) != 2 || limits[1] != 2 {
+			t.Errorf("expected limits [1 2], got %v", limits)
+		}
+	})
+
+	t.Run("drop-oldest makes room for the new hit", func(t *testing.T) {
+		dispatcher, updater := newSaturatedDispatcher(t, application.OverflowDropOldest)
+
+		if err := dispatcher.UpdateStats(context.Background(), queryWithLimit(3), entity.Caller{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dropped := dispatcher.Stats().Dropped; dropped != 1 {
+			t.Errorf("expected 1 dropped, got %d", dropped)
+		}
+
+		updater.open()
+		dispatcher.Close(context.Background())
+		if limits := limitsOf(updater.getCalls()); len(limits) != 2 || limits[1] != 3 {
+			t.Errorf("expected limits [1 3], got %v", limits)
+		}
+	})
+
+	t.Run("block waits for room until the context ends", func(t *testing.T) {
+		dispatcher, updater := newSaturatedDispatcher(t, application.OverflowBlock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := dispatcher.UpdateStats(ctx, queryWithLimit(3), entity.Caller{})
+		if !errors.Is(err, application.ErrStatisticsDropped) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected a dropped hit after the deadline, got %v", err)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- dispatcher.UpdateStats(context.Background(), queryWithLimit(4), entity.Caller{})
+		}()
+		updater.open()
+
+		if err := <-done; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dispatcher.Close(context.Background())
+		if limits := limitsOf(updater.getCalls()); len(limits) != 3 || limits[2] != 4 {
+			t.Errorf("expected limits [1 2 4], got %v", limits)
+		}
+	})
+
+	t.Run("close drains pending hits and rejects new ones", func(t *testing.T) {
+		dispatcher, updater := newSaturatedDispatcher(t, application.OverflowDrop)
+
+		closed := make(chan error, 1)
+		go func() {
+			closed <- dispatcher.Close(context.Background())
+		}()
+
+		select {
+		case <-closed:
+			t.Fatal("close returned before pending hits were recorded")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		updater.open()
+		if err := <-closed; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls := updater.getCalls(); len(calls) != 2 {
+			t.Errorf("expected 2 recorded hits, got %d", len(calls))
+		}
+
+		err := dispatcher.UpdateStats(context.Background(), queryWithLimit(3), entity.Caller{})
+		if !errors.Is(err, application.ErrDispatcherClosed) {
+			t.Errorf("expected ErrDispatcherClosed, got %v", err)
+		}
+	})
+
+	t.Run("close gives up when its context ends", func(t *testing.T) {
+		dispatcher, _ := newSaturatedDispatcher(t, application.OverflowDrop)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if err := dispatcher.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("close releases senders blocked on a full queue", func(t *testing.T) {
+		dispatcher, updater := newSaturatedDispatcher(t, application.OverflowBlock)
+
+		sent := make(chan error, 1)
+		go func() {
+			sent <- dispatcher.UpdateStats(context.Background(), queryWithLimit(3), entity.Caller{})
+		}()
+		// Let the sender block on the full queue
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		closed := make(chan error, 1)
+		go func() {
+			closed <- dispatcher.Close(ctx)
+		}()
+
+		select {
+		case err := <-sent:
+			if !errors.Is(err, application.ErrDispatcherClosed) {
+				t.Errorf("expected ErrDispatcherClosed, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("sender stayed blocked after close")
+		}
+		select {
+		case err := <-closed:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected context.DeadlineExceeded while hits are pending, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("close outlived its context")
+		}
+
+		updater.open()
+		if err := dispatcher.Close(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if calls := updater.getCalls(); len(calls) != 2 {
+			t.Errorf("expected the 2 queued hits to be recorded, got %d", len(calls))
+		}
+	})
+
+	t.Run("failures are counted", func(t *testing.T) {
+		dispatcher := application.NewStatisticsDispatcher(&mockStatsUpdater{shouldErr: true},
+			application.DefaultDispatcherConfig(), newTestLogger())
+
+		dispatcher.UpdateStats(context.Background(), queryWithLimit(1), entity.Caller{})
+		dispatcher.Close(context.Background())
+
+		if stats := dispatcher.Stats(); stats.Failed != 1 || stats.Processed != 0 {
+			t.Errorf("expected 1 failure, got %+v", stats)
+		}
+	})
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, name := range []string{"drop", "drop-oldest", "block"} {
+		if _, err := application.ParseOverflowPolicy(name); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+
+	if _, err := application.ParseOverflowPolicy("ignore"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
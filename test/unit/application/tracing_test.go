@@ -0,0 +1,131 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/domain/service"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecordingProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+// spanNamed returns the ended span with the given name
+func spanNamed(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %q", name)
+	return nil
+}
+
+func TestGenerateFizzBuzzUseCase_Tracing(t *testing.T) {
+	t.Run("generate spans are children of the caller's", func(t *testing.T) {
+		tp, recorder := newRecordingProvider()
+		useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), &mockStatsUpdater{}, 100, newTestLogger(),
+			application.WithTracerProvider(tp),
+		)
+
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+		if _, err := useCase.Generate(ctx, queryWithLimit(15)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parent.End()
+
+		uc := spanNamed(t, recorder, "GenerateFizzBuzzUseCase.Generate")
+		if uc.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error("use case span should be a child of the caller's span")
+		}
+
+		gen := spanNamed(t, recorder, "FizzBuzzGenerator.Generate")
+		if gen.Parent().SpanID() != uc.SpanContext().SpanID() {
+			t.Error("generator span should be a child of the use case span")
+		}
+		if !hasAttribute(gen, "fizzbuzz.result_length", 15) {
+			t.Errorf("expected the result length on the generator span, got %v", gen.Attributes())
+		}
+	})
+
+	t.Run("invalid queries fail the span without generating", func(t *testing.T) {
+		tp, recorder := newRecordingProvider()
+		useCase := application.NewGenerateFizzBuzzUseCase(service.NewFizzBuzzGenerator(), &mockStatsUpdater{}, 100, newTestLogger(),
+			application.WithTracerProvider(tp),
+		)
+
+		if _, err := useCase.Generate(context.Background(), queryWithLimit(1000)); err == nil {
+			t.Fatal("expected a validation error")
+		}
+
+		if status := spanNamed(t, recorder, "GenerateFizzBuzzUseCase.Generate").Status(); status.Code != codes.Error {
+			t.Errorf("expected an error status, got %v", status)
+		}
+		if len(recorder.Ended()) != 1 {
+			t.Errorf("expected no generator span, got %d spans", len(recorder.Ended()))
+		}
+	})
+}
+
+func TestStatisticsDispatcher_Tracing(t *testing.T) {
+	t.Run("background update links to the request span", func(t *testing.T) {
+		tp, recorder := newRecordingProvider()
+		dispatcher := application.NewStatisticsDispatcher(&mockStatsUpdater{}, application.DefaultDispatcherConfig(), newTestLogger(),
+			application.WithDispatcherTracerProvider(tp),
+		)
+
+		ctx, request := tp.Tracer("test").Start(context.Background(), "request")
+		if err := dispatcher.UpdateStats(ctx, queryWithLimit(15), entity.Caller{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		request.End()
+		if err := dispatcher.Close(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		record := spanNamed(t, recorder, "StatisticsDispatcher.record")
+		if record.Parent().IsValid() {
+			t.Error("background span should start a new trace")
+		}
+		links := record.Links()
+		if len(links) != 1 || !links[0].SpanContext.Equal(request.SpanContext()) {
+			t.Errorf("expected a link to the request span, got %v", links)
+		}
+	})
+
+	t.Run("failed updates fail the span", func(t *testing.T) {
+		tp, recorder := newRecordingProvider()
+		dispatcher := application.NewStatisticsDispatcher(&mockStatsUpdater{shouldErr: true}, application.DefaultDispatcherConfig(), newTestLogger(),
+			application.WithDispatcherTracerProvider(tp),
+		)
+
+		dispatcher.UpdateStats(context.Background(), queryWithLimit(15), entity.Caller{})
+		dispatcher.Close(context.Background())
+
+		record := spanNamed(t, recorder, "StatisticsDispatcher.record")
+		if record.Status().Code != codes.Error {
+			t.Errorf("expected an error status, got %v", record.Status())
+		}
+		if len(record.Links()) != 0 {
+			t.Errorf("expected no link without a request span, got %v", record.Links())
+		}
+	})
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, key string, value int) bool {
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key && attr.Value.AsInt64() == int64(value) {
+			return true
+		}
+	}
+	return false
+}
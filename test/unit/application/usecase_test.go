@@ -19,10 +19,11 @@ import (
 type mockStatsUpdater struct {
 	mu        sync.Mutex
 	calls     []entity.FizzBuzzQuery
+	callers   []entity.Caller
 	shouldErr bool
 }
 
-func (m *mockStatsUpdater) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery) error {
+func (m *mockStatsUpdater) UpdateStats(ctx context.Context, query entity.FizzBuzzQuery, caller entity.Caller) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -31,6 +32,7 @@ func (m *mockStatsUpdater) UpdateStats(ctx context.Context, query entity.FizzBuz
 	}
 
 	m.calls = append(m.calls, query)
+	m.callers = append(m.callers, caller)
 	return nil
 }
 
@@ -127,14 +129,14 @@ func TestGenerateFizzBuzzUseCase_Execute(t *testing.T) {
 
 		// Check that error details mention the limit
 		found := false
-		for _, detail := range validationErr.Details {
-			if strings.Contains(detail, "limit") {
+		for _, v := range validationErr.Violations {
+			if v.Field == "limit" {
 				found = true
 				break
 			}
 		}
 		if !found {
-			t.Errorf("expected error details to mention 'limit', got: %v", validationErr.Details)
+			t.Errorf("expected a violation of 'limit', got: %v", validationErr.Violations)
 		}
 	})
 
@@ -157,8 +159,8 @@ func TestGenerateFizzBuzzUseCase_Execute(t *testing.T) {
 			t.Fatalf("expected ValidationError, got %T", err)
 		}
 
-		if len(validationErr.Details) != 5 {
-			t.Errorf("expected 5 validation errors, got %d: %v", len(validationErr.Details), validationErr.Details)
+		if len(validationErr.Violations) != 5 {
+			t.Errorf("expected 5 validation errors, got %d: %v", len(validationErr.Violations), validationErr.Violations)
 		}
 	})
 
@@ -192,6 +194,24 @@ func TestGenerateFizzBuzzUseCase_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("hits are attributed to the caller of the context", func(t *testing.T) {
+		mockUpdater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger)
+
+		ctx := application.WithCaller(context.Background(), entity.Caller{ID: "api_key:ci"})
+		if _, err := useCase.Generate(ctx, queryWithLimit(10)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		mockUpdater.mu.Lock()
+		defer mockUpdater.mu.Unlock()
+		if len(mockUpdater.callers) != 1 || mockUpdater.callers[0].ID != "api_key:ci" {
+			t.Errorf("expected the hit attributed to api_key:ci, got %v", mockUpdater.callers)
+		}
+	})
+
 	t.Run("stats error does not fail the request", func(t *testing.T) {
 		mockUpdater := &mockStatsUpdater{shouldErr: true}
 		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger)
@@ -217,6 +237,162 @@ func TestGenerateFizzBuzzUseCase_Execute(t *testing.T) {
 	})
 }
 
+func TestGenerateFizzBuzzUseCase_Stream(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+
+	query := entity.FizzBuzzQuery{
+		FirstDivisor:  3,
+		SecondDivisor: 5,
+		UpperLimit:    1000,
+		FirstString:   "fizz",
+		SecondString:  "buzz",
+	}
+
+	t.Run("accepts limits up to the stream maximum", func(t *testing.T) {
+		mockUpdater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger,
+			application.WithStreamMaxLimit(1000),
+		)
+
+		seq, err := useCase.Stream(context.Background(), query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		count := 0
+		for range seq {
+			count++
+		}
+		if count != 1000 {
+			t.Errorf("expected 1000 values, got %d", count)
+		}
+
+		// Generate keeps its own, lower maximum
+		if _, err := useCase.Generate(context.Background(), query); err == nil {
+			t.Error("expected Generate to reject a limit above maxLimit")
+		}
+	})
+
+	t.Run("stream maximum defaults to maxLimit", func(t *testing.T) {
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, &mockStatsUpdater{}, 100, logger)
+
+		_, err := useCase.Stream(context.Background(), query)
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+	})
+
+	t.Run("records one hit per stream", func(t *testing.T) {
+		mockUpdater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger,
+			application.WithStreamMaxLimit(1000),
+		)
+
+		if _, err := useCase.Stream(context.Background(), query); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		if calls := mockUpdater.getCalls(); len(calls) != 1 {
+			t.Errorf("expected 1 stats update call, got %d", len(calls))
+		}
+	})
+}
+
+func TestGenerateFizzBuzzUseCase_GeneratePage(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	logger := newTestLogger()
+
+	query := entity.FizzBuzzQuery{
+		FirstDivisor:  3,
+		SecondDivisor: 5,
+		UpperLimit:    100,
+		FirstString:   "fizz",
+		SecondString:  "buzz",
+	}
+
+	t.Run("generates only the page and records the whole query", func(t *testing.T) {
+		mockUpdater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger)
+
+		result, err := useCase.GeneratePage(context.Background(), query, entity.Page{Offset: 10, Count: 5})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(result, ",") != "11,fizz,13,14,fizzbuzz" {
+			t.Errorf("unexpected page %v", result)
+		}
+
+		calls := mockUpdater.getCalls()
+		if len(calls) != 1 || calls[0].Key() != query.Key() {
+			t.Errorf("expected one hit of %s, got %v", query.Key(), calls)
+		}
+	})
+
+	t.Run("truncates the last page", func(t *testing.T) {
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, &mockStatsUpdater{}, 100, logger)
+
+		result, err := useCase.GeneratePage(context.Background(), query, entity.Page{Offset: 95, Count: 10})
+		if err != nil || len(result) != 5 || result[4] != "buzz" {
+			t.Errorf("expected the 5 last values, got %v, %v", result, err)
+		}
+	})
+
+	t.Run("reports the query and the page together", func(t *testing.T) {
+		mockUpdater := &mockStatsUpdater{}
+		useCase := application.NewGenerateFizzBuzzUseCase(generator, mockUpdater, 100, logger)
+		invalid := query
+		invalid.FirstString = ""
+
+		_, err := useCase.GeneratePage(context.Background(), invalid, entity.Page{Offset: 100, Count: 10})
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+		if len(validationErr.Violations) != 2 ||
+			validationErr.Violations[0].Field != "str1" || validationErr.Violations[1].Field != "offset" {
+			t.Errorf("expected str1 and offset violations, got %+v", validationErr.Violations)
+		}
+		if len(mockUpdater.getCalls()) != 0 {
+			t.Error("expected no hit recorded for an invalid page")
+		}
+	})
+}
+
+func TestGenerateFizzBuzzUseCase_SetMaxLimit(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	useCase := application.NewGenerateFizzBuzzUseCase(generator, &mockStatsUpdater{}, 100, newTestLogger())
+	query := entity.FizzBuzzQuery{
+		FirstDivisor:  3,
+		SecondDivisor: 5,
+		UpperLimit:    150,
+		FirstString:   "fizz",
+		SecondString:  "buzz",
+	}
+
+	if _, err := useCase.Generate(context.Background(), query); err == nil {
+		t.Fatal("expected limit 150 to exceed the maximum of 100")
+	}
+
+	useCase.SetMaxLimit(200)
+	if _, err := useCase.Generate(context.Background(), query); err != nil {
+		t.Errorf("expected raised maximum to accept limit 150, got %v", err)
+	}
+	if _, err := useCase.Stream(context.Background(), query); err == nil {
+		t.Error("stream maximum should not follow SetMaxLimit")
+	}
+
+	useCase.SetStreamMaxLimit(150)
+	if _, err := useCase.Stream(context.Background(), query); err != nil {
+		t.Errorf("expected raised stream maximum to accept limit 150, got %v", err)
+	}
+}
+
 func TestGetStatisticsUseCase_Execute(t *testing.T) {
 	t.Run("returns stats from repository", func(t *testing.T) {
 		mockRepo := &mockStatsRepository{
@@ -267,11 +443,300 @@ func TestGetStatisticsUseCase_Execute(t *testing.T) {
 	})
 }
 
+func TestGetStatisticsUseCase_Top(t *testing.T) {
+	entries := []entity.StatisticsEntry{
+		{Rank: 1, HitCount: 5, Key: "a"},
+		{Rank: 2, HitCount: 3, Key: "b"},
+		{Rank: 3, HitCount: 1, Key: "c"},
+	}
+
+	t.Run("returns a cursor when more entries exist", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{entries: entries}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		page, err := useCase.Top(context.Background(), application.TopRequest{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(page.Entries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(page.Entries))
+		}
+		if page.NextCursor == "" {
+			t.Fatal("expected a next cursor")
+		}
+
+		cursor, err := entity.DecodeRankCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("unexpected cursor error: %v", err)
+		}
+		if cursor.Key != "b" {
+			t.Errorf("expected cursor after 'b', got %q", cursor.Key)
+		}
+	})
+
+	t.Run("passes the cursor to the repository", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{entries: entries}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		cursor := entity.CursorOf(entries[1]).Encode()
+		page, err := useCase.Top(context.Background(), application.TopRequest{Limit: 5, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if mockRepo.topQuery.After == nil || mockRepo.topQuery.After.Key != "b" {
+			t.Errorf("expected repository query after 'b', got %+v", mockRepo.topQuery.After)
+		}
+		if page.NextCursor != "" {
+			t.Errorf("expected no next cursor on the last page, got %q", page.NextCursor)
+		}
+	})
+
+	t.Run("passes the window to the repository", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{entries: entries}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		if _, err := useCase.Top(context.Background(), application.TopRequest{Limit: 5, Window: time.Hour}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if mockRepo.topQuery.Window != time.Hour {
+			t.Errorf("expected 1h window, got %s", mockRepo.topQuery.Window)
+		}
+	})
+
+	t.Run("rejects invalid limit, cursor and window", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		_, err := useCase.Top(context.Background(), application.TopRequest{Limit: 0, Cursor: "not-a-cursor", Window: time.Second})
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+		if len(validationErr.Violations) != 3 {
+			t.Errorf("expected 3 validation errors, got %v", validationErr.Violations)
+		}
+	})
+
+	t.Run("empty ranking returns an empty list", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		page, err := useCase.Top(context.Background(), application.TopRequest{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.Entries == nil || len(page.Entries) != 0 {
+			t.Errorf("expected an empty, non-nil list, got %#v", page.Entries)
+		}
+	})
+}
+
+func TestGetStatisticsUseCase_TopQueries(t *testing.T) {
+	queries := []entity.FizzBuzzQuery{
+		{FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15, FirstString: "fizz", SecondString: "buzz"},
+		{UpperLimit: 21, Rules: []entity.Rule{{Divisor: 7, Word: "bazz"}}},
+		{FirstDivisor: 2, SecondDivisor: 4, UpperLimit: 8, FirstString: "a", SecondString: "b"},
+	}
+	var entries []entity.StatisticsEntry
+	for i, q := range queries {
+		entries = append(entries, entity.StatisticsEntry{Rank: i + 1, Query: q.ToResponse(), HitCount: int64(10 - i), Key: q.Key()})
+	}
+	useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{entries: entries})
+
+	top, err := useCase.TopQueries(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(top) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(top))
+	}
+	for i, q := range top {
+		if q.Key() != queries[i].Key() {
+			t.Errorf("query %d: expected key %s, got %s", i, queries[i].Key(), q.Key())
+		}
+	}
+}
+
+func TestGetStatisticsUseCase_GetInWindow(t *testing.T) {
+	t.Run("returns the top entry of the window", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{entries: []entity.StatisticsEntry{
+			{Rank: 1, HitCount: 7, Key: "a", Query: &entity.FizzBuzzQueryResponse{Limit: 15}},
+		}}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		stats, err := useCase.GetInWindow(context.Background(), 5*time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats.HitCount != 7 || stats.MostFrequentQuery.Limit != 15 {
+			t.Errorf("unexpected summary %+v", stats)
+		}
+		if mockRepo.topQuery.Window != 5*time.Minute || mockRepo.topQuery.Limit != 1 {
+			t.Errorf("unexpected repository query %+v", mockRepo.topQuery)
+		}
+	})
+
+	t.Run("empty window returns null request", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		stats, err := useCase.GetInWindow(context.Background(), time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.MostFrequentQuery != nil || stats.HitCount != 0 {
+			t.Errorf("expected empty summary, got %+v", stats)
+		}
+	})
+
+	t.Run("rejects windows beyond a day", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		_, err := useCase.GetInWindow(context.Background(), 48*time.Hour)
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+	})
+}
+
+func TestGetStatisticsUseCase_GetForClient(t *testing.T) {
+	t.Run("returns the top entry of the client", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{entries: []entity.StatisticsEntry{
+			{Rank: 1, HitCount: 4, Key: "a", Query: &entity.FizzBuzzQueryResponse{Limit: 15}},
+		}}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		stats, err := useCase.GetForClient(context.Background(), "jwt:alice", 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats.HitCount != 4 || stats.MostFrequentQuery.Limit != 15 {
+			t.Errorf("unexpected summary %+v", stats)
+		}
+		if mockRepo.topQuery.Client != "jwt:alice" || mockRepo.topQuery.Limit != 1 {
+			t.Errorf("unexpected repository query %+v", mockRepo.topQuery)
+		}
+	})
+
+	t.Run("rejects an unknown client and a window", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		_, err := useCase.GetForClient(context.Background(), "", time.Hour)
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+		if len(validationErr.Violations) != 2 {
+			t.Errorf("expected 2 validation errors, got %v", validationErr.Violations)
+		}
+	})
+
+	t.Run("top of a client rejects a window", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		_, err := useCase.Top(context.Background(), application.TopRequest{Limit: 5, Client: "jwt:alice", Window: time.Hour})
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+	})
+}
+
+func TestGetStatisticsUseCase_Clients(t *testing.T) {
+	clients := []entity.ClientEntry{
+		{Rank: 1, HitCount: 5, Client: "api_key:a"},
+		{Rank: 2, HitCount: 3, Client: "jwt:b"},
+		{Rank: 3, HitCount: 1, Client: "ip:192.0.2.1"},
+	}
+
+	t.Run("returns a cursor when more clients exist", func(t *testing.T) {
+		mockRepo := &mockStatsRepository{clients: clients}
+		useCase := application.NewGetStatisticsUseCase(mockRepo)
+
+		page, err := useCase.Clients(context.Background(), application.ClientsRequest{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(page.Entries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(page.Entries))
+		}
+		cursor, err := entity.DecodeRankCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("unexpected cursor error: %v", err)
+		}
+		if cursor.Key != "jwt:b" {
+			t.Errorf("expected cursor after 'jwt:b', got %q", cursor.Key)
+		}
+
+		if _, err := useCase.Clients(context.Background(), application.ClientsRequest{Limit: 2, Cursor: page.NextCursor}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockRepo.clientQuery.After == nil || mockRepo.clientQuery.After.Key != "jwt:b" {
+			t.Errorf("expected repository query after 'jwt:b', got %+v", mockRepo.clientQuery.After)
+		}
+	})
+
+	t.Run("rejects invalid limit and cursor", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		_, err := useCase.Clients(context.Background(), application.ClientsRequest{Limit: 101, Cursor: "not-a-cursor"})
+
+		var validationErr domain.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T", err)
+		}
+		if len(validationErr.Violations) != 2 {
+			t.Errorf("expected 2 validation errors, got %v", validationErr.Violations)
+		}
+	})
+
+	t.Run("no clients returns an empty list", func(t *testing.T) {
+		useCase := application.NewGetStatisticsUseCase(&mockStatsRepository{})
+
+		page, err := useCase.Clients(context.Background(), application.ClientsRequest{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.Entries == nil || len(page.Entries) != 0 {
+			t.Errorf("expected an empty, non-nil list, got %#v", page.Entries)
+		}
+	})
+}
+
 type mockStatsRepository struct {
-	summary *entity.StatisticsSummary
-	err     error
+	summary     *entity.StatisticsSummary
+	entries     []entity.StatisticsEntry
+	topQuery    entity.TopQuery
+	clients     []entity.ClientEntry
+	clientQuery entity.ClientQuery
+	err         error
 }
 
 func (m *mockStatsRepository) GetMostFrequent(ctx context.Context) (*entity.StatisticsSummary, error) {
 	return m.summary, m.err
 }
+
+func (m *mockStatsRepository) GetTop(ctx context.Context, query entity.TopQuery) ([]entity.StatisticsEntry, error) {
+	m.topQuery = query
+	if len(m.entries) > query.Limit {
+		return m.entries[:query.Limit], m.err
+	}
+	return m.entries, m.err
+}
+
+func (m *mockStatsRepository) GetClients(ctx context.Context, query entity.ClientQuery) ([]entity.ClientEntry, error) {
+	m.clientQuery = query
+	if len(m.clients) > query.Limit {
+		return m.clients[:query.Limit], m.err
+	}
+	return m.clients, m.err
+}
@@ -1,6 +1,8 @@
 package domain_test
 
 import (
+	"encoding/json"
+	"fizzbuzz-service/internal/domain"
 	"fizzbuzz-service/internal/domain/entity"
 	"strings"
 	"testing"
@@ -149,6 +151,55 @@ func TestFizzBuzzQuery_Validate(t *testing.T) {
 			maxLimit:    10000,
 			expectValid: true, // This is valid - same divisors are allowed
 		},
+		{
+			name: "valid - three rules",
+			query: entity.FizzBuzzQuery{
+				UpperLimit: 105,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "fizz"},
+					{Divisor: 5, Word: "buzz"},
+					{Divisor: 7, Word: "bazz"},
+				},
+			},
+			maxLimit:    10000,
+			expectValid: true,
+		},
+		{
+			name: "invalid - rule with zero divisor and empty word",
+			query: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "fizz"},
+					{Divisor: 0, Word: ""},
+				},
+			},
+			maxLimit:       10000,
+			expectValid:    false,
+			expectErrors:   2,
+			errorSubstring: "rules[1].divisor",
+		},
+		{
+			name: "invalid - rules mixed with int1",
+			query: entity.FizzBuzzQuery{
+				FirstDivisor: 3,
+				UpperLimit:   15,
+				Rules:        []entity.Rule{{Divisor: 5, Word: "buzz"}},
+			},
+			maxLimit:       10000,
+			expectValid:    false,
+			expectErrors:   1,
+			errorSubstring: "cannot be combined",
+		},
+		{
+			name: "invalid - too many rules",
+			query: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules:      make([]entity.Rule, entity.MaxRules+1),
+			},
+			maxLimit:       10000,
+			expectValid:    false,
+			errorSubstring: "more than",
+		},
 	}
 
 	for _, tt := range tests {
@@ -159,26 +210,72 @@ func TestFizzBuzzQuery_Validate(t *testing.T) {
 				t.Errorf("expected Valid=%v, got Valid=%v", tt.expectValid, result.Valid)
 			}
 
-			if tt.expectErrors > 0 && len(result.Errors) != tt.expectErrors {
-				t.Errorf("expected %d errors, got %d: %v", tt.expectErrors, len(result.Errors), result.Errors)
+			if tt.expectErrors > 0 && len(result.Violations) != tt.expectErrors {
+				t.Errorf("expected %d errors, got %d: %v", tt.expectErrors, len(result.Violations), result.Violations)
 			}
 
 			if tt.errorSubstring != "" {
 				found := false
-				for _, err := range result.Errors {
-					if strings.Contains(err, tt.errorSubstring) {
+				for _, v := range result.Violations {
+					if strings.Contains(v.Message, tt.errorSubstring) {
 						found = true
 						break
 					}
 				}
 				if !found {
-					t.Errorf("expected error containing %q, got: %v", tt.errorSubstring, result.Errors)
+					t.Errorf("expected error containing %q, got: %v", tt.errorSubstring, result.Violations)
 				}
 			}
 		})
 	}
 }
 
+func TestFizzBuzzQuery_ValidateViolations(t *testing.T) {
+	t.Run("two-divisor form", func(t *testing.T) {
+		query := entity.FizzBuzzQuery{SecondDivisor: 5, UpperLimit: 101, FirstString: "fizz"}
+
+		result := query.Validate(100)
+
+		expected := []struct{ field, code string }{
+			{"int1", domain.CodeMustBePositive},
+			{"str2", domain.CodeRequired},
+			{"limit", domain.CodeExceedsMax},
+		}
+		if len(result.Violations) != len(expected) {
+			t.Fatalf("expected %d violations, got %+v", len(expected), result.Violations)
+		}
+		for i, e := range expected {
+			v := result.Violations[i]
+			if v.Field != e.field || v.Code != e.code {
+				t.Errorf("violation %d: expected %s %s, got %+v", i, e.field, e.code, v)
+			}
+		}
+		if max := result.Violations[2].Params["max"]; max != 100 {
+			t.Errorf("expected max 100 in params, got %v", max)
+		}
+	})
+
+	t.Run("rules form", func(t *testing.T) {
+		query := entity.FizzBuzzQuery{
+			FirstString: "fizz",
+			UpperLimit:  15,
+			Rules:       []entity.Rule{{Divisor: 3, Word: "fizz"}, {Divisor: -1, Word: "buzz"}},
+		}
+
+		result := query.Validate(100)
+
+		if len(result.Violations) != 2 {
+			t.Fatalf("expected 2 violations, got %+v", result.Violations)
+		}
+		if v := result.Violations[0]; v.Field != "rules" || v.Code != domain.CodeMutuallyExclusive {
+			t.Errorf("expected rules to be mutually exclusive, got %+v", v)
+		}
+		if v := result.Violations[1]; v.Field != "rules[1].divisor" || v.Code != domain.CodeMustBePositive || v.Params != nil {
+			t.Errorf("expected rules[1].divisor to be positive, got %+v", v)
+		}
+	})
+}
+
 func TestFizzBuzzQuery_Key(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -234,6 +331,73 @@ func TestFizzBuzzQuery_Key(t *testing.T) {
 			},
 			sameKey: false,
 		},
+		{
+			name: "two rules share the key of the equivalent pair",
+			query1: entity.FizzBuzzQuery{
+				FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+				FirstString: "fizz", SecondString: "buzz",
+			},
+			query2: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "fizz"},
+					{Divisor: 5, Word: "buzz"},
+				},
+			},
+			sameKey: true,
+		},
+		{
+			name: "separators inside words do not merge pairs",
+			query1: entity.FizzBuzzQuery{
+				FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 6,
+				FirstString: "fizz:x", SecondString: "buzz",
+			},
+			query2: entity.FizzBuzzQuery{
+				FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 6,
+				FirstString: "fizz", SecondString: "x:buzz",
+			},
+			sameKey: false,
+		},
+		{
+			name: "separators inside words do not merge rules",
+			query1: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: `a"|5:"b`},
+					{Divisor: 7, Word: "c"},
+					{Divisor: 9, Word: "d"},
+				},
+			},
+			query2: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "a"},
+					{Divisor: 5, Word: "b"},
+					{Divisor: 7, Word: "c"},
+				},
+			},
+			sameKey: false,
+		},
+		{
+			name: "rule order matters",
+			query1: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "fizz"},
+					{Divisor: 5, Word: "buzz"},
+					{Divisor: 7, Word: "bazz"},
+				},
+			},
+			query2: entity.FizzBuzzQuery{
+				UpperLimit: 15,
+				Rules: []entity.Rule{
+					{Divisor: 7, Word: "bazz"},
+					{Divisor: 5, Word: "buzz"},
+					{Divisor: 3, Word: "fizz"},
+				},
+			},
+			sameKey: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,3 +415,89 @@ func TestFizzBuzzQuery_Key(t *testing.T) {
 		})
 	}
 }
+
+func TestPage(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			page   entity.Page
+			limit  int
+			fields []string
+		}{
+			{name: "first page", page: entity.Page{Offset: 0, Count: 10}, limit: 100},
+			{name: "last page truncated", page: entity.Page{Offset: 95, Count: 10}, limit: 100},
+			{name: "whole sequence", page: entity.Page{Offset: 0, Count: 100}, limit: 100},
+			{name: "negative offset", page: entity.Page{Offset: -1, Count: 10}, limit: 100, fields: []string{"offset"}},
+			{name: "offset past limit", page: entity.Page{Offset: 100, Count: 10}, limit: 100, fields: []string{"offset"}},
+			{name: "zero count", page: entity.Page{Offset: 0, Count: 0}, limit: 100, fields: []string{"count"}},
+			{name: "count beyond limit", page: entity.Page{Offset: 0, Count: 101}, limit: 100, fields: []string{"count"}},
+			{name: "invalid limit is not checked twice", page: entity.Page{Offset: 5, Count: 0}, limit: 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				violations := tt.page.Validate(tt.limit)
+				if len(violations) != len(tt.fields) {
+					t.Fatalf("expected violations of %v, got %+v", tt.fields, violations)
+				}
+				for i, v := range violations {
+					if v.Field != tt.fields[i] || v.Code != domain.CodeOutOfRange {
+						t.Errorf("expected %s out of range, got %+v", tt.fields[i], v)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("navigation", func(t *testing.T) {
+		page := entity.Page{Offset: 25, Count: 10}
+
+		if page.Start() != 26 || page.End(100) != 35 || page.End(30) != 30 {
+			t.Errorf("unexpected bounds %d-%d", page.Start(), page.End(100))
+		}
+		if prev, ok := page.Prev(); !ok || prev.Offset != 15 {
+			t.Errorf("expected previous page at 15, got %+v", prev)
+		}
+		if prev, _ := (entity.Page{Offset: 5, Count: 10}).Prev(); prev.Offset != 0 {
+			t.Errorf("expected previous page clamped to 0, got %+v", prev)
+		}
+		if _, ok := (entity.Page{Count: 10}).Prev(); ok {
+			t.Error("expected no page before the first")
+		}
+		if next, ok := page.Next(100); !ok || next.Offset != 35 {
+			t.Errorf("expected next page at 35, got %+v", next)
+		}
+		if _, ok := page.Next(35); ok {
+			t.Error("expected no page after the last")
+		}
+		if last := page.LastPage(100); last.Offset != 90 {
+			t.Errorf("expected last page at 90, got %+v", last)
+		}
+	})
+}
+
+func TestFizzBuzzQuery_ToResponse(t *testing.T) {
+	t.Run("the pair fields are always present", func(t *testing.T) {
+		query := entity.FizzBuzzQuery{
+			UpperLimit: 105,
+			Rules: []entity.Rule{
+				{Divisor: 3, Word: "fizz"},
+				{Divisor: 5, Word: "buzz"},
+				{Divisor: 7, Word: "bazz"},
+			},
+		}
+
+		data, _ := json.Marshal(query.ToResponse())
+		expected := `{"int1":0,"int2":0,"limit":105,"str1":"","str2":"","rules":[{"divisor":3,"word":"fizz"},{"divisor":5,"word":"buzz"},{"divisor":7,"word":"bazz"}]}`
+		if string(data) != expected {
+			t.Errorf("expected %s, got %s", expected, data)
+		}
+	})
+
+	t.Run("rules are omitted when empty", func(t *testing.T) {
+		data, _ := json.Marshal(entity.FizzBuzzQueryResponse{Int1: 3, Int2: 5, Limit: 15, Str1: "fizz", Str2: "buzz"})
+		if expected := `{"int1":3,"int2":5,"limit":15,"str1":"fizz","str2":"buzz"}`; string(data) != expected {
+			t.Errorf("expected %s, got %s", expected, data)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"strings"
 	"testing"
 
 	"fizzbuzz-service/internal/domain/entity"
@@ -99,6 +100,30 @@ func TestFizzBuzzGenerator_Generate(t *testing.T) {
 			},
 			expected: []string{"1", "🎉", "✨", "🎉", "5", "🎉✨"},
 		},
+		{
+			name: "three rules joined in rule order",
+			query: entity.FizzBuzzQuery{
+				UpperLimit: 21,
+				Rules: []entity.Rule{
+					{Divisor: 3, Word: "fizz"},
+					{Divisor: 5, Word: "buzz"},
+					{Divisor: 7, Word: "bazz"},
+				},
+			},
+			expected: []string{
+				"1", "2", "fizz", "4", "buzz", "fizz", "bazz",
+				"8", "fizz", "buzz", "11", "fizz", "13", "bazz",
+				"fizzbuzz", "16", "17", "fizz", "19", "buzz", "fizzbazz",
+			},
+		},
+		{
+			name: "single rule",
+			query: entity.FizzBuzzQuery{
+				UpperLimit: 4,
+				Rules:      []entity.Rule{{Divisor: 2, Word: "even"}},
+			},
+			expected: []string{"1", "even", "3", "even"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,3 +159,80 @@ func BenchmarkFizzBuzzGenerator_Generate(b *testing.B) {
 		generator.Generate(query)
 	}
 }
+
+func TestFizzBuzzGenerator_Sequence(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	query := entity.FizzBuzzQuery{
+		FirstDivisor:  3,
+		SecondDivisor: 5,
+		UpperLimit:    15,
+		FirstString:   "fizz",
+		SecondString:  "buzz",
+	}
+
+	t.Run("yields the same values as Generate", func(t *testing.T) {
+		expected := generator.Generate(query)
+
+		i := 0
+		for n, v := range generator.Sequence(query) {
+			if n != i+1 {
+				t.Errorf("expected number %d, got %d", i+1, n)
+			}
+			if v != expected[i] {
+				t.Errorf("position %d: expected %q, got %q", n, expected[i], v)
+			}
+			i++
+		}
+
+		if i != len(expected) {
+			t.Errorf("expected %d values, got %d", len(expected), i)
+		}
+	})
+
+	t.Run("stops when the consumer stops", func(t *testing.T) {
+		huge := query
+		huge.UpperLimit = 1 << 40
+
+		count := 0
+		for range generator.Sequence(huge) {
+			count++
+			if count == 10 {
+				break
+			}
+		}
+
+		if count != 10 {
+			t.Errorf("expected 10 values, got %d", count)
+		}
+	})
+}
+
+func TestFizzBuzzGenerator_GenerateRange(t *testing.T) {
+	generator := service.NewFizzBuzzGenerator()
+	query := entity.FizzBuzzQuery{
+		FirstDivisor:  3,
+		SecondDivisor: 5,
+		UpperLimit:    15,
+		FirstString:   "fizz",
+		SecondString:  "buzz",
+	}
+
+	t.Run("matches the same part of Generate", func(t *testing.T) {
+		expected := generator.Generate(query)[9:15]
+		result := generator.GenerateRange(query, 10, 15)
+
+		if strings.Join(result, ",") != strings.Join(expected, ",") {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("computes numbers far into the sequence on their own", func(t *testing.T) {
+		huge := query
+		huge.UpperLimit = 1 << 40
+
+		result := generator.GenerateRange(huge, 1<<40-1, 1<<40)
+		if len(result) != 2 || result[0] != "fizzbuzz" || result[1] != "1099511627776" {
+			t.Errorf("unexpected values %v", result)
+		}
+	})
+}
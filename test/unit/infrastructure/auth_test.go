@@ -0,0 +1,109 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+)
+
+func TestLoadAPIKeys(t *testing.T) {
+	ciHash := auth.HashKey("ci-secret")
+
+	t.Run("yaml", func(t *testing.T) {
+		path := writeConfigFile(t, "keys.yaml", `
+keys:
+  - id: ci
+    hash: `+ciHash+`
+    scopes: [fizzbuzz:generate, stats:read]
+  - id: ops
+    hash: `+strings.ToUpper(auth.HashKey("ops-secret"))+`
+    scopes: [admin]
+`)
+		keys, err := auth.LoadAPIKeys(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(keys) != 2 || keys[0].ID != "ci" || keys[0].Hash != ciHash ||
+			!slices.Equal(keys[0].Scopes, []auth.Scope{auth.ScopeGenerate, auth.ScopeStatsRead}) {
+			t.Errorf("unexpected keys %+v", keys)
+		}
+		if keys[1].Hash != auth.HashKey("ops-secret") {
+			t.Errorf("hashes should be normalized to lower case, got %q", keys[1].Hash)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path := writeConfigFile(t, "keys.json", `{"keys": [{"id": "ci", "hash": "`+ciHash+`", "scopes": ["stats:read"]}]}`)
+		keys, err := auth.LoadAPIKeys(path)
+		if err != nil || len(keys) != 1 || keys[0].Scopes[0] != auth.ScopeStatsRead {
+			t.Errorf("unexpected keys %+v, %v", keys, err)
+		}
+	})
+
+	t.Run("lists every problem", func(t *testing.T) {
+		path := writeConfigFile(t, "keys.yaml", `
+keys:
+  - hash: `+ciHash+`
+    scopes: [stats:read]
+  - id: ci
+    hash: sha256:abc
+    scopes: [stats:write]
+  - id: ci
+    hash: `+ciHash+`
+`)
+		_, err := auth.LoadAPIKeys(path)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		for _, want := range []string{
+			"key 1: id is required",
+			"key 2 (ci): hash must be sha256:",
+			`key 2 (ci): unknown scope "stats:write"`,
+			"key 3 (ci): duplicate id",
+			"key 3 (ci): duplicate hash",
+			"key 3 (ci): at least one scope is required",
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("missing %q in:\n%v", want, err)
+			}
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := writeConfigFile(t, "keys.yaml", "keys:\n  - id: ci\n    key: plaintext\n")
+		if _, err := auth.LoadAPIKeys(path); err == nil {
+			t.Error("expected unknown fields to be rejected")
+		}
+	})
+}
+
+func TestKeyStore(t *testing.T) {
+	ctx := context.Background()
+	store := auth.NewKeyStore([]auth.APIKey{
+		{ID: "ci", Hash: auth.HashKey("ci-secret"), Scopes: []auth.Scope{auth.ScopeGenerate}},
+	})
+
+	p, err := store.Authenticate(ctx, "ci-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != "ci" || p.Method != auth.MethodAPIKey || !p.HasScope(auth.ScopeGenerate) || p.HasScope(auth.ScopeAdmin) {
+		t.Errorf("unexpected principal %+v", p)
+	}
+
+	if _, err := store.Authenticate(ctx, "guess"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	store.Update([]auth.APIKey{{ID: "ci", Hash: auth.HashKey("rotated"), Scopes: []auth.Scope{auth.ScopeGenerate}}})
+	if _, err := store.Authenticate(ctx, "ci-secret"); err == nil {
+		t.Error("the rotated key should be rejected")
+	}
+	if _, err := store.Authenticate(ctx, "rotated"); err != nil {
+		t.Errorf("the new key should be accepted, got %v", err)
+	}
+}
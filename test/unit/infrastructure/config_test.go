@@ -0,0 +1,350 @@
+package inmemory_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/infrastructure/auth"
+	"fizzbuzz-service/internal/infrastructure/config"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func configProblems(t *testing.T, err error) []string {
+	t.Helper()
+	var cfgErr *config.Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected *config.Error, got %v", err)
+	}
+	return cfgErr.Problems
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != "8080" || cfg.MaxLimit != 10000 || cfg.RequestTimeout != 30*time.Second ||
+		cfg.StatsOverflow != application.OverflowDrop || !slices.Equal(cfg.CORS.AllowedOrigins, []string{"*"}) {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+	if !slices.Contains(cfg.CORS.AllowedHeaders, "X-API-Key") {
+		t.Errorf("expected API keys to be allowed cross-origin, got %v", cfg.CORS.AllowedHeaders)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  port: 9000
+  request_timeout: 10s
+fizzbuzz:
+  max_limit: 500
+  stream_max_limit: 1000
+log:
+  level: debug
+`)
+	t.Setenv("MAX_LIMIT", "600")
+	t.Setenv("STREAM_MAX_LIMIT", "2000")
+	t.Setenv("LOG_LEVEL", "") // empty variables are ignored
+
+	cfg, err := config.Load([]string{"-config", path, "-fizzbuzz.max_limit", "700"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Port != "9000" || cfg.RequestTimeout != 10*time.Second || cfg.LogLevel != "debug" {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.StreamMaxLimit != 2000 {
+		t.Errorf("env should override file, got %d", cfg.StreamMaxLimit)
+	}
+	if cfg.MaxLimit != 700 {
+		t.Errorf("flag should override env, got %d", cfg.MaxLimit)
+	}
+	if cfg.WriteTimeout != 10*time.Second {
+		t.Errorf("unset settings should keep their default, got %s", cfg.WriteTimeout)
+	}
+}
+
+func TestLoad_ConfigFileFormats(t *testing.T) {
+	want := []string{"https://a.example", "https://b.example"}
+	files := map[string]string{
+		"config.yaml": "cors:\n  allowed_origins: [https://a.example, https://b.example]\n  allow_credentials: false\nhealth:\n  queue_max_fill: 0.5\n",
+		"config.yml":  "cors:\n  allowed_origins: https://a.example, https://b.example\n  allow_credentials: false\nhealth:\n  queue_max_fill: 0.5\n",
+		"config.json": `{"cors": {"allowed_origins": ["https://a.example", "https://b.example"], "allow_credentials": false}, "health": {"queue_max_fill": 0.5}}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(config.ConfigFileEnv, writeConfigFile(t, name, content))
+
+			cfg, err := config.Load(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(cfg.CORS.AllowedOrigins, want) || cfg.CORS.AllowCredentials || cfg.ReadyQueueMaxFill != 0.5 {
+				t.Errorf("unexpected CORS %+v, fill %g", cfg.CORS, cfg.ReadyQueueMaxFill)
+			}
+		})
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"server": {"stop_timeout": "soon"}, "statz": {"backend": "memory"}}`)
+	t.Setenv("MAX_LIMIT", "abc")
+	t.Setenv("STATS_OVERFLOW", "ignore")
+
+	_, err := config.Load([]string{"-config", path, "-tracing.sample_ratio", "1.5", "-metrics.path", "metrics"})
+	problems := configProblems(t, err)
+
+	want := []string{
+		"server.stop_timeout (file " + path + ")",
+		"statz.backend (file " + path + "): unknown setting",
+		"fizzbuzz.max_limit (env MAX_LIMIT)",
+		"stats.overflow (env STATS_OVERFLOW)",
+		"metrics.path (flag -metrics.path)",
+		"tracing.sample_ratio (flag -tracing.sample_ratio)",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %q", len(want), problems)
+	}
+	for _, prefix := range want {
+		if !slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, prefix) }) {
+			t.Errorf("missing problem %q in %q", prefix, problems)
+		}
+	}
+	if !strings.Contains(err.Error(), "abc") {
+		t.Errorf("error should show the invalid value: %v", err)
+	}
+}
+
+func TestLoad_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		problem string
+	}{
+		{"negative duration", []string{"-server.read_timeout", "-1s"}, "server.read_timeout"},
+		{"zero limit", []string{"-fizzbuzz.max_limit", "0"}, "fizzbuzz.max_limit"},
+		{"unknown backend", []string{"-stats.backend", "redis"}, "stats.backend"},
+		{"invalid port", []string{"-server.port", "http"}, "server.port"},
+		{"invalid bool", []string{"-cors.allow_credentials", "maybe"}, "cors.allow_credentials"},
+		{"invalid endpoint", []string{"-tracing.otlp_endpoint", "localhost:4318"}, "tracing.otlp_endpoint"},
+		{"admin port clash", []string{"-server.port", "9000", "-metrics.admin_port", "9000"}, "metrics.admin_port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Load(tt.args)
+			problems := configProblems(t, err)
+			if len(problems) != 1 || !strings.HasPrefix(problems[0], tt.problem) {
+				t.Errorf("expected one problem on %s, got %q", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestLoad_FileErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		_, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+		configProblems(t, err)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := config.Load([]string{"-config", writeConfigFile(t, "config.toml", "")})
+		if problems := configProblems(t, err); !strings.Contains(problems[0], "unsupported extension") {
+			t.Errorf("unexpected problems %q", problems)
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		_, err := config.Load([]string{"-config", writeConfigFile(t, "config.json", "{")})
+		configProblems(t, err)
+	})
+}
+
+func TestLoad_Help(t *testing.T) {
+	if _, err := config.Load([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "server:\n  port: 9000\nfizzbuzz:\n  max_limit: 100\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloader := config.NewReloader(args, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var applied []*config.Config
+	reloader.OnReload(func(c *config.Config) { applied = append(applied, c) })
+
+	rewrite := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		changes, err := reloader.Reload()
+		if err != nil || len(changes) != 0 || len(applied) != 0 {
+			t.Errorf("expected no change, got %v, %v, %d hooks", changes, err, len(applied))
+		}
+	})
+
+	t.Run("applies reloadable settings only", func(t *testing.T) {
+		rewrite("server:\n  port: 9001\nfizzbuzz:\n  max_limit: 200\ncors:\n  allowed_origins: [https://app.example]\n")
+
+		changes, err := reloader.Reload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []config.Change{
+			{Setting: "server.port", Old: "9000", New: "9001", Applied: false},
+			{Setting: "fizzbuzz.max_limit", Old: "100", New: "200", Applied: true},
+			{Setting: "cors.allowed_origins", Old: "*", New: "https://app.example", Applied: true},
+		}
+		if !slices.Equal(changes, want) {
+			t.Errorf("expected changes %+v, got %+v", want, changes)
+		}
+
+		if len(applied) != 1 {
+			t.Fatalf("expected one hook call, got %d", len(applied))
+		}
+		current := reloader.Current()
+		if applied[0] != current || current.MaxLimit != 200 || !slices.Equal(current.CORS.AllowedOrigins, []string{"https://app.example"}) {
+			t.Errorf("reloadable settings not applied: %+v", current)
+		}
+		if current.Port != "9000" {
+			t.Errorf("port needs a restart, expected 9000, got %s", current.Port)
+		}
+		if cfg.MaxLimit != 100 {
+			t.Errorf("the initial configuration must not be modified, got %d", cfg.MaxLimit)
+		}
+	})
+
+	t.Run("rejects invalid configuration", func(t *testing.T) {
+		rewrite("fizzbuzz:\n  max_limit: 300\nlog:\n  level: loud\n")
+
+		_, err := reloader.Reload()
+		if problems := configProblems(t, err); len(problems) != 1 || !strings.HasPrefix(problems[0], "log.level") {
+			t.Errorf("unexpected problems %q", problems)
+		}
+		if len(applied) != 1 || reloader.Current().MaxLimit != 200 {
+			t.Errorf("rejected reload must keep the current configuration, max limit %d", reloader.Current().MaxLimit)
+		}
+	})
+}
+
+func TestLoad_APIKeys(t *testing.T) {
+	keys := writeConfigFile(t, "keys.yaml", "keys:\n  - id: ci\n    hash: "+auth.HashKey("ci-secret")+"\n    scopes: [stats:read]\n")
+	args := []string{"-auth.api_keys_file", keys}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0].ID != "ci" {
+		t.Fatalf("unexpected keys %+v", cfg.APIKeys)
+	}
+
+	t.Run("invalid keys are configuration problems", func(t *testing.T) {
+		bad := writeConfigFile(t, "keys.yaml", "keys:\n  - id: ci\n    hash: plain\n    scopes: [root]\n")
+		_, err := config.Load([]string{"-auth.api_keys_file", bad, "-fizzbuzz.max_limit", "0"})
+		if problems := configProblems(t, err); len(problems) != 3 {
+			t.Errorf("expected 3 problems, got %q", problems)
+		}
+	})
+
+	t.Run("rotated on reload", func(t *testing.T) {
+		reloader := config.NewReloader(args, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if err := os.WriteFile(keys, []byte("keys:\n  - id: ci\n    hash: "+auth.HashKey("rotated")+"\n    scopes: [stats:read]\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		changes, err := reloader.Reload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(changes) != 1 || changes[0].Setting != "auth.api_keys" || !changes[0].Applied || changes[0].Old == changes[0].New {
+			t.Fatalf("expected the rotated key to be reported, got %+v", changes)
+		}
+		if strings.Contains(changes[0].New, auth.HashKey("rotated")) {
+			t.Errorf("changes must not reveal key hashes: %q", changes[0].New)
+		}
+		if reloader.Current().APIKeys[0].Hash != auth.HashKey("rotated") {
+			t.Errorf("rotated key not applied")
+		}
+	})
+}
+
+func TestLoad_JWKS(t *testing.T) {
+	secret := base64.RawURLEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	jwks := writeConfigFile(t, "jwks.json", `{"keys": [{"kty": "oct", "kid": "gw-1", "k": "`+secret+`"}]}`)
+	args := []string{"-auth.jwks_file", jwks, "-auth.jwt_issuer", "https://gateway", "-auth.jwt_audience", "fizzbuzz"}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cfg.JWKS.Lookup("gw-1"); !ok || cfg.JWTLeeway != 30*time.Second {
+		t.Fatalf("unexpected JWT configuration %s, leeway %s", cfg.JWKS, cfg.JWTLeeway)
+	}
+
+	t.Run("issuer and audience are required", func(t *testing.T) {
+		_, err := config.Load([]string{"-auth.jwks_file", jwks})
+		problems := configProblems(t, err)
+		if len(problems) != 2 || !strings.HasPrefix(problems[0], "auth.jwt_issuer") || !strings.HasPrefix(problems[1], "auth.jwt_audience") {
+			t.Errorf("unexpected problems %q", problems)
+		}
+	})
+
+	t.Run("invalid keys are configuration problems", func(t *testing.T) {
+		bad := writeConfigFile(t, "jwks.json", `{"keys": [{"kty": "oct", "k": "c2hvcnQ"}, {"kty": "RSA"}]}`)
+		_, err := config.Load(append(slices.Clone(args), "-auth.jwks_file", bad))
+		problems := configProblems(t, err)
+		if len(problems) < 2 || !strings.HasPrefix(problems[0], "auth.jwks_file: key 1") {
+			t.Errorf("unexpected problems %q", problems)
+		}
+	})
+
+	t.Run("rotated on reload", func(t *testing.T) {
+		reloader := config.NewReloader(args, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		rotated := base64.RawURLEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+		if err := os.WriteFile(jwks, []byte(`{"keys": [{"kty": "oct", "kid": "gw-1", "k": "`+rotated+`"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		changes, err := reloader.Reload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(changes) != 1 || changes[0].Setting != "auth.jwks" || !changes[0].Applied || changes[0].Old == changes[0].New {
+			t.Fatalf("expected the rotated key to be reported, got %+v", changes)
+		}
+		if strings.Contains(changes[0].New, rotated) {
+			t.Errorf("changes must not reveal keys: %q", changes[0].New)
+		}
+		if key, _ := reloader.Current().JWKS.Lookup("gw-1"); string(key.Key.([]byte)) != "fedcba9876543210fedcba9876543210" {
+			t.Errorf("rotated key not applied")
+		}
+	})
+}
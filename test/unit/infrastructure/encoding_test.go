@@ -0,0 +1,101 @@
+package inmemory_test
+
+import (
+	"bytes"
+	"testing"
+
+	"fizzbuzz-service/internal/infrastructure/http/encoding"
+)
+
+func TestRegistry_Negotiate(t *testing.T) {
+	registry := encoding.DefaultRegistry()
+
+	tests := []struct {
+		name      string
+		accept    string
+		expected  string
+		expectErr bool
+	}{
+		{name: "missing header uses default", accept: "", expected: "application/json"},
+		{name: "wildcard uses default", accept: "*/*", expected: "application/json"},
+		{name: "exact match", accept: "text/csv", expected: "text/csv"},
+		{name: "parameters are ignored", accept: "text/plain; charset=utf-8", expected: "text/plain"},
+		{name: "highest quality wins", accept: "application/json;q=0.5, application/x-ndjson", expected: "application/x-ndjson"},
+		{name: "subtype wildcard", accept: "text/*", expected: "text/csv"},
+		{name: "specific range overrides wildcard", accept: "text/*;q=0.9, text/csv;q=0", expected: "text/plain"},
+		{name: "browser style header", accept: "text/html,application/xhtml+xml,*/*;q=0.8", expected: "application/json"},
+		{name: "unsupported type", accept: "application/xml", expectErr: true},
+		{name: "explicitly refused", accept: "*/*;q=0", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, ok := registry.Negotiate(tt.accept)
+
+			if tt.expectErr {
+				if ok {
+					t.Errorf("expected no encoder, got %s", enc.MediaType())
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("expected %s, got none", tt.expected)
+			}
+			if enc.MediaType() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, enc.MediaType())
+			}
+		})
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	seq := func(yield func(int, string) bool) {
+		for i, v := range []string{"1", "fi,zz", `say "hi"`} {
+			if !yield(i+1, v) {
+				return
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		encoder  encoding.Encoder
+		expected string
+	}{
+		{
+			name:     "json",
+			encoder:  encoding.JSON{},
+			expected: `{"result":["1","fi,zz","say \"hi\""]}` + "\n",
+		},
+		{
+			name:     "ndjson",
+			encoder:  encoding.NDJSON{},
+			expected: "\"1\"\n\"fi,zz\"\n\"say \\\"hi\\\"\"\n",
+		},
+		{
+			name:     "csv",
+			encoder:  encoding.CSV{},
+			expected: "index,value\r\n1,1\r\n2,\"fi,zz\"\r\n3,\"say \"\"hi\"\"\"\r\n",
+		},
+		{
+			name:     "text",
+			encoder:  encoding.Text{},
+			expected: "1\nfi,zz\nsay \"hi\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := encoding.Encode(&buf, tt.encoder, seq, 0, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/persistence/file"
+)
+
+func openFileRepository(t *testing.T, dir string, opts ...file.Option) *file.StatisticsRepository {
+	t.Helper()
+
+	config := file.DefaultConfig(dir)
+	config.FlushInterval = time.Hour
+	config.SnapshotInterval = time.Hour
+
+	repo, err := file.Open(config, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	return repo
+}
+
+func TestFileStatisticsRepository_Recovery(t *testing.T) {
+	ctx := context.Background()
+
+	classic := entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+	rules := entity.FizzBuzzQuery{
+		UpperLimit: 105,
+		Rules: []entity.Rule{
+			{Divisor: 3, Word: "fizz"},
+			{Divisor: 5, Word: "buzz"},
+			{Divisor: 7, Word: "bazz"},
+		},
+	}
+
+	t.Run("state survives a graceful restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo := openFileRepository(t, dir)
+		for i := 0; i < 3; i++ {
+			repo.UpdateStats(ctx, classic, entity.Caller{})
+		}
+		repo.UpdateStats(ctx, rules, entity.Caller{})
+		if err := repo.Close(ctx); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+
+		reopened := openFileRepository(t, dir)
+		defer reopened.Close(ctx)
+
+		stats, _ := reopened.GetMostFrequent(ctx)
+		if stats.HitCount != 3 || stats.MostFrequentQuery.Limit != 15 {
+			t.Errorf("expected classic query with 3 hits, got %+v", stats)
+		}
+
+		entries, _ := reopened.GetTop(ctx, entity.TopQuery{Limit: 10})
+		if len(entries) != 2 || len(entries[1].Query.Rules) != 3 {
+			t.Errorf("expected the rules query to be restored, got %+v", entries)
+		}
+	})
+
+	t.Run("flushed hits survive a crash", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo := openFileRepository(t, dir)
+		for i := 0; i < 5; i++ {
+			repo.UpdateStats(ctx, classic, entity.Caller{})
+		}
+		if err := repo.Flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+		// No Close: simulates the process being killed
+
+		reopened := openFileRepository(t, dir)
+		defer reopened.Close(ctx)
+
+		stats, _ := reopened.GetMostFrequent(ctx)
+		if stats.HitCount != 5 {
+			t.Errorf("expected 5 hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("snapshot and log are not counted twice", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo := openFileRepository(t, dir)
+		for i := 0; i < 4; i++ {
+			repo.UpdateStats(ctx, classic, entity.Caller{})
+		}
+
+		// Keep a copy of the log as it was before compaction, as if the
+		// process died between writing the snapshot and truncating the log
+		repo.Flush()
+		logBefore, _ := os.ReadFile(filepath.Join(dir, "hits.log"))
+
+		if err := repo.Compact(); err != nil {
+			t.Fatalf("compact failed: %v", err)
+		}
+		repo.UpdateStats(ctx, classic, entity.Caller{})
+		repo.Flush()
+
+		logAfter, _ := os.ReadFile(filepath.Join(dir, "hits.log"))
+		os.WriteFile(filepath.Join(dir, "hits.log"), append(logBefore, logAfter...), 0o644)
+
+		reopened := openFileRepository(t, dir)
+		defer reopened.Close(ctx)
+
+		stats, _ := reopened.GetMostFrequent(ctx)
+		if stats.HitCount != 5 {
+			t.Errorf("expected 5 hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("client statistics survive snapshot and log replay", func(t *testing.T) {
+		dir := t.TempDir()
+		alice := entity.Caller{ID: "api_key:alice"}
+
+		repo := openFileRepository(t, dir)
+		repo.UpdateStats(ctx, classic, alice)
+		repo.UpdateStats(ctx, classic, alice)
+		if err := repo.Compact(); err != nil {
+			t.Fatalf("compact failed: %v", err)
+		}
+		repo.UpdateStats(ctx, rules, alice)
+		repo.UpdateStats(ctx, rules, entity.Caller{ID: "ip:192.0.2.1"})
+		if err := repo.Close(ctx); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+
+		reopened := openFileRepository(t, dir)
+		defer reopened.Close(ctx)
+
+		clients, _ := reopened.GetClients(ctx, entity.ClientQuery{Limit: 10})
+		if len(clients) != 2 || clients[0].Client != alice.ID || clients[0].HitCount != 3 {
+			t.Fatalf("expected alice first with 3 hits, got %+v", clients)
+		}
+		if clients[0].MostFrequentQuery.Limit != 15 || clients[0].MostFrequentHits != 2 {
+			t.Errorf("expected alice's most frequent request to be restored, got %+v", clients[0])
+		}
+	})
+
+	t.Run("torn last record is discarded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo := openFileRepository(t, dir)
+		repo.UpdateStats(ctx, classic, entity.Caller{})
+		repo.UpdateStats(ctx, classic, entity.Caller{})
+		repo.Flush()
+
+		f, _ := os.OpenFile(filepath.Join(dir, "hits.log"), os.O_WRONLY|os.O_APPEND, 0)
+		f.WriteString(`{"seq":3,"query":{"FirstDiv`)
+		f.Close()
+
+		reopened := openFileRepository(t, dir)
+		reopened.UpdateStats(ctx, classic, entity.Caller{})
+		if err := reopened.Close(ctx); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+
+		final := openFileRepository(t, dir)
+		defer final.Close(ctx)
+
+		stats, _ := final.GetMostFrequent(ctx)
+		if stats.HitCount != 3 {
+			t.Errorf("expected 3 hits, got %d", stats.HitCount)
+		}
+	})
+
+	t.Run("time windows survive a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+		repo := openFileRepository(t, dir, file.WithClock(clock))
+		repo.UpdateStats(ctx, rules, entity.Caller{})
+		repo.Compact()
+		clock.Advance(2 * time.Hour)
+		repo.UpdateStats(ctx, classic, entity.Caller{})
+		repo.Flush()
+
+		reopened := openFileRepository(t, dir, file.WithClock(clock))
+		defer reopened.Close(ctx)
+
+		lastHour, _ := reopened.GetTop(ctx, entity.TopQuery{Limit: 10, Window: time.Hour})
+		lastDay, _ := reopened.GetTop(ctx, entity.TopQuery{Limit: 10, Window: 24 * time.Hour})
+
+		if len(lastHour) != 1 || lastHour[0].Query.Limit != 15 {
+			t.Errorf("expected only the classic query in the last hour, got %+v", lastHour)
+		}
+		if len(lastDay) != 2 {
+			t.Errorf("expected both queries in the last day, got %d", len(lastDay))
+		}
+	})
+
+	t.Run("snapshots of earlier versions keep their time windows", func(t *testing.T) {
+		dir := t.TempDir()
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+
+		// Recent hits were keyed by the unquoted two-rule key of the time
+		legacy := `{"seq":2,"state":{
+			"entries":[{"query":{"FirstDivisor":3,"SecondDivisor":5,"UpperLimit":15,"FirstString":"fizz","SecondString":"buzz"},
+				"hits":2,"last_hit_at":"2025-01-01T12:00:00Z"}],
+			"recent":[{"resolution":60000000000,"start":"2025-01-01T12:00:00Z","counts":{"3:5:15:fizz:buzz":2}}]}}`
+		if err := os.WriteFile(filepath.Join(dir, "snapshot.json"), []byte(legacy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		repo := openFileRepository(t, dir, file.WithClock(clock))
+		defer repo.Close(ctx)
+
+		lastHour, err := repo.GetTop(ctx, entity.TopQuery{Limit: 10, Window: time.Hour})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(lastHour) != 1 || lastHour[0].HitCount != 2 || lastHour[0].Key != `3:5:15:"fizz":"buzz"` {
+			t.Errorf("expected the 2 recent hits under the quoted key, got %+v", lastHour)
+		}
+		if stats, _ := repo.GetMostFrequent(ctx); stats.HitCount != 2 {
+			t.Errorf("expected the all-time hits to be restored, got %+v", stats)
+		}
+	})
+
+	t.Run("close compacts even when its context ended", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo := openFileRepository(t, dir)
+		for i := 0; i < 2; i++ {
+			repo.UpdateStats(ctx, classic, entity.Caller{})
+		}
+		expired, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := repo.Close(expired); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the context error, got %v", err)
+		}
+		if err := repo.Flush(); err != file.ErrClosed {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, "hits.log"))
+		if err != nil || info.Size() != 0 {
+			t.Fatalf("expected a compacted log, got %v", err)
+		}
+		reopened := openFileRepository(t, dir)
+		defer reopened.Close(ctx)
+		if stats, _ := reopened.GetMostFrequent(ctx); stats.HitCount != 2 {
+			t.Errorf("expected 2 hits, got %+v", stats)
+		}
+	})
+
+	t.Run("updates fail after close", func(t *testing.T) {
+		repo := openFileRepository(t, t.TempDir())
+		repo.Close(ctx)
+
+		if err := repo.UpdateStats(ctx, classic, entity.Caller{}); err != file.ErrClosed {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	})
+}
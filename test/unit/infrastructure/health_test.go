@@ -0,0 +1,143 @@
+package inmemory_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/infrastructure/health"
+	"fizzbuzz-service/internal/infrastructure/persistence/file"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/persistence/sqlite"
+)
+
+type fixedQueueStats application.DispatcherStats
+
+func (f fixedQueueStats) Stats() application.DispatcherStats { return application.DispatcherStats(f) }
+
+func TestReadiness(t *testing.T) {
+	ctx := context.Background()
+	passing := health.CheckerFunc(func(context.Context) error { return nil })
+	failing := health.CheckerFunc(func(context.Context) error { return errors.New("unreachable") })
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("ready without checkers", func(t *testing.T) {
+		report := health.NewReadiness(0, discard).Check(ctx)
+		if !report.Ready() || len(report.Checks) != 0 {
+			t.Errorf("expected ready without checks, got %+v", report)
+		}
+	})
+
+	t.Run("not ready when any check fails, with each result", func(t *testing.T) {
+		var logs bytes.Buffer
+		r := health.NewReadiness(0, slog.New(slog.NewTextHandler(&logs, nil)))
+		r.Register("db", failing)
+		r.Register("queue", passing)
+
+		report := r.Check(ctx)
+		if report.Status != health.StatusNotReady {
+			t.Errorf("expected %s, got %s", health.StatusNotReady, report.Status)
+		}
+		if db := report.Checks["db"]; db.Status != health.CheckFailing {
+			t.Errorf("unexpected db result %+v", db)
+		}
+		if queue := report.Checks["queue"]; queue.Status != health.CheckOK {
+			t.Errorf("unexpected queue result %+v", queue)
+		}
+		if out := logs.String(); !strings.Contains(out, "check=db") || !strings.Contains(out, "unreachable") {
+			t.Errorf("expected the failure of db to be logged, got %q", out)
+		}
+	})
+
+	t.Run("slow checks time out", func(t *testing.T) {
+		r := health.NewReadiness(10*time.Millisecond, discard)
+		r.Register("slow", health.CheckerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		report := r.Check(ctx)
+		if report.Ready() || report.Checks["slow"].Status != health.CheckFailing {
+			t.Errorf("expected a timed out check, got %+v", report)
+		}
+	})
+
+	t.Run("draining is never ready", func(t *testing.T) {
+		r := health.NewReadiness(0, discard)
+		r.Register("db", passing)
+		r.Drain()
+
+		if report := r.Check(ctx); report.Status != health.StatusDraining {
+			t.Errorf("expected %s, got %+v", health.StatusDraining, report)
+		}
+	})
+}
+
+func TestHealthCheckers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queue checker fails once the queue is nearly full", func(t *testing.T) {
+		cases := []struct {
+			depth   int
+			healthy bool
+		}{
+			{0, true},
+			{89, true},
+			{90, false},
+			{100, false},
+		}
+		for _, c := range cases {
+			checker := health.QueueChecker(fixedQueueStats{QueueDepth: c.depth, QueueCapacity: 100}, 0.9)
+			if err := checker.Check(ctx); (err == nil) != c.healthy {
+				t.Errorf("depth %d: expected healthy=%v, got %v", c.depth, c.healthy, err)
+			}
+		}
+	})
+
+	t.Run("statistics checker pings the in-memory stores", func(t *testing.T) {
+		for _, repo := range []health.Pinger{inmemory.NewStatisticsRepository(), inmemory.NewShardedStatisticsRepository(4)} {
+			if err := health.StatisticsChecker(repo).Check(ctx); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("statistics checker pings the file store", func(t *testing.T) {
+		repo, err := file.Open(file.DefaultConfig(t.TempDir()), slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if err != nil {
+			t.Fatalf("failed to open repository: %v", err)
+		}
+		checker := health.StatisticsChecker(repo)
+		if err := checker.Check(ctx); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		repo.Close(ctx)
+		if err := checker.Check(ctx); !errors.Is(err, file.ErrClosed) {
+			t.Errorf("expected ErrClosed once closed, got %v", err)
+		}
+	})
+
+	t.Run("statistics checker pings the sqlite store", func(t *testing.T) {
+		repo, err := sqlite.Open(filepath.Join(t.TempDir(), "statistics.db"))
+		if err != nil {
+			t.Fatalf("failed to open repository: %v", err)
+		}
+		checker := health.StatisticsChecker(repo)
+		if err := checker.Check(ctx); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		repo.Close(ctx)
+		if err := checker.Check(ctx); err == nil {
+			t.Error("expected an error once closed")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package inmemory_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys are signing keys with their public JWK
+type testKeys struct {
+	hmac []byte
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{hmac: []byte("0123456789abcdef0123456789abcdef"), rsa: rsaKey, ec: ecKey}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwks renders the public keys as a JSON Web Key Set
+func (k testKeys) jwks(t *testing.T) []byte {
+	t.Helper()
+	ecBytes, err := k.ec.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hs", "alg": "HS256", "k": b64(k.hmac)},
+		{"kty": "RSA", "kid": "rs", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "es", "crv": "P-256", "x": b64(ecBytes[1:33]), "y": b64(ecBytes[33:])},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// sign creates a token signed with the key of kid
+func (k testKeys) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	var token *jwt.Token
+	var key any
+	switch kid {
+	case "hs":
+		token, key = jwt.NewWithClaims(jwt.SigningMethodHS256, claims), k.hmac
+	case "rs":
+		token, key = jwt.NewWithClaims(jwt.SigningMethodRS256, claims), k.rsa
+	case "es":
+		token, key = jwt.NewWithClaims(jwt.SigningMethodES256, claims), k.ec
+	}
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+
+	t.Run("supported key types", func(t *testing.T) {
+		set, err := auth.ParseJWKS(keys.jwks(t))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var algs []string
+		for _, k := range set.Keys() {
+			algs = append(algs, k.ID+":"+k.Algorithm)
+		}
+		if !slices.Equal(algs, []string{"hs:HS256", "rs:RS256", "es:ES256"}) {
+			t.Errorf("unexpected keys %v", algs)
+		}
+		if strings.Contains(set.String(), b64(keys.hmac)) {
+			t.Errorf("description must not reveal secrets: %s", set)
+		}
+	})
+
+	t.Run("lists every problem", func(t *testing.T) {
+		_, err := auth.ParseJWKS([]byte(`{"keys": [
+			{"kty": "oct", "k": "c2hvcnQ"},
+			{"kty": "oct", "kid": "enc", "use": "enc", "k": "` + b64(keys.hmac) + `"},
+			{"kty": "RSA", "kid": "private", "n": "AQAB", "e": "AQAB", "d": "AQAB"},
+			{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AA", "y": "AA"},
+			{"kty": "oct", "kid": "mismatch", "alg": "RS256", "k": "` + b64(keys.hmac) + `"},
+			{"kty": "OKP", "kid": "mismatch"}
+		]}`))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		for _, want := range []string{
+			"key 1: kid is required",
+			"key 1: HS256 secret must be at least 32 bytes",
+			`key 2 (enc): use "enc" is not sig`,
+			"key 3 (private): private key found",
+			`key 4 (p384): curve "P-384" is not supported`,
+			`key 5 (mismatch): alg "RS256" does not match`,
+			"key 6 (mismatch): duplicate kid",
+			`key 6 (mismatch): key type "OKP" is not supported`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("missing %q in:\n%v", want, err)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := auth.ParseJWKS([]byte(`{"keys": []}`)); err == nil {
+			t.Error("expected an empty set to be rejected")
+		}
+	})
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeys(t)
+	set, err := auth.ParseJWKS(keys.jwks(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cfg := auth.JWTConfig{Issuer: "https://gateway", Audience: "fizzbuzz", Leeway: 30 * time.Second}
+	authn := auth.NewJWTAuthenticator(set, cfg, auth.WithJWTClock(clk))
+
+	now := clk.Now().Unix()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":   "client-1",
+			"iss":   "https://gateway",
+			"aud":   []string{"other", "fizzbuzz"},
+			"exp":   now + 60,
+			"nbf":   now - 60,
+			"scope": "fizzbuzz:generate openid",
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	for _, kid := range []string{"hs", "rs", "es"} {
+		t.Run("valid "+kid, func(t *testing.T) {
+			p, err := authn.Authenticate(ctx, keys.sign(t, kid, claims(nil)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.ID != "client-1" || p.Method != auth.MethodJWT || !slices.Equal(p.Scopes, []auth.Scope{auth.ScopeGenerate}) {
+				t.Errorf("unexpected principal %+v", p)
+			}
+		})
+	}
+
+	t.Run("scp claim", func(t *testing.T) {
+		p, err := authn.Authenticate(ctx, keys.sign(t, "es", claims(jwt.MapClaims{"scope": nil, "scp": []string{"stats:read", "admin"}})))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(p.Scopes, []auth.Scope{auth.ScopeStatsRead, auth.ScopeAdmin}) {
+			t.Errorf("unexpected scopes %v", p.Scopes)
+		}
+	})
+
+	t.Run("within leeway", func(t *testing.T) {
+		if _, err := authn.Authenticate(ctx, keys.sign(t, "hs", claims(jwt.MapClaims{"exp": now - 10, "nbf": now + 10}))); err != nil {
+			t.Errorf("expected clock skew to be tolerated, got %v", err)
+		}
+	})
+
+	tampered := keys.sign(t, "rs", claims(nil))
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+	// An HS256 token signed with the public RSA key must not be accepted
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil))
+	confused.Header["kid"] = "rs"
+	confusedToken, _ := confused.SignedString(keys.rsa.N.Bytes())
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims(nil)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"expired", keys.sign(t, "hs", claims(jwt.MapClaims{"exp": now - 60})), "token is expired"},
+		{"not yet valid", keys.sign(t, "hs", claims(jwt.MapClaims{"nbf": now + 60})), "token is not valid yet"},
+		{"no expiration", keys.sign(t, "hs", claims(jwt.MapClaims{"exp": nil})), "missing a required claim"},
+		{"wrong issuer", keys.sign(t, "hs", claims(jwt.MapClaims{"iss": "https://elsewhere"})), "issuer is not accepted"},
+		{"wrong audience", keys.sign(t, "hs", claims(jwt.MapClaims{"aud": "other"})), "audience is not accepted"},
+		{"no subject", keys.sign(t, "hs", claims(jwt.MapClaims{"sub": nil})), "token has no subject"},
+		{"tampered", tampered, "invalid signature"},
+		{"algorithm confusion", confusedToken, "signing algorithm does not match the key"},
+		{"unsigned", unsigned, "invalid signature"},
+		{"malformed", "not.a.token", "malformed token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authn.Authenticate(ctx, tt.token)
+			if !errors.Is(err, auth.ErrInvalidCredentials) {
+				t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("unknown key", func(t *testing.T) {
+		other := newTestKeys(t)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims(nil))
+		token.Header["kid"] = "retired"
+		signed, _ := token.SignedString(other.ec)
+		if _, err := authn.Authenticate(ctx, signed); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+			t.Errorf("expected an unknown key error, got %v", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		rotated := newTestKeys(t)
+		rotatedSet, err := auth.ParseJWKS(rotated.jwks(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		authn := auth.NewJWTAuthenticator(set, cfg, auth.WithJWTClock(clk))
+		authn.Update(rotatedSet, auth.JWTConfig{Issuer: "https://gateway", Audience: "reports"})
+
+		if _, err := authn.Authenticate(ctx, keys.sign(t, "es", claims(nil))); err == nil {
+			t.Error("tokens of the retired keys should be rejected")
+		}
+		if _, err := authn.Authenticate(ctx, rotated.sign(t, "es", claims(jwt.MapClaims{"aud": "reports"}))); err != nil {
+			t.Errorf("tokens of the new keys should be accepted, got %v", err)
+		}
+	})
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeys(t)
+	set, err := auth.ParseJWKS(keys.jwks(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := &fakeClock{now: time.Now()}
+	jwtAuthn := auth.NewJWTAuthenticator(set, auth.JWTConfig{Issuer: "gw", Audience: "fb"}, auth.WithJWTClock(clk))
+	store := auth.NewKeyStore([]auth.APIKey{{ID: "ci", Hash: auth.HashKey("ci-secret"), Scopes: []auth.Scope{auth.ScopeAdmin}}})
+	token := keys.sign(t, "hs", jwt.MapClaims{"sub": "gw-client", "iss": "gw", "aud": "fb", "exp": clk.Now().Add(time.Minute).Unix()})
+
+	both := auth.Dispatcher{APIKeys: store, JWT: jwtAuthn}
+	if p, err := both.Authenticate(ctx, token); err != nil || p.Method != auth.MethodJWT {
+		t.Errorf("expected the JWT to be verified, got %+v, %v", p, err)
+	}
+	if p, err := both.Authenticate(ctx, "ci-secret"); err != nil || p.Method != auth.MethodAPIKey {
+		t.Errorf("expected the API key to be verified, got %+v, %v", p, err)
+	}
+
+	if _, err := (auth.Dispatcher{APIKeys: store}).Authenticate(ctx, token); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("JWTs should be rejected when disabled, got %v", err)
+	}
+	if _, err := (auth.Dispatcher{JWT: jwtAuthn}).Authenticate(ctx, "ci-secret"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("API keys should be rejected when disabled, got %v", err)
+	}
+}
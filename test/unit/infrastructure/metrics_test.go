@@ -0,0 +1,128 @@
+package inmemory_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/infrastructure/metrics"
+)
+
+func scrape(reg *metrics.Registry) string {
+	var b bytes.Buffer
+	reg.Write(&b)
+	return b.String()
+}
+
+func TestMetricsRegistry(t *testing.T) {
+	t.Run("counters are exposed per label combination", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		requests := reg.NewCounterVec("requests_total", "Requests.", "route", "status")
+
+		requests.With("/b", "200").Inc()
+		requests.With("/a", "200").Add(2)
+		requests.With("/b", "200").Inc()
+
+		expected := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 2
+requests_total{route="/b",status="200"} 2
+`
+		if got := scrape(reg); got != expected {
+			t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+		}
+	})
+
+	t.Run("histograms have cumulative buckets", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		h := reg.NewHistogram("length", "Lengths.", []float64{10, 100})
+
+		for _, v := range []float64{5, 10, 50, 500} {
+			h.Observe(v)
+		}
+
+		expected := `# HELP length Lengths.
+# TYPE length histogram
+length_bucket{le="10"} 2
+length_bucket{le="100"} 3
+length_bucket{le="+Inf"} 4
+length_sum 565
+length_count 4
+`
+		if got := scrape(reg); got != expected {
+			t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+		}
+	})
+
+	t.Run("label values and help are escaped", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		reg.NewGaugeVec("g", "Line one\nline two.", "name").With(`say "hi"\`).Set(1.5)
+
+		got := scrape(reg)
+		if !strings.Contains(got, `# HELP g Line one\nline two.`) {
+			t.Errorf("help not escaped:\n%s", got)
+		}
+		if !strings.Contains(got, `g{name="say \"hi\"\\"} 1.5`) {
+			t.Errorf("label not escaped:\n%s", got)
+		}
+	})
+
+	t.Run("function metrics are read on scrape", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		depth := 3.0
+		reg.NewGaugeFunc("queue_depth", "Depth.", func() float64 { return depth })
+
+		depth = 7
+		if got := scrape(reg); !strings.Contains(got, "queue_depth 7\n") {
+			t.Errorf("expected the current value, got:\n%s", got)
+		}
+	})
+
+	t.Run("runtime metrics are exposed", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		reg.RegisterRuntimeMetrics()
+
+		got := scrape(reg)
+		for _, name := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "go_gc_cycles_total ", `go_info{version="go`} {
+			if !strings.Contains(got, name) {
+				t.Errorf("missing %s in:\n%s", name, got)
+			}
+		}
+	})
+
+	t.Run("result cache metrics are exposed", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		cache := application.NewResultCache(1 << 10)
+		reg.RegisterResultCache(cache)
+
+		generate := func() []string { return []string{"1", "2"} }
+		cache.Get("a", generate)
+		cache.Get("a", generate)
+
+		got := scrape(reg)
+		for _, line := range []string{
+			"result_cache_hits_total 1\n",
+			"result_cache_misses_total 1\n",
+			"result_cache_entries 1\n",
+			"result_cache_bytes 59\n",
+			"result_cache_max_bytes 1024\n",
+		} {
+			if !strings.Contains(got, line) {
+				t.Errorf("missing %q in:\n%s", line, got)
+			}
+		}
+	})
+
+	t.Run("registering a name twice panics", func(t *testing.T) {
+		reg := metrics.NewRegistry()
+		reg.NewCounter("c", "C.")
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		reg.NewGauge("c", "C.")
+	})
+}
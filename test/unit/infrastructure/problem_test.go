@@ -0,0 +1,116 @@
+package inmemory_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fizzbuzz-service/internal/domain"
+	"fizzbuzz-service/internal/infrastructure/http/problem"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRegistry_Map(t *testing.T) {
+	registry := problem.DefaultRegistry()
+
+	t.Run("validation errors list their parameters", func(t *testing.T) {
+		err := domain.NewValidationError("invalid parameters",
+			domain.ExceedsMax("limit", 100),
+			domain.MustBePositive("rules[1].divisor"),
+			domain.Invalid("extra", domain.CodeUnknown, `unknown parameter "extra"`),
+			domain.MutuallyExclusive("rules", "int1", "int2", "str1", "str2"),
+		)
+
+		p, ok := registry.Map(fmt.Errorf("generate: %w", err))
+		if !ok {
+			t.Fatal("expected the validation error to be mapped")
+		}
+		if p.Status != http.StatusBadRequest || p.Type != problem.InvalidParameters.URI {
+			t.Errorf("expected an invalid parameters problem, got %+v", p)
+		}
+
+		expected := []string{"limit", "rules[1].divisor", "extra", "rules"}
+		if len(p.InvalidParams) != len(expected) {
+			t.Fatalf("expected %d invalid parameters, got %+v", len(expected), p.InvalidParams)
+		}
+		for i, name := range expected {
+			v := err.Violations[i]
+			if got := p.InvalidParams[i]; got.Name != name || got.Code != v.Code || got.Reason != v.Message {
+				t.Errorf("parameter %d: expected %s %s (%s), got %+v", i, name, v.Code, v.Message, got)
+			}
+		}
+		if max := p.InvalidParams[0].Params["max"]; max != 100 {
+			t.Errorf("expected the max of limit in its params, got %+v", p.InvalidParams[0])
+		}
+		if p.Detail != "limit exceeds maximum allowed value of 100; rules[1].divisor must be greater than 0; "+
+			`unknown parameter "extra"; rules cannot be combined with int1, int2, str1 or str2` {
+			t.Errorf("expected the detail to join the reasons, got %q", p.Detail)
+		}
+	})
+
+	t.Run("not found errors", func(t *testing.T) {
+		p, ok := registry.Map(domain.NotFoundError{Resource: "client ip:192.0.2.1"})
+		if !ok || p.Status != http.StatusNotFound || p.Detail != "client ip:192.0.2.1 not found" {
+			t.Errorf("expected a not found problem, got %+v", p)
+		}
+	})
+
+	t.Run("unknown errors are internal and not disclosed", func(t *testing.T) {
+		p, ok := registry.Map(errors.New("database is locked"))
+		if ok {
+			t.Error("expected unknown errors to be reported as unmapped")
+		}
+		if p.Status != http.StatusInternalServerError || p.Detail != "" {
+			t.Errorf("expected an internal problem without detail, got %+v", p)
+		}
+	})
+
+	t.Run("registered mappers extend the registry", func(t *testing.T) {
+		errTeapot := errors.New("teapot")
+		registry := problem.NewRegistry(func(err error) (problem.Problem, bool) {
+			if !errors.Is(err, errTeapot) {
+				return problem.Problem{}, false
+			}
+			return problem.Type{URI: "/problems/teapot", Title: "Teapot", Status: http.StatusTeapot}.New(""), true
+		})
+
+		if p, ok := registry.Map(errTeapot); !ok || p.Status != http.StatusTeapot {
+			t.Errorf("expected the custom problem, got %+v", p)
+		}
+		if _, ok := registry.Map(domain.NotFoundError{}); ok {
+			t.Error("expected only the registered mappers to apply")
+		}
+	})
+}
+
+func TestWrite(t *testing.T) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.NotAcceptable.New("supported media types: application/json"))
+	})
+	handler = middleware.RequestID(handler)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fizzbuzz?limit=15", nil))
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected 406, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("expected %s, got %q", problem.ContentType, ct)
+	}
+
+	var p problem.Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if p.Type != "/problems/not-acceptable" || p.Title != "Not acceptable" || p.Status != http.StatusNotAcceptable {
+		t.Errorf("unexpected problem %+v", p)
+	}
+	if p.Instance != "/fizzbuzz" || p.RequestID == "" {
+		t.Errorf("expected the request path and ID, got %q and %q", p.Instance, p.RequestID)
+	}
+}
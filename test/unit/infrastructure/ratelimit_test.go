@@ -0,0 +1,121 @@
+package inmemory_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/infrastructure/auth"
+	"fizzbuzz-service/internal/infrastructure/http/middleware"
+)
+
+func TestRateLimiter(t *testing.T) {
+	newLimiter := func(limit middleware.RateLimit) (*middleware.RateLimiter, *fakeClock) {
+		clock := &fakeClock{now: time.Unix(1700000000, 0)}
+		return middleware.NewRateLimiter(limit, middleware.WithRateLimitClock(clock), middleware.WithIdleTTL(time.Minute)), clock
+	}
+
+	t.Run("spends the burst then refills", func(t *testing.T) {
+		limiter, clock := newLimiter(middleware.RateLimit{Rate: 2, Burst: 3})
+
+		for i := range 3 {
+			if d := limiter.Allow("a", 1); !d.Allowed || d.Remaining != 2-i {
+				t.Fatalf("request %d: expected allowed with %d remaining, got %+v", i, 2-i, d)
+			}
+		}
+		d := limiter.Allow("a", 1)
+		if d.Allowed || d.RetryAfter != 500*time.Millisecond || d.Reset != 1500*time.Millisecond {
+			t.Fatalf("expected denial retrying after 500ms, got %+v", d)
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		if d := limiter.Allow("a", 1); !d.Allowed {
+			t.Errorf("expected a refilled token, got %+v", d)
+		}
+	})
+
+	t.Run("clients have separate buckets", func(t *testing.T) {
+		limiter, _ := newLimiter(middleware.RateLimit{Rate: 1, Burst: 1})
+
+		limiter.Allow("a", 1)
+		if limiter.Allow("a", 1).Allowed {
+			t.Error("expected a to be limited")
+		}
+		if !limiter.Allow("b", 1).Allowed {
+			t.Error("b should not pay for a")
+		}
+	})
+
+	t.Run("costs are capped to the burst", func(t *testing.T) {
+		limiter, clock := newLimiter(middleware.RateLimit{Rate: 1, Burst: 5})
+
+		if d := limiter.Allow("a", 3); !d.Allowed || d.Remaining != 2 {
+			t.Fatalf("expected 3 tokens spent, got %+v", d)
+		}
+		if d := limiter.Allow("a", 100); d.Allowed || d.RetryAfter != 3*time.Second {
+			t.Fatalf("expected to wait for a full bucket, got %+v", d)
+		}
+		clock.Advance(3 * time.Second)
+		if d := limiter.Allow("a", 100); !d.Allowed || d.Remaining != 0 {
+			t.Errorf("expected the whole burst spent, got %+v", d)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limiter, _ := newLimiter(middleware.RateLimit{})
+		for range 100 {
+			if !limiter.Allow("a", 1).Allowed {
+				t.Fatal("expected no limit")
+			}
+		}
+		if limiter.Len() != 0 {
+			t.Errorf("disabled limiter should not track clients, got %d", limiter.Len())
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		limiter, _ := newLimiter(middleware.RateLimit{Rate: 1, Burst: 10})
+		limiter.Allow("a", 1)
+
+		limiter.Update(middleware.RateLimit{Rate: 1, Burst: 2})
+		if d := limiter.Allow("a", 1); !d.Allowed || d.Remaining != 1 || d.Limit.Burst != 2 {
+			t.Errorf("expected tokens capped to the new burst, got %+v", d)
+		}
+	})
+
+	t.Run("expires idle buckets", func(t *testing.T) {
+		limiter, clock := newLimiter(middleware.RateLimit{Rate: 1, Burst: 10})
+		limiter.Allow("a", 1)
+		limiter.Allow("b", 1)
+
+		clock.Advance(30 * time.Second)
+		limiter.Allow("b", 1)
+		clock.Advance(45 * time.Second)
+		limiter.Allow("c", 1)
+
+		if limiter.Len() != 2 {
+			t.Errorf("expected the idle bucket of a to expire, got %d buckets", limiter.Len())
+		}
+	})
+}
+
+func TestClientKey(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("X-API-Key", "unverified")
+
+	if got := middleware.ClientKey(r); got != "ip:192.0.2.1" {
+		t.Errorf("expected ip key, got %q", got)
+	}
+
+	apiKey := r.WithContext(auth.NewContext(r.Context(), &auth.Principal{ID: "ci", Method: auth.MethodAPIKey}))
+	if got := middleware.ClientKey(apiKey); got != "api_key:ci" {
+		t.Errorf("expected principal key, got %q", got)
+	}
+
+	// A JWT subject named like an API key does not share its bucket
+	jwt := r.WithContext(auth.NewContext(r.Context(), &auth.Principal{ID: "ci", Method: auth.MethodJWT}))
+	if got := middleware.ClientKey(jwt); got != "jwt:ci" {
+		t.Errorf("expected JWT principal key, got %q", got)
+	}
+}
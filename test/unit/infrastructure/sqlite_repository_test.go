@@ -0,0 +1,134 @@
+package inmemory_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/persistence/sqlite"
+)
+
+func TestSQLiteStatisticsRepository_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "statistics.db")
+
+	query := entity.FizzBuzzQuery{
+		UpperLimit: 105,
+		Rules: []entity.Rule{
+			{Divisor: 3, Word: "fizz"},
+			{Divisor: 5, Word: "buzz"},
+			{Divisor: 7, Word: "bazz"},
+		},
+	}
+
+	repo, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	repo.UpdateStats(ctx, query, entity.Caller{})
+	repo.UpdateStats(ctx, query, entity.Caller{})
+	repo.Close(ctx)
+
+	t.Run("reopening keeps hits and skips applied migrations", func(t *testing.T) {
+		reopened, err := sqlite.Open(path)
+		if err != nil {
+			t.Fatalf("failed to reopen database: %v", err)
+		}
+		defer reopened.Close(ctx)
+
+		stats, _ := reopened.GetMostFrequent(ctx)
+		if stats.HitCount != 2 || len(stats.MostFrequentQuery.Rules) != 3 {
+			t.Errorf("expected the rules query with 2 hits, got %+v", stats)
+		}
+	})
+
+	t.Run("parameters are stored as columns", func(t *testing.T) {
+		db, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		var limit, hits int
+		if err := db.QueryRow(`SELECT upper_limit, hits FROM queries`).Scan(&limit, &hits); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		if limit != 105 || hits != 2 {
+			t.Errorf("expected limit 105 with 2 hits, got %d with %d", limit, hits)
+		}
+
+		var word string
+		if err := db.QueryRow(`SELECT word FROM query_rules WHERE divisor = 7`).Scan(&word); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		if word != "bazz" {
+			t.Errorf("expected word 'bazz', got %q", word)
+		}
+	})
+}
+
+func TestSQLiteStatisticsRepository_Rekey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "statistics.db")
+
+	query := entity.FizzBuzzQuery{FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15, FirstString: "fizz", SecondString: "buzz"}
+	caller := entity.Caller{ID: "ip:192.0.2.1"}
+
+	repo, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	repo.UpdateStats(ctx, query, caller)
+	repo.Close(ctx)
+
+	// Store the hit under the unquoted key of earlier versions, as if the
+	// migrations quoting keys and listing clients had not run yet
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	tx, _ := db.Begin()
+	tx.Exec(`PRAGMA defer_foreign_keys = ON`)
+	for _, table := range []string{"query_rules", "query_hits_per_minute", "client_query_hits"} {
+		if _, err := tx.Exec(`UPDATE ` + table + ` SET query_key = '3:5:15:fizz:buzz'`); err != nil {
+			t.Fatalf("update %s failed: %v", table, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE queries SET key = '3:5:15:fizz:buzz'`); err != nil {
+		t.Fatalf("update queries failed: %v", err)
+	}
+	if _, err := tx.Exec(`DROP TABLE clients`); err != nil {
+		t.Fatalf("drop clients failed: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version >= 3`); err != nil {
+		t.Fatalf("delete migrations failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	db.Close()
+
+	reopened, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer reopened.Close(ctx)
+
+	// New hits land on the rekeyed row instead of a second one
+	reopened.UpdateStats(ctx, query, caller)
+
+	entries, err := reopened.GetTop(ctx, entity.TopQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].HitCount != 2 || entries[0].Key != query.Key() {
+		t.Errorf("expected 2 hits under %s, got %+v", query.Key(), entries)
+	}
+
+	clients, err := reopened.GetTop(ctx, entity.TopQuery{Limit: 10, Client: caller.ID})
+	if err != nil || len(clients) != 1 || clients[0].HitCount != 2 {
+		t.Errorf("expected the client hits to follow, got %+v, %v", clients, err)
+	}
+}
@@ -0,0 +1,103 @@
+package inmemory_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/persistence/statstest"
+)
+
+// benchmarkQueries are the distinct queries spread over concurrent updates
+var benchmarkQueries = func() []entity.FizzBuzzQuery {
+	queries := make([]entity.FizzBuzzQuery, 64)
+	for i := range queries {
+		queries[i] = entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 100 + i,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+	}
+	return queries
+}()
+
+func benchmarkUpdateStats(b *testing.B, updater application.StatisticsUpdater) {
+	ctx := context.Background()
+	var next atomic.Uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := next.Add(1)
+			updater.UpdateStats(ctx, benchmarkQueries[i%uint64(len(benchmarkQueries))], entity.Caller{})
+		}
+	})
+}
+
+func newBenchmarkBatcher(b *testing.B, updater application.StatisticsUpdater) *application.StatisticsBatcher {
+	batcher := application.NewStatisticsBatcher(updater, application.DefaultBatcherConfig(),
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { batcher.Close(context.Background()) })
+	return batcher
+}
+
+// BenchmarkUpdateStats compares write contention of the statistics designs
+// Run with -cpu 1,4,16 to see how each one scales.
+func BenchmarkUpdateStats(b *testing.B) {
+	b.Run("single-mutex", func(b *testing.B) {
+		benchmarkUpdateStats(b, inmemory.NewStatisticsRepository())
+	})
+
+	b.Run("sharded", func(b *testing.B) {
+		benchmarkUpdateStats(b, inmemory.NewShardedStatisticsRepository(16))
+	})
+
+	b.Run("batched-single-mutex", func(b *testing.B) {
+		benchmarkUpdateStats(b, newBenchmarkBatcher(b, inmemory.NewStatisticsRepository()))
+	})
+
+	b.Run("batched-sharded", func(b *testing.B) {
+		benchmarkUpdateStats(b, newBenchmarkBatcher(b, inmemory.NewShardedStatisticsRepository(16)))
+	})
+}
+
+// BenchmarkMixedLoad adds one reader of the ranking for every 100 updates
+func BenchmarkMixedLoad(b *testing.B) {
+	run := func(b *testing.B, updater application.StatisticsUpdater, repo statstest.Repository) {
+		ctx := context.Background()
+		var next atomic.Uint64
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := next.Add(1)
+				if i%100 == 0 {
+					repo.GetTop(ctx, entity.TopQuery{Limit: 10, Window: time.Hour})
+					continue
+				}
+				updater.UpdateStats(ctx, benchmarkQueries[i%uint64(len(benchmarkQueries))], entity.Caller{})
+			}
+		})
+	}
+
+	b.Run("single-mutex", func(b *testing.B) {
+		repo := inmemory.NewStatisticsRepository()
+		run(b, repo, repo)
+	})
+
+	b.Run("sharded", func(b *testing.B) {
+		repo := inmemory.NewShardedStatisticsRepository(16)
+		run(b, repo, repo)
+	})
+
+	b.Run("batched-sharded", func(b *testing.B) {
+		repo := inmemory.NewShardedStatisticsRepository(16)
+		run(b, newBenchmarkBatcher(b, repo), repo)
+	})
+}
@@ -2,235 +2,77 @@ package inmemory_test
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+	"fizzbuzz-service/internal/infrastructure/persistence/file"
 	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/persistence/sqlite"
+	"fizzbuzz-service/internal/infrastructure/persistence/statstest"
 )
 
-func TestStatisticsRepository_UpdateStats(t *testing.T) {
-	t.Run("increments count for same query", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query := entity.FizzBuzzQuery{
-			FirstDivisor:  3,
-			SecondDivisor: 5,
-			UpperLimit:    15,
-			FirstString:   "fizz",
-			SecondString:  "buzz",
-		}
-
-		// Update 5 times
-		for i := 0; i < 5; i++ {
-			err := repo.UpdateStats(ctx, query)
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-		}
-
-		stats, err := repo.GetMostFrequent(ctx)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-
-		if stats.HitCount != 5 {
-			t.Errorf("expected 5 hits, got %d", stats.HitCount)
-		}
-	})
-
-	t.Run("tracks different queries separately", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query1 := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
-			FirstString: "fizz", SecondString: "buzz",
-		}
-
-		query2 := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 100, // Different limit
-			FirstString: "fizz", SecondString: "buzz",
-		}
-
-		// Query1: 3 times
-		for i := 0; i < 3; i++ {
-			repo.UpdateStats(ctx, query1)
-		}
-
-		// Query2: 5 times
-		for i := 0; i < 5; i++ {
-			repo.UpdateStats(ctx, query2)
-		}
-
-		stats, _ := repo.GetMostFrequent(ctx)
-
-		if stats.HitCount != 5 {
-			t.Errorf("expected most frequent to have 5 hits, got %d", stats.HitCount)
-		}
-
-		if stats.MostFrequentQuery.Limit != 100 {
-			t.Errorf("expected most frequent query limit to be 100, got %d", stats.MostFrequentQuery.Limit)
-		}
+func TestStatisticsRepository_Suite(t *testing.T) {
+	t.Run("inmemory", func(t *testing.T) {
+		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+			return inmemory.NewStatisticsRepository(inmemory.WithClock(c), inmemory.WithMaxClients(maxClients))
+		})
 	})
 
-	t.Run("different strings are tracked separately", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query1 := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
-			FirstString: "fizz", SecondString: "buzz",
-		}
-
-		query2 := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
-			FirstString: "foo", SecondString: "bar", // Different strings
-		}
-
-		repo.UpdateStats(ctx, query1)
-		repo.UpdateStats(ctx, query2)
-		repo.UpdateStats(ctx, query2)
-
-		stats, _ := repo.GetMostFrequent(ctx)
-
-		if stats.MostFrequentQuery.Str1 != "foo" {
-			t.Errorf("expected most frequent str1 to be 'foo', got %q", stats.MostFrequentQuery.Str1)
-		}
+	t.Run("inmemory-sharded", func(t *testing.T) {
+		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+			return inmemory.NewShardedStatisticsRepository(8, inmemory.WithClock(c), inmemory.WithMaxClients(maxClients))
+		})
 	})
-}
 
-func TestStatisticsRepository_GetMostFrequent(t *testing.T) {
-	t.Run("returns nil query when empty", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-
-		stats, err := repo.GetMostFrequent(context.Background())
-
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-
-		if stats.MostFrequentQuery != nil {
-			t.Errorf("expected nil query, got %+v", stats.MostFrequentQuery)
-		}
-
-		if stats.HitCount != 0 {
-			t.Errorf("expected 0 hits, got %d", stats.HitCount)
-		}
+	t.Run("file", func(t *testing.T) {
+		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+			config := file.DefaultConfig(t.TempDir())
+			config.MaxClients = maxClients
+			repo, err := file.Open(config,
+				slog.New(slog.NewTextHandler(io.Discard, nil)), file.WithClock(c))
+			if err != nil {
+				t.Fatalf("failed to open repository: %v", err)
+			}
+			t.Cleanup(func() { repo.Close(context.Background()) })
+			return repo
+		})
 	})
 
-	t.Run("returns correct JSON format", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query := entity.FizzBuzzQuery{
-			FirstDivisor:  3,
-			SecondDivisor: 5,
-			UpperLimit:    15,
-			FirstString:   "fizz",
-			SecondString:  "buzz",
-		}
-
-		repo.UpdateStats(ctx, query)
-
-		stats, _ := repo.GetMostFrequent(ctx)
-
-		// Verify the response format uses API field names
-		if stats.MostFrequentQuery.Int1 != 3 {
-			t.Errorf("expected Int1=3, got %d", stats.MostFrequentQuery.Int1)
-		}
-		if stats.MostFrequentQuery.Int2 != 5 {
-			t.Errorf("expected Int2=5, got %d", stats.MostFrequentQuery.Int2)
-		}
-		if stats.MostFrequentQuery.Limit != 15 {
-			t.Errorf("expected Limit=15, got %d", stats.MostFrequentQuery.Limit)
-		}
+	t.Run("sqlite", func(t *testing.T) {
+		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+			repo, err := sqlite.Open(filepath.Join(t.TempDir(), "statistics.db"),
+				sqlite.WithClock(c), sqlite.WithMaxClients(maxClients))
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			t.Cleanup(func() { repo.Close(context.Background()) })
+			return repo
+		})
 	})
 }
 
-func TestStatisticsRepository_Concurrency(t *testing.T) {
-	t.Run("handles concurrent updates safely", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
-			FirstString: "fizz", SecondString: "buzz",
-		}
-
-		const numGoroutines = 100
-		const updatesPerGoroutine = 100
-
-		var wg sync.WaitGroup
-		wg.Add(numGoroutines)
-
-		for i := 0; i < numGoroutines; i++ {
-			go func() {
-				defer wg.Done()
-				for j := 0; j < updatesPerGoroutine; j++ {
-					repo.UpdateStats(ctx, query)
-				}
-			}()
-		}
-
-		wg.Wait()
-
-		stats, err := repo.GetMostFrequent(ctx)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-
-		expected := int64(numGoroutines * updatesPerGoroutine)
-		if stats.HitCount != expected {
-			t.Errorf("expected %d hits, got %d", expected, stats.HitCount)
-		}
-	})
-
-	t.Run("handles concurrent reads and writes", func(t *testing.T) {
-		repo := inmemory.NewStatisticsRepository()
-		ctx := context.Background()
-
-		query := entity.FizzBuzzQuery{
-			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
-			FirstString: "fizz", SecondString: "buzz",
-		}
-
-		// Pre-populate
-		repo.UpdateStats(ctx, query)
-
-		const numGoroutines = 50
-		var wg sync.WaitGroup
-		wg.Add(numGoroutines * 2) // Half writers, half readers
-
-		// Writers
-		for i := 0; i < numGoroutines; i++ {
-			go func() {
-				defer wg.Done()
-				for j := 0; j < 100; j++ {
-					repo.UpdateStats(ctx, query)
-				}
-			}()
-		}
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
 
-		// Readers
-		for i := 0; i < numGoroutines; i++ {
-			go func() {
-				defer wg.Done()
-				for j := 0; j < 100; j++ {
-					stats, err := repo.GetMostFrequent(ctx)
-					if err != nil {
-						t.Errorf("read error: %v", err)
-					}
-					if stats.HitCount < 1 {
-						t.Errorf("expected at least 1 hit")
-					}
-				}
-			}()
-		}
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
 
-		wg.Wait()
-	})
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
 }
 
 func TestStatisticsRepository_Clear(t *testing.T) {
@@ -242,8 +84,8 @@ func TestStatisticsRepository_Clear(t *testing.T) {
 		FirstString: "fizz", SecondString: "buzz",
 	}
 
-	repo.UpdateStats(ctx, query)
-	repo.UpdateStats(ctx, query)
+	repo.UpdateStats(ctx, query, entity.Caller{})
+	repo.UpdateStats(ctx, query, entity.Caller{})
 
 	repo.Clear()
 
@@ -253,3 +95,45 @@ func TestStatisticsRepository_Clear(t *testing.T) {
 		t.Errorf("expected 0 hits after clear, got %d", stats.HitCount)
 	}
 }
+
+func TestStatisticsRepository_MaxClients(t *testing.T) {
+	ctx := context.Background()
+	query := entity.FizzBuzzQuery{
+		FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+		FirstString: "fizz", SecondString: "buzz",
+	}
+	clients := func(repo *inmemory.StatisticsRepository) []string {
+		entries, err := repo.GetClients(ctx, entity.ClientQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.Client)
+		}
+		return ids
+	}
+
+	c := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := inmemory.NewStatisticsRepository(inmemory.WithClock(c), inmemory.WithMaxClients(2))
+	// A new hit makes .1 the most recent client, so .2 is forgotten for .3
+	for _, id := range []string{"ip:192.0.2.1", "ip:192.0.2.2", "ip:192.0.2.1", "ip:192.0.2.3"} {
+		repo.UpdateStats(ctx, query, entity.Caller{ID: id})
+		c.Advance(time.Second)
+	}
+
+	if got := clients(repo); len(got) != 2 || got[0] != "ip:192.0.2.1" || got[1] != "ip:192.0.2.3" {
+		t.Errorf("expected clients .1 and .3, got %v", got)
+	}
+	if stats, _ := repo.GetMostFrequent(ctx); stats.HitCount != 4 {
+		t.Errorf("expected forgotten clients to stay in global counts, got %d hits", stats.HitCount)
+	}
+
+	t.Run("restore keeps the most recent clients", func(t *testing.T) {
+		restored := inmemory.NewStatisticsRepository(inmemory.WithMaxClients(1))
+		restored.Restore(repo.Snapshot())
+		if got := clients(restored); len(got) != 1 || got[0] != "ip:192.0.2.3" {
+			t.Errorf("expected client .3, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package inmemory_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fizzbuzz-service/internal/application"
+	"fizzbuzz-service/internal/domain/entity"
+	"fizzbuzz-service/internal/infrastructure/clock"
+	"fizzbuzz-service/internal/infrastructure/persistence/file"
+	"fizzbuzz-service/internal/infrastructure/persistence/inmemory"
+	"fizzbuzz-service/internal/infrastructure/persistence/statstest"
+	"fizzbuzz-service/internal/infrastructure/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracingProvider(t *testing.T) {
+	t.Run("none is a no-op", func(t *testing.T) {
+		p, err := tracing.NewProvider(context.Background(), tracing.Config{Exporter: tracing.ExporterNone})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Enabled() {
+			t.Error("expected tracing to be disabled")
+		}
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown exporters are rejected", func(t *testing.T) {
+		_, err := tracing.NewProvider(context.Background(), tracing.Config{Exporter: "zipkin"})
+		if err == nil || !strings.Contains(err.Error(), "zipkin") {
+			t.Errorf("expected an unknown exporter error, got %v", err)
+		}
+	})
+
+	t.Run("file exporter writes one JSON span per line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+		p, err := tracing.NewProvider(context.Background(), tracing.Config{
+			Exporter:    tracing.ExporterFile,
+			File:        path,
+			SampleRatio: 1,
+			ServiceName: "fizzbuzz-test",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, span := p.Tracer("test").Start(context.Background(), "exported")
+		span.End()
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Errorf("second shutdown: unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read traces: %v", err)
+		}
+		var exported struct {
+			Name string
+		}
+		if err := json.Unmarshal(data, &exported); err != nil {
+			t.Fatalf("expected a JSON span, got %q: %v", data, err)
+		}
+		if exported.Name != "exported" {
+			t.Errorf("expected span %q, got %q", "exported", exported.Name)
+		}
+		if !strings.Contains(string(data), "fizzbuzz-test") {
+			t.Errorf("expected the service name in %s", data)
+		}
+	})
+
+	t.Run("otlp exporter sends spans to the collector", func(t *testing.T) {
+		requests := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			var req coltracepb.ExportTraceServiceRequest
+			if err := proto.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			requests <- &req
+
+			resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			w.Write(resp)
+		}))
+		defer collector.Close()
+
+		p, err := tracing.NewProvider(context.Background(), tracing.Config{
+			Exporter:    tracing.ExporterOTLP,
+			Endpoint:    collector.URL + "/v1/traces",
+			SampleRatio: 1,
+			ServiceName: "fizzbuzz-test",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, parent := p.Tracer("test").Start(context.Background(), "parent")
+		_, child := p.Tracer("test").Start(ctx, "child")
+		child.End()
+		parent.End()
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		req := <-requests
+		resourceSpans := req.GetResourceSpans()
+		if len(resourceSpans) != 1 {
+			t.Fatalf("expected spans of one resource, got %d", len(resourceSpans))
+		}
+		var service string
+		for _, attr := range resourceSpans[0].GetResource().GetAttributes() {
+			if attr.GetKey() == "service.name" {
+				service = attr.GetValue().GetStringValue()
+			}
+		}
+		if service != "fizzbuzz-test" {
+			t.Errorf("expected service.name fizzbuzz-test, got %q", service)
+		}
+
+		spans := map[string][]byte{}
+		parents := map[string][]byte{}
+		for _, scope := range resourceSpans[0].GetScopeSpans() {
+			for _, span := range scope.GetSpans() {
+				spans[span.GetName()] = span.GetSpanId()
+				parents[span.GetName()] = span.GetParentSpanId()
+			}
+		}
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %v", spans)
+		}
+		if string(parents["child"]) != string(spans["parent"]) {
+			t.Error("expected the child span to reference its parent")
+		}
+	})
+}
+
+func TestTraceStatistics(t *testing.T) {
+	t.Run("conformance", func(t *testing.T) {
+		tp := sdktrace.NewTracerProvider()
+		statstest.RunRepositorySuite(t, func(t *testing.T, c clock.Clock, maxClients int) statstest.Repository {
+			repo := inmemory.NewStatisticsRepository(inmemory.WithClock(c), inmemory.WithMaxClients(maxClients))
+			return tracing.TraceStatistics(repo, tp)
+		})
+	})
+
+	t.Run("batch support is preserved", func(t *testing.T) {
+		tp := sdktrace.NewTracerProvider()
+
+		traced := tracing.TraceStatistics(inmemory.NewStatisticsRepository(), tp)
+		if _, ok := traced.(application.BatchStatisticsUpdater); !ok {
+			t.Error("expected a batch updater for a batch-capable store")
+		}
+
+		repo, err := file.Open(file.DefaultConfig(t.TempDir()), slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if err != nil {
+			t.Fatalf("failed to open repository: %v", err)
+		}
+		defer repo.Close(context.Background())
+		if _, ok := tracing.TraceStatistics(repo, tp).(application.BatchStatisticsUpdater); ok {
+			t.Error("expected no batch updater for a store without batch support")
+		}
+	})
+
+	t.Run("each port call is a span", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		traced := tracing.TraceStatistics(inmemory.NewStatisticsRepository(), tp)
+		ctx := context.Background()
+		query := entity.FizzBuzzQuery{
+			FirstDivisor: 3, SecondDivisor: 5, UpperLimit: 15,
+			FirstString: "fizz", SecondString: "buzz",
+		}
+
+		traced.UpdateStats(ctx, query, entity.Caller{})
+		traced.(application.BatchStatisticsUpdater).UpdateStatsBatch(ctx, []application.HitDelta{{Query: query, Hits: 2}})
+		traced.GetMostFrequent(ctx)
+
+		var names []string
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+		}
+		expected := []string{
+			"StatisticsUpdater.UpdateStats",
+			"StatisticsUpdater.UpdateStatsBatch",
+			"StatisticsRepository.GetMostFrequent",
+		}
+		if strings.Join(names, ",") != strings.Join(expected, ",") {
+			t.Errorf("expected spans %v, got %v", expected, names)
+		}
+	})
+}